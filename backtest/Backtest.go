@@ -0,0 +1,332 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoCandles K线数据为空
+	ErrNoCandles = errors.New("backtest: no candle data")
+	// ErrSideLength 策略信号数量与K线数量不一致
+	ErrSideLength = errors.New("backtest: side data length does not match candles")
+	// ErrInvalidCapital 初始资金必须大于 0
+	ErrInvalidCapital = errors.New("backtest: initial capital must be greater than zero")
+)
+
+// FillMode 成交方式
+type FillMode uint32
+
+const (
+	// FillNextOpen 信号出现后，在下一根K线的开盘价成交
+	FillNextOpen FillMode = iota
+	// FillSameClose 在信号K线的收盘价成交
+	FillSameClose
+)
+
+// SizingMode 仓位计算方式
+type SizingMode uint32
+
+const (
+	// SizingPercentOfEquity 按当前权益的比例开仓，SizingValue 为 0-1 之间的比例
+	SizingPercentOfEquity SizingMode = iota
+	// SizingFixedQuantity 每次开仓固定数量，SizingValue 为数量
+	SizingFixedQuantity
+	// SizingFixedCash 每次开仓固定金额，SizingValue 为金额
+	SizingFixedCash
+)
+
+// Config 回测参数
+type Config struct {
+	// 初始资金
+	InitialCapital float64
+	// 手续费率，按成交额计算，例如 0.001 表示千分之一
+	Commission float64
+	// 滑点比例，买入价格上浮、卖出价格下浮，例如 0.0005
+	Slippage float64
+	// 成交方式
+	FillMode FillMode
+	// 仓位计算方式
+	Sizing SizingMode
+	// 仓位参数，含义由 Sizing 决定
+	SizingValue float64
+	// 是否允许做空，不允许时 Sell 信号只平多仓
+	AllowShort bool
+	// 回测结束时是否按最后一根K线收盘价平掉持仓
+	CloseOnFinish bool
+	// 年化无风险利率，用于计算 Sharpe/Sortino
+	RiskFreeRate float64
+}
+
+// DefaultConfig 默认回测参数：10000 初始资金，全仓做多，下一根K线开盘成交，千分之一手续费
+func DefaultConfig() Config {
+	return Config{
+		InitialCapital: 10000,
+		Commission:     0.001,
+		FillMode:       FillNextOpen,
+		Sizing:         SizingPercentOfEquity,
+		SizingValue:    1,
+		CloseOnFinish:  true,
+	}
+}
+
+// Backtest 回测引擎，将 utils.IStrategy 的买卖信号转换为交易及权益曲线
+type Backtest struct {
+	Name   string
+	Config Config
+	kline  *klines.Item
+}
+
+// NewBacktest new Func
+func NewBacktest(klineItem *klines.Item, config Config) *Backtest {
+	return &Backtest{
+		Name:   "Backtest",
+		Config: config,
+		kline:  klineItem,
+	}
+}
+
+// NewDefaultBacktest new Func
+func NewDefaultBacktest(klineItem *klines.Item) *Backtest {
+	return NewBacktest(klineItem, DefaultConfig())
+}
+
+// Run 运行单个策略
+func (e *Backtest) Run(strategy utils.IStrategy) (*Report, error) {
+	return e.RunSides(strategy.AnalysisSide())
+}
+
+// RunStrategies 依次运行多个策略，每个策略单独生成一份报告
+func (e *Backtest) RunStrategies(strategies ...utils.IStrategy) ([]*Report, error) {
+	reports := make([]*Report, len(strategies))
+	for i, strategy := range strategies {
+		report, err := e.Run(strategy)
+		if err != nil {
+			return nil, err
+		}
+		reports[i] = report
+	}
+	return reports, nil
+}
+
+// RunSides 使用 utils.RunStrategies 的结果运行回测
+func (e *Backtest) RunSides(sides utils.SideData) (*Report, error) {
+	if e.kline == nil || len(e.kline.Candles) == 0 {
+		return nil, ErrNoCandles
+	}
+	return e.RunSidesRange(sides, 0, len(e.kline.Candles))
+}
+
+// RunSidesRange 只在 [start, end) 区间内的K线上交易，区间之前的K线仅用于指标预热
+func (e *Backtest) RunSidesRange(sides utils.SideData, start, end int) (*Report, error) {
+	if e.kline == nil || len(e.kline.Candles) == 0 {
+		return nil, ErrNoCandles
+	}
+	if len(sides.Data) != len(e.kline.Candles) {
+		return nil, fmt.Errorf("%w: %s has %d sides, %d candles",
+			ErrSideLength,
+			sides.Name,
+			len(sides.Data),
+			len(e.kline.Candles))
+	}
+	if e.Config.InitialCapital <= 0 {
+		return nil, ErrInvalidCapital
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(e.kline.Candles) {
+		end = len(e.kline.Candles)
+	}
+	if start >= end {
+		return nil, fmt.Errorf("%w: empty range [%d, %d)", ErrNoCandles, start, end)
+	}
+
+	var run = &runner{
+		config:  e.Config,
+		candles: e.kline.Candles,
+		cash:    e.Config.InitialCapital,
+		// 与 newReport 一致，从初始资金开始计算回撤
+		peak: e.Config.InitialCapital,
+	}
+
+	var pending = utils.Hold
+	var hasPending bool
+
+	for i := start; i < end; i++ {
+		var candle = e.kline.Candles[i]
+
+		// 下一根K线开盘价成交上一根K线的信号
+		if hasPending {
+			run.execute(pending, i, candle.Open)
+			hasPending = false
+		}
+
+		// 多数策略不计算第一根K线，Side 的零值为 Buy，这里忽略
+		var side = sides.Data[i]
+		if i > 0 && side != utils.Hold {
+			if e.Config.FillMode == FillSameClose {
+				run.execute(side, i, candle.Close)
+			} else if i+1 < end {
+				pending = side
+				hasPending = true
+			}
+		}
+
+		run.mark(i)
+	}
+
+	if e.Config.CloseOnFinish && run.position != 0 {
+		var last = end - 1
+		run.close(last, e.kline.Candles[last].Close)
+		run.equity[len(run.equity)-1] = run.snapshot(last)
+	}
+
+	return newReport(sides.Name, e.kline.Interval, e.Config, run.trades, run.equity), nil
+}
+
+// runner 保存一次回测过程中的账户状态
+type runner struct {
+	config   Config
+	candles  []*klines.Candle
+	cash     float64
+	position float64 // 持仓数量，做空时为负数
+	open     *Trade
+	trades   []Trade
+	equity   []EquityData
+	peak     float64
+}
+
+// execute 按信号方向调整持仓
+func (e *runner) execute(side utils.Side, index int, price float64) {
+	switch side {
+	case utils.Buy:
+		if e.position < 0 {
+			e.close(index, price)
+		}
+		if e.position == 0 {
+			e.enter(Long, index, price)
+		}
+	case utils.Sell:
+		if e.position > 0 {
+			e.close(index, price)
+		}
+		if e.position == 0 && e.config.AllowShort {
+			e.enter(Short, index, price)
+		}
+	}
+}
+
+// fillPrice 计算滑点后的成交价
+func (e *runner) fillPrice(buy bool, price float64) float64 {
+	if buy {
+		return price * (1 + e.config.Slippage)
+	}
+	return price * (1 - e.config.Slippage)
+}
+
+// quantity 按仓位规则计算开仓数量
+func (e *runner) quantity(price float64) float64 {
+	var qty float64
+	switch e.config.Sizing {
+	case SizingFixedQuantity:
+		qty = e.config.SizingValue
+	case SizingFixedCash:
+		qty = e.config.SizingValue / (price * (1 + e.config.Commission))
+	default:
+		// 开仓时一定是空仓状态，此时现金即为权益
+		qty = e.cash * e.config.SizingValue / (price * (1 + e.config.Commission))
+	}
+	if qty < 0 || math.IsNaN(qty) || math.IsInf(qty, 0) {
+		return 0
+	}
+	return qty
+}
+
+func (e *runner) enter(direction Direction, index int, price float64) {
+	var fill = e.fillPrice(direction == Long, price)
+	if fill <= 0 {
+		return
+	}
+	var qty = e.quantity(fill)
+	if qty == 0 {
+		return
+	}
+	var commission = qty * fill * e.config.Commission
+
+	if direction == Long {
+		e.cash -= qty*fill + commission
+		e.position = qty
+	} else {
+		e.cash += qty*fill - commission
+		e.position = -qty
+	}
+
+	e.open = &Trade{
+		Direction:  direction,
+		EntryIndex: index,
+		EntryTime:  time.Unix(e.candles[index].TimeUnix, 0),
+		EntryPrice: fill,
+		Quantity:   qty,
+		Commission: commission,
+	}
+}
+
+func (e *runner) close(index int, price float64) {
+	if e.open == nil {
+		return
+	}
+	var trade = *e.open
+	var fill = e.fillPrice(trade.Direction == Short, price)
+	var commission = trade.Quantity * fill * e.config.Commission
+
+	if trade.Direction == Long {
+		e.cash += trade.Quantity*fill - commission
+		trade.Profit = (fill-trade.EntryPrice)*trade.Quantity - trade.Commission - commission
+	} else {
+		e.cash -= trade.Quantity*fill + commission
+		trade.Profit = (trade.EntryPrice-fill)*trade.Quantity - trade.Commission - commission
+	}
+
+	trade.ExitIndex = index
+	trade.ExitTime = time.Unix(e.candles[index].TimeUnix, 0)
+	trade.ExitPrice = fill
+	trade.Commission += commission
+	trade.Bars = trade.ExitIndex - trade.EntryIndex
+	if cost := trade.EntryPrice * trade.Quantity; cost != 0 {
+		trade.ReturnPercent = trade.Profit / cost
+	}
+
+	e.trades = append(e.trades, trade)
+	e.position = 0
+	e.open = nil
+}
+
+// mark 按收盘价计算当前权益
+func (e *runner) mark(index int) {
+	e.equity = append(e.equity, e.snapshot(index))
+}
+
+func (e *runner) snapshot(index int) EquityData {
+	var candle = e.candles[index]
+	var equity = e.cash + e.position*candle.Close
+	if equity > e.peak {
+		e.peak = equity
+	}
+	var drawdown float64
+	if e.peak > 0 {
+		drawdown = (e.peak - equity) / e.peak
+	}
+	return EquityData{
+		Time:     time.Unix(candle.TimeUnix, 0),
+		Cash:     e.cash,
+		Position: e.position,
+		Equity:   equity,
+		Drawdown: drawdown,
+	}
+}
@@ -0,0 +1,144 @@
+package backtest
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// RUN
+// go test -v ./backtest -run TestBacktestSameClose
+func TestBacktestSameClose(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 0, 10, 10, 12, 11, 13, 15)
+	var config = DefaultConfig()
+	config.Commission = 0
+	config.FillMode = FillSameClose
+
+	report, err := NewBacktest(list, config).Run(utils.GetSidesStrategy("test",
+		utils.Buy, utils.Buy, utils.Hold, utils.Sell, utils.Buy, utils.Sell,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.TotalTrades != 2 {
+		t.Fatalf("expected 2 trades, got %d", report.TotalTrades)
+	}
+	// 10 -> 11, 13 -> 15
+	if !almostEqual(report.FinalEquity, 10000*1.1*15/13) {
+		t.Fatalf("unexpected final equity %f", report.FinalEquity)
+	}
+	if report.WinRate != 1 {
+		t.Fatalf("expected win rate 1, got %f", report.WinRate)
+	}
+	if !math.IsInf(report.ProfitFactor, 1) {
+		t.Fatalf("expected infinite profit factor, got %f", report.ProfitFactor)
+	}
+	// 12 -> 11 的回撤
+	if !almostEqual(report.MaxDrawdown, 1-11.0/12) {
+		t.Fatalf("unexpected max drawdown %f", report.MaxDrawdown)
+	}
+}
+
+// RUN
+// go test -v ./backtest -run TestBacktestNextOpen
+func TestBacktestNextOpen(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 0, 10, 10, 20, 20, 10, 10)
+	var config = DefaultConfig()
+	config.Commission = 0.01
+	config.AllowShort = true
+
+	report, err := NewBacktest(list, config).Run(utils.GetSidesStrategy("test",
+		utils.Hold, utils.Buy, utils.Hold, utils.Sell, utils.Hold, utils.Hold,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.TotalTrades != 2 {
+		t.Fatalf("expected 2 trades, got %d", report.TotalTrades)
+	}
+	var long, short = report.Trades[0], report.Trades[1]
+	if long.Direction != Long || long.EntryIndex != 2 || long.ExitIndex != 4 {
+		t.Fatalf("unexpected long trade %+v", long)
+	}
+	if short.Direction != Short || short.EntryIndex != 4 || short.ExitIndex != 5 {
+		t.Fatalf("unexpected short trade %+v", short)
+	}
+	// 下一根开盘 20 买入，10 卖出，亏损一半再加两次手续费
+	var qty = 10000 / (20 * 1.01)
+	var expected = -10*qty - qty*20*0.01 - qty*10*0.01
+	if !almostEqual(long.Profit, expected) {
+		t.Fatalf("unexpected long profit %f, expected %f", long.Profit, expected)
+	}
+	if !almostEqual(report.GrossLoss, -long.Profit-short.Profit) {
+		t.Fatalf("unexpected gross loss %f", report.GrossLoss)
+	}
+}
+
+// RUN
+// go test -v ./backtest -run TestBacktestFirstBarDrawdown
+func TestBacktestFirstBarDrawdown(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 0, 10, 10, 10, 10)
+	var config = DefaultConfig()
+	config.Commission = 0.01
+	config.FillMode = FillSameClose
+
+	// 样本外窗口的第一根K线成交，手续费的亏损也计入回撤
+	report, err := NewBacktest(list, config).RunSidesRange(utils.SideData{
+		Data: []utils.Side{utils.Hold, utils.Buy, utils.Hold, utils.Hold},
+	}, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Equity[0].Drawdown <= 0 || !almostEqual(report.MaxDrawdown*report.InitialCapital, report.MaxDrawdownValue) {
+		t.Fatalf("max drawdown %f does not match max drawdown value %f", report.MaxDrawdown, report.MaxDrawdownValue)
+	}
+}
+
+// RUN
+// go test -v ./backtest -run TestBacktestErrors
+func TestBacktestErrors(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 0, 10, 11)
+
+	if _, err := NewDefaultBacktest(&klines.Item{}).Run(utils.GetSidesStrategy("test")); !errors.Is(err, ErrNoCandles) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNoCandles)
+	}
+	if _, err := NewDefaultBacktest(list).Run(utils.GetSidesStrategy("test", utils.Buy)); !errors.Is(err, ErrSideLength) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrSideLength)
+	}
+	if _, err := NewBacktest(list, Config{}).Run(utils.GetSidesStrategy("test", utils.Buy, utils.Buy)); !errors.Is(err, ErrInvalidCapital) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrInvalidCapital)
+	}
+}
+
+// RUN
+// go test -v ./backtest -run TestReportSeries
+func TestReportSeries(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 0, 10, 11, 9, 12, 13, 12)
+	report, err := NewDefaultBacktest(list).Run(utils.GetSidesStrategy("test",
+		utils.Buy, utils.Hold, utils.Hold, utils.Hold, utils.Sell, utils.Hold,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var series = report.Series()
+	if len(series.Returns) != len(report.Equity) || !series.Times[0].Equal(report.Equity[0].Time) {
+		t.Fatalf("got %d returns", len(series.Returns))
+	}
+	if !almostEqual(series.TotalReturn(), report.TotalReturn) || !almostEqual(series.Sharpe(0), report.Sharpe) {
+		t.Fatalf("total return %v, sharpe %v", series.TotalReturn(), series.Sharpe(0))
+	}
+}
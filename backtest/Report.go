@@ -0,0 +1,178 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/metrics"
+)
+
+// Direction 持仓方向
+type Direction uint32
+
+const (
+	// Long 做多
+	Long Direction = iota
+	// Short 做空
+	Short
+)
+
+// String implements the stringer interface
+func (d Direction) String() string {
+	switch d {
+	case Long:
+		return "LONG"
+	case Short:
+		return "SHORT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Trade 一笔完整的交易（开仓到平仓）
+type Trade struct {
+	Direction  Direction
+	EntryIndex int
+	EntryTime  time.Time
+	EntryPrice float64
+	ExitIndex  int
+	ExitTime   time.Time
+	ExitPrice  float64
+	Quantity   float64
+	// 开仓与平仓手续费之和
+	Commission float64
+	// 扣除手续费后的盈亏
+	Profit float64
+	// 盈亏占开仓成本的比例
+	ReturnPercent float64
+	// 持仓K线数量
+	Bars int
+}
+
+// EquityData 每根K线收盘时的账户状态
+type EquityData struct {
+	Time     time.Time
+	Cash     float64
+	Position float64
+	Equity   float64
+	// 相对历史最高权益的回撤比例
+	Drawdown float64
+}
+
+// Report 回测报告
+type Report struct {
+	Name           string
+	Interval       klines.Interval
+	InitialCapital float64
+	FinalEquity    float64
+	NetProfit      float64
+	// 总收益率
+	TotalReturn float64
+	// 最大回撤比例
+	MaxDrawdown float64
+	// 最大回撤金额
+	MaxDrawdownValue float64
+	// 年化 Sharpe 比率
+	Sharpe float64
+	// 年化 Sortino 比率
+	Sortino float64
+	// 胜率
+	WinRate float64
+	// 盈利因子 = 总盈利 / 总亏损
+	ProfitFactor    float64
+	TotalTrades     int
+	WinningTrades   int
+	LosingTrades    int
+	GrossProfit     float64
+	GrossLoss       float64
+	TotalCommission float64
+	Trades          []Trade
+	Equity          []EquityData
+}
+
+// NewReport 根据交易列表和权益曲线生成报告，用于汇总拼接后的多段回测结果，
+// equity 中的 Drawdown 需要已经按拼接后的权益曲线计算
+func NewReport(name string, interval klines.Interval, config Config, trades []Trade, equity []EquityData) *Report {
+	return newReport(name, interval, config, trades, equity)
+}
+
+// newReport 根据交易列表和权益曲线汇总统计数据
+func newReport(name string, interval klines.Interval, config Config, trades []Trade, equity []EquityData) *Report {
+	var report = &Report{
+		Name:           name,
+		Interval:       interval,
+		InitialCapital: config.InitialCapital,
+		FinalEquity:    config.InitialCapital,
+		Trades:         trades,
+		Equity:         equity,
+		TotalTrades:    len(trades),
+	}
+
+	if len(equity) > 0 {
+		report.FinalEquity = equity[len(equity)-1].Equity
+	}
+	report.NetProfit = report.FinalEquity - report.InitialCapital
+	report.TotalReturn = report.NetProfit / report.InitialCapital
+
+	for _, trade := range trades {
+		report.TotalCommission += trade.Commission
+		if trade.Profit > 0 {
+			report.WinningTrades++
+			report.GrossProfit += trade.Profit
+		} else {
+			report.LosingTrades++
+			report.GrossLoss -= trade.Profit
+		}
+	}
+	if report.TotalTrades > 0 {
+		report.WinRate = float64(report.WinningTrades) / float64(report.TotalTrades)
+	}
+	if report.GrossLoss > 0 {
+		report.ProfitFactor = report.GrossProfit / report.GrossLoss
+	} else if report.GrossProfit > 0 {
+		report.ProfitFactor = math.Inf(1)
+	}
+
+	var peak = report.InitialCapital
+	for _, v := range equity {
+		if v.Equity > peak {
+			peak = v.Equity
+		}
+		if v.Drawdown > report.MaxDrawdown {
+			report.MaxDrawdown = v.Drawdown
+		}
+		if peak-v.Equity > report.MaxDrawdownValue {
+			report.MaxDrawdownValue = peak - v.Equity
+		}
+	}
+
+	var series = metrics.NewSeries(equityReturns(report.InitialCapital, equity), interval)
+	report.Sharpe = series.Sharpe(config.RiskFreeRate)
+	report.Sortino = series.Sortino(config.RiskFreeRate)
+
+	return report
+}
+
+// Series 权益曲线每根K线的收益率，用于 metrics 计算更多绩效与风险指标
+func (r *Report) Series() *metrics.Series {
+	var series = metrics.NewSeries(equityReturns(r.InitialCapital, r.Equity), r.Interval)
+	series.Times = make([]time.Time, len(r.Equity))
+	for i, v := range r.Equity {
+		series.Times[i] = v.Time
+	}
+	return series
+}
+
+// equityReturns 计算每根K线的权益收益率
+func equityReturns(initial float64, equity []EquityData) []float64 {
+	var returns = make([]float64, len(equity))
+	var prev = initial
+	for i, v := range equity {
+		if prev != 0 {
+			returns[i] = v.Equity/prev - 1
+		}
+		prev = v.Equity
+	}
+	return returns
+}
@@ -0,0 +1,123 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Heatmap 两个参数的得分表，其余参数取得分最高的组合
+type Heatmap struct {
+	X       string
+	Y       string
+	XValues []float64
+	YValues []float64
+	// Scores[y][x] 为对应取值的最高得分，没有运行的组合为 NaN
+	Scores [][]float64
+}
+
+// Heatmap 生成参数 x 与 y 的得分表
+func (r *Results) Heatmap(x, y string) (*Heatmap, error) {
+	var xi, yi = -1, -1
+	for i, p := range r.Space {
+		switch p.Name {
+		case x:
+			xi = i
+		case y:
+			yi = i
+		}
+	}
+	if xi < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParam, x)
+	}
+	if yi < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParam, y)
+	}
+
+	var heatmap = &Heatmap{
+		X:       x,
+		Y:       y,
+		XValues: r.Space[xi].Values,
+		YValues: r.Space[yi].Values,
+		Scores:  make([][]float64, len(r.Space[yi].Values)),
+	}
+	for i := range heatmap.Scores {
+		heatmap.Scores[i] = make([]float64, len(heatmap.XValues))
+		for j := range heatmap.Scores[i] {
+			heatmap.Scores[i][j] = math.NaN()
+		}
+	}
+	for _, result := range r.Results {
+		var cell = &heatmap.Scores[result.index[yi]][result.index[xi]]
+		if math.IsNaN(*cell) || result.Score > *cell {
+			*cell = result.Score
+		}
+	}
+	return heatmap, nil
+}
+
+// Records 转换为字符串表格，第一行为 x 的取值，第一列为 y 的取值
+func (h *Heatmap) Records() [][]string {
+	var header = []string{h.Y + `\` + h.X}
+	for _, v := range h.XValues {
+		header = append(header, formatFloat(v))
+	}
+	var records = [][]string{header}
+	for i, v := range h.YValues {
+		var row = []string{formatFloat(v)}
+		for _, score := range h.Scores[i] {
+			row = append(row, formatFloat(score))
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// WriteCSV 写为 CSV
+func (h *Heatmap) WriteCSV(w io.Writer) error {
+	return csv.NewWriter(w).WriteAll(h.Records())
+}
+
+// Records 转换为字符串表格，每行一组参数，按得分从高到低排列
+func (r *Results) Records() [][]string {
+	var header []string
+	for _, p := range r.Space {
+		header = append(header, p.Name)
+	}
+	header = append(header, "score", "stability", "net_profit", "total_return", "max_drawdown", "sharpe", "win_rate", "trades")
+
+	var records = [][]string{header}
+	for _, result := range r.Results {
+		var row []string
+		for _, p := range r.Space {
+			row = append(row, formatFloat(result.Params[p.Name]))
+		}
+		var report = result.Report
+		row = append(row,
+			formatFloat(result.Score),
+			formatFloat(result.Stability),
+			formatFloat(report.NetProfit),
+			formatFloat(report.TotalReturn),
+			formatFloat(report.MaxDrawdown),
+			formatFloat(report.Sharpe),
+			formatFloat(report.WinRate),
+			strconv.Itoa(report.TotalTrades),
+		)
+		records = append(records, row)
+	}
+	return records
+}
+
+// WriteCSV 写为 CSV
+func (r *Results) WriteCSV(w io.Writer) error {
+	return csv.NewWriter(w).WriteAll(r.Records())
+}
+
+func formatFloat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
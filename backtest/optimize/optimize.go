@@ -0,0 +1,279 @@
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/idoall/stockindicator/backtest"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrEmptySpace 参数空间为空或某个参数没有取值
+	ErrEmptySpace = errors.New("optimize: empty parameter space")
+	// ErrNoResults 约束条件过滤后没有可以运行的组合
+	ErrNoResults = errors.New("optimize: no parameter combination to run")
+	// ErrUnknownParam 参数不在参数空间中
+	ErrUnknownParam = errors.New("optimize: unknown parameter")
+)
+
+// Factory 使用一组参数创建策略，多个 goroutine 会同时调用，不能修改 item
+type Factory func(item *klines.Item, params Params) utils.IStrategy
+
+// Objective 根据回测报告计算得分，得分越高越好
+type Objective func(report *backtest.Report) float64
+
+// NetProfit 以净利润为目标
+func NetProfit(report *backtest.Report) float64 {
+	return report.NetProfit
+}
+
+// Sharpe 以年化 Sharpe 比率为目标
+func Sharpe(report *backtest.Report) float64 {
+	return report.Sharpe
+}
+
+// DrawdownPenalized 以扣除回撤惩罚后的收益率为目标，得分 = 总收益率 - penalty * 最大回撤
+func DrawdownPenalized(penalty float64) Objective {
+	return func(report *backtest.Report) float64 {
+		return report.TotalReturn - penalty*report.MaxDrawdown
+	}
+}
+
+// Method 搜索方式
+type Method uint32
+
+const (
+	// Grid 网格搜索，运行全部组合
+	Grid Method = iota
+	// Random 随机搜索，随机选取 Samples 个不重复的组合
+	Random
+)
+
+// Config 参数优化配置
+type Config struct {
+	// 回测参数
+	Backtest backtest.Config
+	Method   Method
+	// 随机搜索的组合数量
+	Samples int
+	// 随机搜索的种子，相同的种子选取相同的组合
+	Seed int64
+	// 同时运行的 goroutine 数量，为 0 时使用 CPU 数量
+	Workers int
+	// 约束条件，返回 false 的组合不运行，例如 short < long
+	Constraint func(params Params) bool
+	// 是否在结果中保留交易列表与权益曲线，组合较多时会占用大量内存
+	KeepDetails bool
+}
+
+// DefaultConfig 默认配置：默认回测参数的网格搜索
+func DefaultConfig() Config {
+	return Config{
+		Backtest: backtest.DefaultConfig(),
+		Method:   Grid,
+		Samples:  100,
+	}
+}
+
+// Optimizer 参数优化，在同一份K线上回测参数空间中的组合并按得分排序
+type Optimizer struct {
+	Name      string
+	Space     Space
+	Factory   Factory
+	Objective Objective
+	Config    Config
+	kline     *klines.Item
+}
+
+// NewOptimizer new Func
+//
+//	var space = optimize.Space{
+//		optimize.Range("short", 8, 16, 2),
+//		optimize.Range("long", 20, 40, 5),
+//	}
+//	var opt = optimize.NewOptimizer(list, space, func(item *klines.Item, p optimize.Params) utils.IStrategy {
+//		return trend.NewMacd(item, p.Int("short"), 9, p.Int("long"))
+//	}, optimize.Sharpe, optimize.DefaultConfig())
+//	results, err := opt.Run()
+func NewOptimizer(klineItem *klines.Item, space Space, factory Factory, objective Objective, config Config) *Optimizer {
+	return &Optimizer{
+		Name:      "Optimizer",
+		Space:     space,
+		Factory:   factory,
+		Objective: objective,
+		Config:    config,
+		kline:     klineItem,
+	}
+}
+
+// Run 运行参数优化，返回按得分从高到低排列的结果
+func (e *Optimizer) Run() (*Results, error) {
+	if e.kline == nil || len(e.kline.Candles) == 0 {
+		return nil, backtest.ErrNoCandles
+	}
+	if e.Space.Size() == 0 {
+		return nil, ErrEmptySpace
+	}
+
+	var combinations [][]int
+	switch e.Config.Method {
+	case Random:
+		combinations = e.Space.random(e.Config.Samples, e.Config.Seed)
+	default:
+		combinations = e.Space.grid()
+	}
+
+	var jobs []Result
+	for _, index := range combinations {
+		var params = e.Space.params(index)
+		if e.Config.Constraint != nil && !e.Config.Constraint(params) {
+			continue
+		}
+		jobs = append(jobs, Result{Params: params, index: index})
+	}
+	if len(jobs) == 0 {
+		return nil, ErrNoResults
+	}
+
+	var workers = e.Config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	var errs = make([]error, len(jobs))
+	var queue = make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				errs[i] = e.run(&jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", jobs[i].Params, err)
+		}
+	}
+	return newResults(e.Space, jobs), nil
+}
+
+// run 回测一组参数
+func (e *Optimizer) run(result *Result) error {
+	var strategy = e.Factory(e.kline, result.Params)
+	report, err := backtest.NewBacktest(e.kline, e.Config.Backtest).Run(strategy)
+	if err != nil {
+		return err
+	}
+	result.Score = e.Objective(report)
+	if !e.Config.KeepDetails {
+		report.Trades = nil
+		report.Equity = nil
+	}
+	result.Report = report
+	return nil
+}
+
+// Result 一组参数的回测结果
+type Result struct {
+	Params Params
+	// Objective 计算的得分
+	Score float64
+	// 该组合与参数空间中相邻组合（每个参数最多相差一个取值）的平均得分，越接近 Score 说明参数越稳定
+	Stability float64
+	Report    *backtest.Report
+	// 每个参数的取值序号
+	index []int
+}
+
+// Results 按得分从高到低排列的优化结果
+type Results struct {
+	Space   Space
+	Results []Result
+}
+
+func newResults(space Space, results []Result) *Results {
+	// 计算相邻组合的平均得分
+	var scores = make(map[string]float64, len(results))
+	for _, r := range results {
+		scores[indexKey(r.index)] = r.Score
+	}
+	for i := range results {
+		var sum float64
+		var count int
+		neighbours(results[i].index, space, func(index []int) {
+			if score, ok := scores[indexKey(index)]; ok && !math.IsNaN(score) {
+				sum += score
+				count++
+			}
+		})
+		results[i].Stability = math.NaN()
+		if count > 0 {
+			results[i].Stability = sum / float64(count)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		var a, b = results[i].Score, results[j].Score
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		return a > b
+	})
+	return &Results{Space: space, Results: results}
+}
+
+// neighbours 遍历每个参数最多相差一个取值的组合，包括 index 自身
+func neighbours(index []int, space Space, fn func(index []int)) {
+	var current = make([]int, len(index))
+	var walk func(d int)
+	walk = func(d int) {
+		if d == len(index) {
+			fn(current)
+			return
+		}
+		for delta := -1; delta <= 1; delta++ {
+			var v = index[d] + delta
+			if v < 0 || v >= len(space[d].Values) {
+				continue
+			}
+			current[d] = v
+			walk(d + 1)
+		}
+	}
+	walk(0)
+}
+
+func indexKey(index []int) string {
+	return fmt.Sprint(index)
+}
+
+// Best 得分最高的结果
+func (r *Results) Best() Result {
+	return r.Results[0]
+}
+
+// Top 得分最高的 n 个结果
+func (r *Results) Top(n int) []Result {
+	if n > len(r.Results) {
+		n = len(r.Results)
+	}
+	return r.Results[:n]
+}
@@ -0,0 +1,116 @@
+package optimize
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+func macdFactory(item *klines.Item, p Params) utils.IStrategy {
+	return trend.NewMacd(item, p.Int("short"), p.Int("signal"), p.Int("long"))
+}
+
+// RUN
+// go test -v ./backtest/optimize -run TestSpace
+func TestSpace(t *testing.T) {
+	t.Parallel()
+	var param = Range("multiplier", 0.1, 0.5, 0.1)
+	if !reflect.DeepEqual(param.Values, []float64{0.1, 0.2, 0.3, 0.4, 0.5}) {
+		t.Fatalf("unexpected values %v", param.Values)
+	}
+
+	var space = Space{Range("a", 1, 3, 1), Values("b", 10, 20)}
+	if space.Size() != 6 {
+		t.Fatalf("expected 6 combinations, got %d", space.Size())
+	}
+	if p := space.params(space.grid()[5]); p.String() != "a=3,b=20" {
+		t.Fatalf("unexpected params %s", p)
+	}
+	var random = space.random(4, 1)
+	if len(random) != 4 || !reflect.DeepEqual(random, space.random(4, 1)) {
+		t.Fatalf("unexpected random combinations %v", random)
+	}
+}
+
+// RUN
+// go test -v ./backtest/optimize -run TestOptimizer
+func TestOptimizer(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(500, 3)
+	var space = Space{
+		Range("short", 6, 14, 2),
+		Values("signal", 9),
+		Range("long", 14, 30, 4),
+	}
+	var config = DefaultConfig()
+	config.Constraint = func(p Params) bool { return p["short"] < p["long"] }
+
+	results, err := NewOptimizer(list, space, macdFactory, Sharpe, config).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// short=14 与 long=14 的组合被约束条件排除
+	if len(results.Results) != space.Size()-1 {
+		t.Fatalf("expected %d results, got %d", space.Size()-1, len(results.Results))
+	}
+	for i := 1; i < len(results.Results); i++ {
+		if results.Results[i].Score > results.Results[i-1].Score {
+			t.Fatalf("results not sorted at %d", i)
+		}
+	}
+	var best = results.Best()
+	if best.Score != best.Report.Sharpe || best.Report.Equity != nil || math.IsNaN(best.Stability) {
+		t.Fatalf("unexpected best result %+v", best)
+	}
+
+	// 单个 goroutine 的结果一致
+	config.Workers = 1
+	serial, err := NewOptimizer(list, space, macdFactory, Sharpe, config).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range serial.Results {
+		if serial.Results[i].Params.String() != results.Results[i].Params.String() || serial.Results[i].Score != results.Results[i].Score {
+			t.Fatalf("[%d] serial %s parallel %s", i, serial.Results[i].Params, results.Results[i].Params)
+		}
+	}
+
+	heatmap, err := results.Heatmap("short", "long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heatmap.Scores) != 5 || len(heatmap.Scores[0]) != 5 || !math.IsNaN(heatmap.Scores[0][4]) {
+		t.Fatalf("unexpected heatmap %+v", heatmap)
+	}
+	var buf bytes.Buffer
+	if err = heatmap.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = results.Heatmap("short", "unknown"); !errors.Is(err, ErrUnknownParam) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrUnknownParam)
+	}
+	if records := results.Records(); len(records) != len(results.Results)+1 || records[0][3] != "score" {
+		t.Fatalf("unexpected records header %v", records[0])
+	}
+
+	config.Method = Random
+	config.Samples = 5
+	config.Seed = 7
+	random, err := NewOptimizer(list, space, macdFactory, DrawdownPenalized(2), config).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(random.Results) > 5 {
+		t.Fatalf("expected at most 5 results, got %d", len(random.Results))
+	}
+
+	if _, err = NewOptimizer(list, Space{Values("short")}, macdFactory, Sharpe, config).Run(); !errors.Is(err, ErrEmptySpace) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrEmptySpace)
+	}
+}
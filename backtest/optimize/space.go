@@ -0,0 +1,131 @@
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Param 需要搜索的参数及其取值
+type Param struct {
+	Name   string
+	Values []float64
+}
+
+// Range 从 min 到 max（包含）按 step 生成取值
+func Range(name string, min, max, step float64) Param {
+	var param = Param{Name: name}
+	if step <= 0 {
+		return param
+	}
+	// 按步数生成，避免浮点累加误差导致漏掉 max
+	var count = int(math.Floor((max-min)/step+1e-9)) + 1
+	for i := 0; i < count; i++ {
+		param.Values = append(param.Values, roundStep(min+float64(i)*step))
+	}
+	return param
+}
+
+// Values 使用给定的取值
+func Values(name string, values ...float64) Param {
+	return Param{Name: name, Values: values}
+}
+
+// roundStep 去掉 0.1+0.2 这类浮点误差，保留 10 位小数
+func roundStep(v float64) float64 {
+	return math.Round(v*1e10) / 1e10
+}
+
+// Space 参数空间，组合为各参数取值的笛卡尔积
+type Space []Param
+
+// Size 全部组合的数量
+func (s Space) Size() int {
+	if len(s) == 0 {
+		return 0
+	}
+	var size = 1
+	for _, p := range s {
+		size *= len(p.Values)
+	}
+	return size
+}
+
+// params 根据每个参数的取值序号生成参数
+func (s Space) params(index []int) Params {
+	var params = make(Params, len(s))
+	for i, p := range s {
+		params[p.Name] = p.Values[index[i]]
+	}
+	return params
+}
+
+// grid 按顺序返回全部组合的取值序号，最后一个参数变化最快
+func (s Space) grid() [][]int {
+	var size = s.Size()
+	var result = make([][]int, 0, size)
+	for n := 0; n < size; n++ {
+		result = append(result, s.decode(n))
+	}
+	return result
+}
+
+// random 不重复地随机选取 count 个组合，count 不小于组合数量时返回全部组合
+func (s Space) random(count int, seed int64) [][]int {
+	var size = s.Size()
+	if count >= size {
+		return s.grid()
+	}
+	var r = rand.New(rand.NewSource(seed))
+	var result = make([][]int, 0, count)
+	var used = make(map[int]bool, count)
+	for len(result) < count {
+		var n = r.Intn(size)
+		if used[n] {
+			continue
+		}
+		used[n] = true
+		result = append(result, s.decode(n))
+	}
+	return result
+}
+
+// decode 把组合序号转换为每个参数的取值序号
+func (s Space) decode(n int) []int {
+	var index = make([]int, len(s))
+	for i := len(s) - 1; i >= 0; i-- {
+		index[i] = n % len(s[i].Values)
+		n /= len(s[i].Values)
+	}
+	return index
+}
+
+// Params 一组参数取值
+type Params map[string]float64
+
+// Int 返回整数参数
+func (p Params) Int(name string) int {
+	return int(math.Round(p[name]))
+}
+
+// Float 返回浮点数参数
+func (p Params) Float(name string) float64 {
+	return p[name]
+}
+
+// String implements the stringer interface, 按名称排序，例如 long=26,short=12
+func (p Params) String() string {
+	var names = make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var list = make([]string, len(names))
+	for i, name := range names {
+		list[i] = fmt.Sprintf("%s=%s", name, strconv.FormatFloat(p[name], 'f', -1, 64))
+	}
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,169 @@
+package walkforward
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/backtest"
+	"github.com/idoall/stockindicator/backtest/optimize"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// Config 滚动优化配置
+type Config struct {
+	// 样本内参数优化的配置，其中的 Backtest 同时用于样本外验证
+	Optimize optimize.Config
+	// 样本内窗口的时间长度
+	InSample time.Duration
+	// 样本外窗口的时间长度，也是窗口每次向后移动的长度
+	OutOfSample time.Duration
+	// 样本内窗口是否始终从第一根K线开始
+	Anchored bool
+}
+
+// DefaultConfig 默认配置：滚动窗口，样本内 1 年，样本外 3 个月
+func DefaultConfig() Config {
+	return Config{
+		Optimize:    optimize.DefaultConfig(),
+		InSample:    time.Duration(klines.OneYear),
+		OutOfSample: time.Duration(klines.OneYear) / 4,
+	}
+}
+
+// WalkForward 滚动优化与样本外验证：在每个样本内窗口上优化参数，再用最优参数回测紧随其后的样本外窗口，
+// 样本外的结果按时间拼接为一条权益曲线
+type WalkForward struct {
+	Name      string
+	Space     optimize.Space
+	Factory   optimize.Factory
+	Objective optimize.Objective
+	Config    Config
+	kline     *klines.Item
+}
+
+// WindowResult 一个窗口的优化与验证结果
+type WindowResult struct {
+	Window Window
+	// 样本内得分最高的参数
+	Params optimize.Params
+	// 样本内最优参数的回测报告
+	InSample *backtest.Report
+	// 样本外使用最优参数的回测报告，交易与权益曲线的序号、时间对应原始K线
+	OutOfSample *backtest.Report
+	// 样本外年化收益率 / 样本内年化收益率，样本内收益率不大于 0 时为 NaN
+	Efficiency float64
+}
+
+// Report 滚动优化报告
+type Report struct {
+	Windows []WindowResult
+	// 拼接全部样本外窗口的回测报告，每个窗口结束时平仓，下一个窗口使用上一个窗口结束时的权益
+	OutOfSample *backtest.Report
+	// Walk-Forward Efficiency，样本外整体年化收益率 / 样本内平均年化收益率，样本内平均收益率不大于 0 时为 NaN
+	Efficiency float64
+}
+
+// NewWalkForward new Func
+func NewWalkForward(klineItem *klines.Item, space optimize.Space, factory optimize.Factory, objective optimize.Objective, config Config) *WalkForward {
+	return &WalkForward{
+		Name:      "WalkForward",
+		Space:     space,
+		Factory:   factory,
+		Objective: objective,
+		Config:    config,
+		kline:     klineItem,
+	}
+}
+
+// Run 运行滚动优化
+func (e *WalkForward) Run() (*Report, error) {
+	windows, err := Split(e.kline, e.Config.InSample, e.Config.OutOfSample, e.Config.Anchored)
+	if err != nil {
+		return nil, err
+	}
+
+	var report = &Report{Windows: make([]WindowResult, len(windows))}
+	var capital = e.Config.Optimize.Backtest.InitialCapital
+	var trades []backtest.Trade
+	var equity []backtest.EquityData
+	var isAnnual float64
+
+	for i, window := range windows {
+		var in, out = window.InSample, window.OutOfSample
+
+		// 样本内优化
+		results, err := optimize.NewOptimizer(subItem(e.kline, in.From, in.To), e.Space, e.Factory, e.Objective, e.Config.Optimize).Run()
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", window.Index, err)
+		}
+		var best = results.Best()
+
+		// 样本外验证，样本内的K线用于指标预热，截止到样本外窗口结束，不会用到之后的数据
+		var config = e.Config.Optimize.Backtest
+		config.InitialCapital = capital
+		config.CloseOnFinish = true
+		var item = subItem(e.kline, in.From, out.To)
+		oos, err := backtest.NewBacktest(item, config).RunSidesRange(
+			e.Factory(item, best.Params).AnalysisSide(),
+			out.From-in.From,
+			out.To-in.From,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", window.Index, err)
+		}
+		for x := range oos.Trades {
+			oos.Trades[x].EntryIndex += in.From
+			oos.Trades[x].ExitIndex += in.From
+		}
+		capital = oos.FinalEquity
+		trades = append(trades, oos.Trades...)
+		equity = append(equity, oos.Equity...)
+
+		var inReturn = annualized(best.Report.TotalReturn, in)
+		isAnnual += inReturn
+		report.Windows[i] = WindowResult{
+			Window:      window,
+			Params:      best.Params,
+			InSample:    best.Report,
+			OutOfSample: oos,
+			Efficiency:  efficiency(annualized(oos.TotalReturn, out), inReturn),
+		}
+	}
+
+	// 按拼接后的权益曲线重新计算回撤
+	var peak = e.Config.Optimize.Backtest.InitialCapital
+	for i := range equity {
+		if equity[i].Equity > peak {
+			peak = equity[i].Equity
+		}
+		equity[i].Drawdown = 0
+		if peak > 0 {
+			equity[i].Drawdown = (peak - equity[i].Equity) / peak
+		}
+	}
+	report.OutOfSample = backtest.NewReport(e.Name, e.kline.Interval, e.Config.Optimize.Backtest, trades, equity)
+
+	var total = Segment{
+		Start: windows[0].OutOfSample.Start,
+		End:   windows[len(windows)-1].OutOfSample.End,
+	}
+	report.Efficiency = efficiency(annualized(report.OutOfSample.TotalReturn, total), isAnnual/float64(len(windows)))
+	return report, nil
+}
+
+// annualized 按时间长度线性年化收益率
+func annualized(totalReturn float64, segment Segment) float64 {
+	var years = segment.End.Time.Sub(segment.Start.Time).Hours() / time.Duration(klines.OneYear).Hours()
+	if years <= 0 {
+		return 0
+	}
+	return totalReturn / years
+}
+
+func efficiency(outOfSample, inSample float64) float64 {
+	if inSample <= 0 {
+		return math.NaN()
+	}
+	return outOfSample / inSample
+}
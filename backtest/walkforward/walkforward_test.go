@@ -0,0 +1,107 @@
+package walkforward
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/idoall/stockindicator/backtest/optimize"
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+const day = 24 * time.Hour
+
+// RUN
+// go test -v ./backtest/walkforward -run TestSplit
+func TestSplit(t *testing.T) {
+	t.Parallel()
+	// 30 分钟K线，共 20 天
+	var list = utils.GetRandomKlineItem(960, 1)
+
+	windows, err := Split(list, 10*day, 3*day, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 样本外窗口从第 10 天开始，每 3 天一个，最后一个只有 1 天
+	if len(windows) != 4 || windows[3].OutOfSample.Len() != 48 || !windows[3].OutOfSample.End.Time.Equal(time.Unix(list.Candles[959].TimeUnix, 0).Add(30*time.Minute)) {
+		t.Fatalf("unexpected windows %+v", windows)
+	}
+	for i, w := range windows {
+		if w.InSample.Len() != 480 || w.InSample.To != w.OutOfSample.From {
+			t.Fatalf("[%d] unexpected rolling window %+v", i, w)
+		}
+		if i > 0 && windows[i-1].OutOfSample.To != w.OutOfSample.From {
+			t.Fatalf("[%d] out-of-sample windows are not contiguous", i)
+		}
+	}
+
+	anchored, err := Split(list, 10*day, 3*day, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range anchored {
+		if w.InSample.From != 0 || w.InSample.To != windows[i].InSample.To {
+			t.Fatalf("[%d] unexpected anchored window %+v", i, w)
+		}
+	}
+
+	if _, err = Split(list, 30*day, 3*day, false); !errors.Is(err, ErrNoWindows) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNoWindows)
+	}
+	if _, err = Split(list, 0, 3*day, false); !errors.Is(err, ErrInvalidWindow) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrInvalidWindow)
+	}
+}
+
+// RUN
+// go test -v ./backtest/walkforward -run TestWalkForward
+func TestWalkForward(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(960, 2)
+	var space = optimize.Space{
+		optimize.Range("short", 6, 12, 3),
+		optimize.Range("long", 20, 30, 5),
+	}
+	var config = DefaultConfig()
+	config.InSample = 10 * day
+	config.OutOfSample = 3 * day
+
+	report, err := NewWalkForward(list, space, func(item *klines.Item, p optimize.Params) utils.IStrategy {
+		return trend.NewMacd(item, p.Int("short"), 9, p.Int("long"))
+	}, optimize.NetProfit, config).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Windows) != 4 {
+		t.Fatalf("expected 4 windows, got %d", len(report.Windows))
+	}
+
+	var candles int
+	var capital = config.Optimize.Backtest.InitialCapital
+	for i, w := range report.Windows {
+		candles += w.Window.OutOfSample.Len()
+		if w.Params == nil || w.InSample == nil {
+			t.Fatalf("[%d] missing in-sample result", i)
+		}
+		if w.OutOfSample.InitialCapital != capital {
+			t.Fatalf("[%d] initial capital %f expected %f", i, w.OutOfSample.InitialCapital, capital)
+		}
+		capital = w.OutOfSample.FinalEquity
+		for _, trade := range w.OutOfSample.Trades {
+			if trade.EntryIndex < w.Window.OutOfSample.From || trade.ExitIndex >= w.Window.OutOfSample.To {
+				t.Fatalf("[%d] trade %+v outside of out-of-sample window", i, trade)
+			}
+		}
+	}
+	if len(report.OutOfSample.Equity) != candles {
+		t.Fatalf("expected %d equity points, got %d", candles, len(report.OutOfSample.Equity))
+	}
+	if report.OutOfSample.FinalEquity != capital {
+		t.Fatalf("final equity %f expected %f", report.OutOfSample.FinalEquity, capital)
+	}
+	if !report.OutOfSample.Equity[0].Time.Equal(report.Windows[0].Window.OutOfSample.Start.Time) {
+		t.Fatalf("stitched equity starts at %s", report.OutOfSample.Equity[0].Time)
+	}
+}
@@ -0,0 +1,102 @@
+package walkforward
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/idoall/stockindicator/backtest"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrInvalidWindow 样本内或样本外的时间长度不正确
+	ErrInvalidWindow = errors.New("walkforward: in-sample and out-of-sample durations must be greater than zero")
+	// ErrNoWindows K线数据不足以生成任何窗口
+	ErrNoWindows = errors.New("walkforward: not enough candles for a single window")
+)
+
+// Segment 一段连续的K线，[Start, End) 为时间范围，[From, To) 为K线序号范围
+type Segment struct {
+	Start klines.IntervalTime
+	End   klines.IntervalTime
+	From  int
+	To    int
+}
+
+// Len K线数量
+func (s Segment) Len() int {
+	return s.To - s.From
+}
+
+// Window 一个样本内（优化）与紧随其后的样本外（验证）窗口
+type Window struct {
+	Index       int
+	InSample    Segment
+	OutOfSample Segment
+}
+
+// Split 按时间把K线划分为窗口，K线需要按时间升序排列。
+// 样本外窗口首尾相接，每次向后移动 outOfSample；anchored 为 false 时样本内窗口长度固定为 inSample（滚动），
+// 为 true 时样本内窗口始终从第一根K线开始（锚定）。最后一个样本外窗口不足 outOfSample 时只包含剩余的K线。
+func Split(item *klines.Item, inSample, outOfSample time.Duration, anchored bool) ([]Window, error) {
+	if item == nil || len(item.Candles) == 0 {
+		return nil, backtest.ErrNoCandles
+	}
+	if inSample <= 0 || outOfSample <= 0 {
+		return nil, ErrInvalidWindow
+	}
+
+	var candles = item.Candles
+	var first = time.Unix(candles[0].TimeUnix, 0)
+	// search 返回第一根时间不早于 t 的K线序号
+	var search = func(t time.Time) int {
+		return sort.Search(len(candles), func(i int) bool { return candles[i].TimeUnix >= t.Unix() })
+	}
+	var segment = func(start, end time.Time) Segment {
+		return Segment{
+			Start: klines.CreateIntervalTime(start),
+			End:   klines.CreateIntervalTime(end),
+			From:  search(start),
+			To:    search(end),
+		}
+	}
+
+	var windows []Window
+	for k := 0; ; k++ {
+		var outStart = first.Add(inSample + time.Duration(k)*outOfSample)
+		var out = segment(outStart, outStart.Add(outOfSample))
+		if out.From >= len(candles) {
+			break
+		}
+		var inStart = outStart.Add(-inSample)
+		if anchored {
+			inStart = first
+		}
+		// 最后一个样本外窗口的结束时间不超过最后一根K线
+		if last := time.Unix(candles[len(candles)-1].TimeUnix, 0).Add(item.Interval.Duration()); out.To == len(candles) && item.Interval > 0 && out.End.Time.After(last) {
+			out.End = klines.CreateIntervalTime(last)
+		}
+		var in = segment(inStart, outStart)
+		if in.Len() == 0 || out.Len() == 0 {
+			continue
+		}
+		windows = append(windows, Window{Index: len(windows), InSample: in, OutOfSample: out})
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("%w: %d candles", ErrNoWindows, len(candles))
+	}
+	return windows, nil
+}
+
+// subItem 返回 [from, to) 范围内的K线，与 item 共用K线数据
+func subItem(item *klines.Item, from, to int) *klines.Item {
+	return &klines.Item{
+		Exchange: item.Exchange,
+		Symbol:   item.Symbol,
+		Code:     item.Code,
+		Interval: item.Interval,
+		Candles:  item.Candles[from:to],
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/idoall/stockindicator/utils"
 	"github.com/idoall/stockindicator/utils/klines"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 /*
@@ -49,8 +50,25 @@ type Boll struct {
 	Name    string
 	PeriodN int //计算周期
 	PeriodK int //带宽
+	// 中轨均线类型，默认 SMA
+	MATypes types.MATypes
 	data    []BollData
 	kline   *klines.Item
+	// 增量计算状态
+	stream *bollStream
+}
+
+// bollStream 增量计算时保存的窗口累计值，last 为最后一根K线之前的状态，用于 UpdateLast
+type bollStream struct {
+	last  bollState
+	state bollState
+}
+
+type bollState struct {
+	sum  float64
+	sum2 float64
+	// 中轨不是 SMA 时的均线状态
+	ma ta.MAState
 }
 
 type BollData struct {
@@ -65,7 +83,18 @@ type BollData struct {
 // NewBoll Func
 // 使用方法，先添加最早日期的数据,最后一条应该是当前日期的数据，结果与 AICoin 对比完全一致
 func NewBoll(klineItem *klines.Item, periodN, periodK int) *Boll {
-	return &Boll{Name: fmt.Sprintf("Boll%d-%d", periodN, periodK), PeriodN: periodN, PeriodK: periodK, kline: klineItem}
+	return &Boll{Name: fmt.Sprintf("Boll%d-%d", periodN, periodK), PeriodN: periodN, PeriodK: periodK, MATypes: types.SMA, kline: klineItem}
+}
+
+// NewBollMA Func
+// 中轨使用 maType 指定的均线，标准差仍按收盘价计算
+func NewBollMA(klineItem *klines.Item, periodN, periodK int, maType types.MATypes) *Boll {
+	m := NewBoll(klineItem, periodN, periodK)
+	m.MATypes = maType
+	if maType != types.SMA {
+		m.Name = fmt.Sprintf("Boll%d-%d-%s", periodN, periodK, maType)
+	}
+	return m
 }
 
 // NewDefaultBoll Func
@@ -98,8 +127,12 @@ func (e *Boll) Calculation() *Boll {
 
 	e.data = make([]BollData, l)
 
-	var middle = trend.NewSma(e.kline, e.PeriodN).GetValues()
-	var md = e.dma(middle)
+	var sma = trend.NewSma(e.kline, e.PeriodN).GetValues()
+	var md = e.dma(sma)
+	var middle = sma
+	if !e.isSma() {
+		middle = ta.MovingAverage(e.MATypes, e.PeriodN, e.kline.GetOHLC().Close)
+	}
 	var upper = ta.Add(middle, md)
 	var lower = ta.Subtract(middle, md)
 
@@ -115,6 +148,11 @@ func (e *Boll) Calculation() *Boll {
 	return e
 }
 
+// isSma 中轨是否为 SMA，未设置均线类型时按 SMA 处理
+func (e *Boll) isSma() bool {
+	return e.MATypes == types.SMA || e.MATypes == types.UnknownMATypes
+}
+
 // AnalysisSide Func
 // 收盘价高于 upperBand 时提供卖出操作
 // 收盘价低于 lowerBand 值时提供买入操作。
@@ -158,3 +196,92 @@ func (e *Boll) GetData() []BollData {
 	}
 	return e.data
 }
+
+// Update 追加一根已完成的K线，返回最新的 Boll 数据。
+// 中轨为 TRIMA、MAMA 时没有增量状态，调用时 panic，见 ta.CanStreamMA
+func (e *Boll) Update(candle *klines.Candle) BollData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	var p BollData
+	e.stream.last = e.stream.state
+	e.stream.state, p = e.step(e.stream.last, index)
+
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Boll 数据，耗时与 Update 相同
+func (e *Boll) UpdateLast(candle *klines.Candle) BollData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	e.stream.state, e.data[index] = e.step(e.stream.last, index)
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Boll) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if !e.isSma() && !ta.CanStreamMA(e.MATypes) {
+		panic(fmt.Errorf("%w: %s", ta.ErrMAStreaming, e.MATypes))
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &bollStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state, _ = e.step(e.stream.last, i)
+	}
+}
+
+// step 与 Calculation 的计算方式一致，标准差为 dma
+func (e *Boll) step(state bollState, index int) (bollState, BollData) {
+	var period = e.PeriodN
+	var candle = e.kline.Candles[index]
+
+	var count = index + 1
+	state.sum += candle.Close
+	if index >= period {
+		state.sum -= e.kline.Candles[index-period].Close
+		count = period
+	}
+	var sma = state.sum / float64(count)
+	if math.IsNaN(sma) || math.IsInf(sma, -1) {
+		sma = 0
+	}
+
+	var md float64
+	state.sum2 += candle.Close * candle.Close
+	if index >= period-1 {
+		md = math.Sqrt(state.sum2/float64(period) - sma*sma)
+		var w = e.kline.Candles[index-(period-1)].Close
+		state.sum2 -= w * w
+	}
+
+	var middle = sma
+	if !e.isSma() {
+		var start = max(index-period, 0)
+		var closes = make([]float64, index-start+1)
+		for i := range closes {
+			closes[i] = e.kline.Candles[start+i].Close
+		}
+		state.ma, middle = state.ma.Next(e.MATypes, period, closes)
+	}
+
+	return state, BollData{
+		Time:   time.Unix(candle.TimeUnix, 0),
+		Middle: middle,
+		Upper:  middle + md,
+		Lower:  middle - md,
+		MD:     md,
+	}
+}
@@ -1,10 +1,15 @@
 package channel
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 // RUN
@@ -29,3 +34,31 @@ func TestBoll(t *testing.T) {
 	}
 
 }
+
+// RUN
+// go test -v ./channel -run TestBollUpdate
+func TestBollUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 8)
+	for _, maType := range []types.MATypes{types.SMA, types.EMA, types.WMA, types.DEMA, types.TEMA, types.KAMA, types.T3MA} {
+		batch := NewBollMA(list, 20, 2, maType).GetData()
+
+		stream := NewBollMA(&klines.Item{Interval: list.Interval}, 20, 2, maType)
+		for i, candle := range list.Candles {
+			partial := *candle
+			partial.Close = candle.Open
+			stream.Update(&partial)
+			v := stream.UpdateLast(candle)
+			if math.Abs(v.Upper-batch[i].Upper) > 1e-6 || math.Abs(v.Lower-batch[i].Lower) > 1e-6 || math.Abs(v.Middle-batch[i].Middle) > 1e-9 {
+				t.Fatalf("[%d] %s stream %+v batch %+v", i, maType, v, batch[i])
+			}
+		}
+	}
+	// TRIMA、MAMA 没有增量状态，拒绝增量计算
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ta.ErrMAStreaming) {
+			t.Fatalf("received '%v' expected '%v'", err, ta.ErrMAStreaming)
+		}
+	}()
+	NewBollMA(list, 20, 2, types.MAMA).Update(list.Candles[0])
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/idoall/stockindicator/utils"
 	"github.com/idoall/stockindicator/utils/klines"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 // Keltner Channels是一个波动性指标，由一位名叫 Chester Keltner 的交易商在他 1960 年的著作《如何在商品中赚钱》中引入。
@@ -15,14 +16,16 @@ import (
 // Linda 版本的 Keltner Channel 使用更广泛，它与布林带非常相似，因为它也由三条线组成。
 // 由于该通道源自ATR，而ATR本身就是一个波动率指标，因此Keltner 通道也会随着波动率收缩和扩张，但不像布林带那样波动。
 //
-// Middle Line = EMA(period, closings)
+// Middle Line = EMA(period, closings)，可通过 MATypes 指定其他均线
 // Upper Band = EMA(period, closings) + 2 * ATR(period, highs, lows, closings)
 // Lower Band = EMA(period, closings) - 2 * ATR(period, highs, lows, closings)
 type KeltnerChannel struct {
 	Name   string
 	Period int
-	data   []KeltnerChannelData
-	kline  *klines.Item
+	// 中轨均线类型，默认 EMA
+	MATypes types.MATypes
+	data    []KeltnerChannelData
+	kline   *klines.Item
 }
 
 // KeltnerChannelData
@@ -36,9 +39,21 @@ type KeltnerChannelData struct {
 // NewKeltnerChannel new Func
 func NewKeltnerChannel(klineItem *klines.Item, period int) *KeltnerChannel {
 	m := &KeltnerChannel{
-		Name:   fmt.Sprintf("KeltnerChannel%d", period),
-		kline:  klineItem,
-		Period: period,
+		Name:    fmt.Sprintf("KeltnerChannel%d", period),
+		kline:   klineItem,
+		Period:  period,
+		MATypes: types.EMA,
+	}
+	return m
+}
+
+// NewKeltnerChannelMA new Func
+// 中轨使用 maType 指定的均线
+func NewKeltnerChannelMA(klineItem *klines.Item, period int, maType types.MATypes) *KeltnerChannel {
+	m := NewKeltnerChannel(klineItem, period)
+	m.MATypes = maType
+	if maType != types.EMA {
+		m.Name = fmt.Sprintf("KeltnerChannel%d-%s", period, maType)
 	}
 	return m
 }
@@ -57,7 +72,12 @@ func (e *KeltnerChannel) Calculation() *KeltnerChannel {
 	// _, atr := trend.Atr(period, high, low, closing)
 	atr2 := ta.MultiplyBy(atr, 2)
 
-	middleLine := trend.NewEma(e.kline, period).GetValues()
+	var middleLine []float64
+	if e.MATypes == types.EMA || e.MATypes == types.UnknownMATypes {
+		middleLine = trend.NewEma(e.kline, period).GetValues()
+	} else {
+		middleLine = ta.MovingAverage(e.MATypes, period, e.kline.GetOHLC().Close)
+	}
 	upperBand := ta.Add(middleLine, atr2)
 	lowerBand := ta.Subtract(middleLine, atr2)
 
@@ -0,0 +1,46 @@
+package channel
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+	"github.com/idoall/stockindicator/utils/types"
+)
+
+// 注册通道类指标，参数顺序与 NewXxx 一致
+func init() {
+	registry.Register(registry.Definition{
+		Name: "Boll", Category: registry.Channel, Description: "布林带",
+		Params: []registry.Param{
+			registry.IntParam("periodN", 20, 1),
+			registry.IntParam("periodK", 2, 1),
+			registry.MATypeParam("maType", types.SMA),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewBollMA(item, p.Int("periodN"), p.Int("periodK"), p.MAType("maType"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "DonchianChannel", Aliases: []string{"donchian"}, Category: registry.Channel, Description: "唐奇安通道",
+		Params: []registry.Param{registry.IntParam("period", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewDonchianChannel(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "KeltnerChannel", Aliases: []string{"keltner"}, Category: registry.Channel, Description: "肯特纳通道",
+		Params: []registry.Param{
+			registry.IntParam("period", 20, 1),
+			registry.MATypeParam("maType", types.EMA),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewKeltnerChannelMA(item, p.Int("period"), p.MAType("maType"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "UlcerIndex", Category: registry.Channel, Description: "溃疡指数",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewUlcerIndex(item, p.Int("period"))
+		},
+	})
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/idoall/stockindicator/backtest"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/export"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/loader"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+// stringList 可以重复出现的参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// inputFlags 读取与输出相关的公共参数
+type inputFlags struct {
+	input      string
+	format     string
+	output     string
+	timeFormat string
+	timezone   string
+	interval   string
+}
+
+func (e *inputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&e.input, "input", "", "数据文件，支持 .csv .json .jsonl .ndjson .columnar.json 及对应的 .gz")
+	fs.StringVar(&e.format, "format", "table", "输出格式 table|csv|json")
+	fs.StringVar(&e.output, "output", "", "输出文件，默认输出到标准输出")
+	fs.StringVar(&e.timeFormat, "time-format", loader.TimeAuto, "CSV 时间格式 unix|unixms|Go layout，默认自动识别")
+	fs.StringVar(&e.timezone, "tz", "UTC", "解析与输出时间使用的时区，例如 Asia/Shanghai")
+	fs.StringVar(&e.interval, "interval", "", "K线周期，例如 30m、1h、1d、1w，默认根据数据推算")
+}
+
+func (e *inputFlags) location() (*time.Location, error) {
+	return time.LoadLocation(e.timezone)
+}
+
+// load 读取数据文件
+func (e *inputFlags) load() (*klines.Item, error) {
+	if e.input == "" {
+		return nil, fmt.Errorf("--input is required")
+	}
+	location, err := e.location()
+	if err != nil {
+		return nil, err
+	}
+	interval, err := parseInterval(e.interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts = loader.DefaultOptions()
+	opts.TimeFormat = e.timeFormat
+	opts.Location = location
+	opts.Interval = interval
+	return loader.LoadFile(e.input, opts)
+}
+
+// writer 返回输出目标，close 需要在写入完成后调用
+func (e *inputFlags) writer(stdout io.Writer) (w io.Writer, close func() error, err error) {
+	if e.output == "" {
+		return stdout, func() error { return nil }, nil
+	}
+	file, err := os.Create(e.output)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
+
+// parseInterval 解析K线周期，在 time.ParseDuration 的基础上支持 d 与 w
+func parseInterval(s string) (klines.Interval, error) {
+	if s == "" {
+		return 0, nil
+	}
+	for suffix, unit := range map[string]klines.Interval{"d": klines.OneDay, "w": klines.OneWeek} {
+		if number, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q", s)
+			}
+			return klines.Interval(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	return klines.Interval(d), nil
+}
+
+func listIndicators(stdout io.Writer) error {
+	var w = tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCATEGORY\tPARAMS\tSTRATEGY\tALIASES\tDESCRIPTION")
+	for _, v := range registry.List() {
+		var params = make([]string, len(v.Params))
+		for i, p := range v.Params {
+			params[i] = fmt.Sprintf("%s=%v", p.Name, p.Default)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", v.Name, v.Category, strings.Join(params, ","), v.Strategy, strings.Join(v.Aliases, ","), v.Description)
+	}
+	return w.Flush()
+}
+
+func compute(args []string, stdout io.Writer) error {
+	var fs = flag.NewFlagSet("compute", flag.ContinueOnError)
+	var input inputFlags
+	var specs stringList
+	input.register(fs)
+	fs.Var(&specs, "indicator", "指标及参数，例如 Macd(12,9,26) 或 macd:12,9,26，可以重复或用逗号分隔多个指标")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	parsed, err := parseSpecs(specs)
+	if err != nil {
+		return err
+	}
+	if len(parsed) == 0 {
+		return fmt.Errorf("--indicator is required")
+	}
+	item, err := input.load()
+	if err != nil {
+		return err
+	}
+
+	exporter, err := newExporter(item, &input)
+	if err != nil {
+		return err
+	}
+	for _, s := range parsed {
+		indicator, err := registry.BuildSpec(item, s)
+		if err != nil {
+			return err
+		}
+		exporter.AddIndicator(indicator)
+	}
+	return writeExporter(exporter, &input, stdout)
+}
+
+func signals(args []string, stdout io.Writer) error {
+	var fs = flag.NewFlagSet("signals", flag.ContinueOnError)
+	var input inputFlags
+	var specs stringList
+	var combine string
+	input.register(fs)
+	fs.Var(&specs, "strategies", "策略及参数，例如 rsi,kdj 或 Macd(12,9,26)，可以重复")
+	fs.StringVar(&combine, "combine", "", "额外输出组合信号 all|any|majority")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	item, err := input.load()
+	if err != nil {
+		return err
+	}
+	strategies, err := buildStrategies(specs, item)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := newExporter(item, &input)
+	if err != nil {
+		return err
+	}
+	exporter.AddStrategy(strategies...)
+	switch combine {
+	case "":
+	case "all":
+		exporter.AddStrategy(utils.NewAllAgree(strategies...))
+	case "any":
+		exporter.AddStrategy(utils.NewAnyOf(strategies...))
+	case "majority":
+		exporter.AddStrategy(utils.NewMajority(strategies...))
+	default:
+		return fmt.Errorf("unknown --combine %q", combine)
+	}
+	return writeExporter(exporter, &input, stdout)
+}
+
+func runBacktest(args []string, stdout io.Writer) error {
+	var fs = flag.NewFlagSet("backtest", flag.ContinueOnError)
+	var input inputFlags
+	var specs stringList
+	var config = backtest.DefaultConfig()
+	var fill string
+	input.register(fs)
+	fs.Var(&specs, "strategies", "策略及参数，多个策略时分别回测")
+	fs.Float64Var(&config.InitialCapital, "capital", config.InitialCapital, "初始资金")
+	fs.Float64Var(&config.Commission, "commission", config.Commission, "手续费率")
+	fs.Float64Var(&config.Slippage, "slippage", config.Slippage, "滑点比例")
+	fs.BoolVar(&config.AllowShort, "short", config.AllowShort, "允许做空")
+	fs.StringVar(&fill, "fill", "next", "成交方式 next（下一根开盘价）|close（当根收盘价）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch fill {
+	case "next":
+		config.FillMode = backtest.FillNextOpen
+	case "close":
+		config.FillMode = backtest.FillSameClose
+	default:
+		return fmt.Errorf("unknown --fill %q", fill)
+	}
+
+	item, err := input.load()
+	if err != nil {
+		return err
+	}
+	strategies, err := buildStrategies(specs, item)
+	if err != nil {
+		return err
+	}
+
+	var reports = make([]*backtest.Report, len(strategies))
+	for i, strategy := range strategies {
+		if reports[i], err = backtest.NewBacktest(item, config).Run(strategy); err != nil {
+			return err
+		}
+	}
+
+	w, closeFn, err := input.writer(stdout)
+	if err != nil {
+		return err
+	}
+	if err = writeReports(w, input.format, reports); err != nil {
+		closeFn()
+		return err
+	}
+	return closeFn()
+}
+
+func buildStrategies(specs []string, item *klines.Item) ([]utils.IStrategy, error) {
+	parsed, err := parseSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("--strategies is required")
+	}
+	var strategies = make([]utils.IStrategy, len(parsed))
+	for i, s := range parsed {
+		if strategies[i], err = registry.BuildStrategy(item, s); err != nil {
+			return nil, err
+		}
+	}
+	return strategies, nil
+}
+
+func newExporter(item *klines.Item, input *inputFlags) (*export.Exporter, error) {
+	location, err := input.location()
+	if err != nil {
+		return nil, err
+	}
+	var exporter = export.NewExporter(item)
+	exporter.Location = location
+	return exporter, nil
+}
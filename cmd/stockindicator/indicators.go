@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	// 导入各指标包以注册全部指标
+	_ "github.com/idoall/stockindicator/channel"
+	_ "github.com/idoall/stockindicator/levels"
+	_ "github.com/idoall/stockindicator/oscillator"
+	_ "github.com/idoall/stockindicator/patterns"
+	_ "github.com/idoall/stockindicator/trend"
+	_ "github.com/idoall/stockindicator/volume"
+)
+
+// parseSpecs 解析以逗号分隔的指标列表，返回 registry 格式的描述字符串。
+// 支持 registry 的 Boll(20,2) 格式，以及 macd:12,9,26 的简写，简写中的数字会作为前一个指标的参数
+//
+//	"macd:12,9,26,Boll(20,2),kdj" => Macd(12,9,26) Boll(20,2) kdj
+func parseSpecs(values []string) ([]string, error) {
+	var specs []string
+	// 简写格式的参数还可以继续追加
+	var shorthand = false
+	for _, value := range values {
+		var depth = 0
+		var current strings.Builder
+		var tokens []string
+		for _, r := range value {
+			switch {
+			case r == '(':
+				depth++
+			case r == ')':
+				depth--
+			case r == ',' && depth == 0:
+				tokens = append(tokens, current.String())
+				current.Reset()
+				continue
+			}
+			current.WriteRune(r)
+		}
+		if depth != 0 {
+			return nil, fmt.Errorf("unbalanced parentheses in %q", value)
+		}
+		tokens = append(tokens, current.String())
+
+		for _, token := range tokens {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(token, 64); err == nil {
+				if !shorthand {
+					return nil, fmt.Errorf("parameter %s without indicator", token)
+				}
+				var last = specs[len(specs)-1]
+				specs[len(specs)-1] = last[:len(last)-1] + "," + token + ")"
+				continue
+			}
+
+			shorthand = false
+			if name, param, ok := strings.Cut(token, ":"); ok && !strings.Contains(token, "(") {
+				specs = append(specs, fmt.Sprintf("%s(%s)", name, param))
+				shorthand = true
+				continue
+			}
+			specs = append(specs, token)
+		}
+	}
+	return specs, nil
+}
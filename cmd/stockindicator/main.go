@@ -0,0 +1,63 @@
+// stockindicator 命令行工具，对数据文件计算指标、生成策略信号并回测
+//
+//	stockindicator indicators list
+//	stockindicator compute --indicator macd:12,9,26 --indicator boll --input data.csv
+//	stockindicator signals --strategies rsi,kdj --input data.csv --format csv
+//	stockindicator backtest --strategies macd --input data.csv --format json
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: stockindicator <command> [flags]
+
+Commands:
+  indicators list   列出所有指标及默认参数
+  compute           计算指标并输出
+  signals           运行策略并输出买卖信号
+  backtest          对策略进行回测
+
+Run 'stockindicator <command> -h' for flags of each command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run 执行子命令，结果写入 stdout
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, usage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "indicators":
+		if len(args) < 2 || args[1] != "list" {
+			return fmt.Errorf("unknown command, expected `indicators list`")
+		}
+		return listIndicators(stdout)
+	case "compute":
+		return compute(args[1:], stdout)
+	case "signals":
+		return signals(args[1:], stdout)
+	case "backtest":
+		return runBacktest(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stdout, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
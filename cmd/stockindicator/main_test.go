@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+)
+
+// writeTestCSV 把随机K线写入临时 CSV 文件
+func writeTestCSV(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("time,open,high,low,close,volume\n")
+	for _, v := range utils.GetRandomKlineItem(200, 1).Candles {
+		fmt.Fprintf(&buf, "%d,%f,%f,%f,%f,%f\n", v.TimeUnix, v.Open, v.High, v.Low, v.Close, v.Volume)
+	}
+	var path = filepath.Join(t.TempDir(), "test.csv")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// RUN
+// go test -v ./cmd/stockindicator -run TestParseSpecs
+func TestParseSpecs(t *testing.T) {
+	t.Parallel()
+	specs, err := parseSpecs([]string{"macd:12,9,26,Boll(20, 2),rsi:7", "KDJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []string{"macd(12,9,26)", "Boll(20, 2)", "rsi(7)", "KDJ"}
+	if !reflect.DeepEqual(specs, expected) {
+		t.Fatalf("received %+v expected %+v", specs, expected)
+	}
+
+	if _, err = parseSpecs([]string{"12,macd"}); err == nil {
+		t.Fatal("expected error for parameter without indicator")
+	}
+	if _, err = parseSpecs([]string{"Boll(20,2"}); err == nil {
+		t.Fatal("expected error for unbalanced parentheses")
+	}
+}
+
+// RUN
+// go test -v ./cmd/stockindicator -run TestCommands
+func TestCommands(t *testing.T) {
+	t.Parallel()
+	var input = writeTestCSV(t)
+
+	var out bytes.Buffer
+	if err := run([]string{"compute", "--indicator", "macd:12,9,26,boll", "--input", input, "--format", "csv"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 201 || !strings.Contains(strings.Join(records[0], ","), "Macd12-9-26.DIF") {
+		t.Fatalf("unexpected output header %v", records[0])
+	}
+
+	out.Reset()
+	if err = run([]string{"signals", "--strategies", "rsi,kdj", "--combine", "all", "--input", input, "--format", "json"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]interface{}
+	if err = json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 200 {
+		t.Fatalf("expected 200 rows, got %d", len(rows))
+	}
+
+	out.Reset()
+	if err = run([]string{"backtest", "--strategies", "macd", "--input", input, "--format", "table"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "final_equity") {
+		t.Fatalf("unexpected backtest output %s", out.String())
+	}
+
+	out.Reset()
+	if err = run([]string{"indicators", "list"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "short=12,signal=9,long=26") {
+		t.Fatalf("unexpected list output %s", out.String())
+	}
+
+	if err = run([]string{"compute", "--indicator", "unknown", "--input", input}, &out); err == nil {
+		t.Fatal("expected error for unknown indicator")
+	}
+}
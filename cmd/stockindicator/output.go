@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/idoall/stockindicator/backtest"
+	"github.com/idoall/stockindicator/utils/export"
+)
+
+// writeExporter 按格式输出宽表
+func writeExporter(exporter *export.Exporter, input *inputFlags, stdout io.Writer) error {
+	w, closeFn, err := input.writer(stdout)
+	if err != nil {
+		return err
+	}
+
+	switch input.format {
+	case "csv":
+		err = exporter.WriteCSV(w)
+	case "json":
+		err = exporter.WriteJSON(w)
+	case "table":
+		var records [][]string
+		if records, err = exporter.Records(); err == nil {
+			err = writeTable(w, records)
+		}
+	default:
+		err = fmt.Errorf("unknown --format %q", input.format)
+	}
+	if err != nil {
+		closeFn()
+		return err
+	}
+	return closeFn()
+}
+
+// writeTable 以对齐的文本表格输出
+func writeTable(w io.Writer, records [][]string) error {
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, record := range records {
+		fmt.Fprintln(tw, strings.Join(record, "\t"))
+	}
+	return tw.Flush()
+}
+
+// summary 回测报告中的统计数据，按顺序输出
+func summary(report *backtest.Report) [][2]interface{} {
+	return [][2]interface{}{
+		{"name", report.Name},
+		{"initial_capital", report.InitialCapital},
+		{"final_equity", report.FinalEquity},
+		{"net_profit", report.NetProfit},
+		{"total_return", report.TotalReturn},
+		{"max_drawdown", report.MaxDrawdown},
+		{"max_drawdown_value", report.MaxDrawdownValue},
+		{"sharpe", report.Sharpe},
+		{"sortino", report.Sortino},
+		{"win_rate", report.WinRate},
+		{"profit_factor", report.ProfitFactor},
+		{"total_trades", report.TotalTrades},
+		{"winning_trades", report.WinningTrades},
+		{"losing_trades", report.LosingTrades},
+		{"gross_profit", report.GrossProfit},
+		{"gross_loss", report.GrossLoss},
+		{"total_commission", report.TotalCommission},
+	}
+}
+
+var tradeColumns = []string{"strategy", "direction", "entry_time", "entry_price", "exit_time", "exit_price", "quantity", "commission", "profit", "return", "bars"}
+
+func tradeRecord(name string, trade backtest.Trade) []string {
+	return []string{
+		name,
+		trade.Direction.String(),
+		trade.EntryTime.UTC().Format(time.RFC3339),
+		formatFloat(trade.EntryPrice),
+		trade.ExitTime.UTC().Format(time.RFC3339),
+		formatFloat(trade.ExitPrice),
+		formatFloat(trade.Quantity),
+		formatFloat(trade.Commission),
+		formatFloat(trade.Profit),
+		formatFloat(trade.ReturnPercent),
+		strconv.Itoa(trade.Bars),
+	}
+}
+
+// writeReports 输出回测报告，table 输出统计与交易明细，csv 只输出交易明细，json 输出全部
+func writeReports(w io.Writer, format string, reports []*backtest.Report) error {
+	switch format {
+	case "table":
+		var records = [][]string{{"METRIC"}}
+		for _, report := range reports {
+			records[0] = append(records[0], report.Name)
+		}
+		for i, row := range summary(reports[0])[1:] {
+			var record = []string{row[0].(string)}
+			for _, report := range reports {
+				record = append(record, formatValue(summary(report)[i+1][1]))
+			}
+			records = append(records, record)
+		}
+		if err := writeTable(w, records); err != nil {
+			return err
+		}
+
+		records = [][]string{tradeColumns}
+		for _, report := range reports {
+			for _, trade := range report.Trades {
+				records = append(records, tradeRecord(report.Name, trade))
+			}
+		}
+		fmt.Fprintln(w)
+		return writeTable(w, records)
+	case "csv":
+		var records = [][]string{tradeColumns}
+		for _, report := range reports {
+			for _, trade := range report.Trades {
+				records = append(records, tradeRecord(report.Name, trade))
+			}
+		}
+		return csv.NewWriter(w).WriteAll(records)
+	case "json":
+		var output = make([]map[string]interface{}, len(reports))
+		for i, report := range reports {
+			output[i] = map[string]interface{}{}
+			for _, row := range summary(report) {
+				var value = row[1]
+				if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+					value = nil
+				}
+				output[i][row[0].(string)] = value
+			}
+			output[i]["trades"] = report.Trades
+		}
+		var encoder = json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	default:
+		return fmt.Errorf("unknown --format %q", format)
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case float64:
+		return formatFloat(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
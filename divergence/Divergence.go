@@ -0,0 +1,284 @@
+package divergence
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// Kind 背离类型
+type Kind int
+
+const (
+	// RegularBullish 常规底背离：价格创更低的低点，指标形成更高的低点，预示下跌趋势反转
+	RegularBullish Kind = iota
+	// RegularBearish 常规顶背离：价格创更高的高点，指标形成更低的高点，预示上涨趋势反转
+	RegularBearish
+	// HiddenBullish 隐藏底背离：价格形成更高的低点，指标创更低的低点，预示上涨趋势延续
+	HiddenBullish
+	// HiddenBearish 隐藏顶背离：价格形成更低的高点，指标创更高的高点，预示下跌趋势延续
+	HiddenBearish
+)
+
+// String Func
+func (k Kind) String() string {
+	switch k {
+	case RegularBullish:
+		return "RegularBullish"
+	case RegularBearish:
+		return "RegularBearish"
+	case HiddenBullish:
+		return "HiddenBullish"
+	case HiddenBearish:
+		return "HiddenBearish"
+	}
+	return "Unknown"
+}
+
+// IsBullish 是否为底背离
+func (k Kind) IsBullish() bool {
+	return k == RegularBullish || k == HiddenBullish
+}
+
+// Config 背离识别参数
+type Config struct {
+	// 指标枢轴点左右两侧的K线数量，枢轴点在其右侧 PivotRight 根K线之后才确认
+	PivotLeft  int
+	PivotRight int
+	// 前后两个枢轴点之间的最小与最大K线数量
+	MinDistance int
+	MaxDistance int
+	// 枢轴点确认之后再等待的K线数量，等待期间指标与价格都没有突破第二个枢轴点时才确认背离
+	Confirmation int
+	// 是否识别常规背离与隐藏背离
+	Regular bool
+	Hidden  bool
+}
+
+// DefaultConfig 默认参数：枢轴点左右各 5 根K线，两个枢轴点间隔 5 到 60 根K线，识别常规与隐藏背离
+func DefaultConfig() Config {
+	return Config{
+		PivotLeft:   5,
+		PivotRight:  5,
+		MinDistance: 5,
+		MaxDistance: 60,
+		Regular:     true,
+		Hidden:      true,
+	}
+}
+
+// Point 背离的一个枢轴点
+type Point struct {
+	Index int
+	Time  time.Time
+	// 底背离为最低价，顶背离为最高价
+	Price float64
+	Value float64
+}
+
+// Divergence 一次背离
+type Divergence struct {
+	Kind   Kind
+	First  Point
+	Second Point
+	// 确认背离的K线，之前的K线不会看到这次背离
+	ConfirmIndex int
+	ConfirmTime  time.Time
+}
+
+// Detector 价格与任意指标之间的背离识别
+// 在指标上寻找枢轴高点与低点，与前一个同方向的枢轴点比较，价格使用枢轴点所在K线的最高价或最低价。
+// 背离在第二个枢轴点之后 PivotRight + Confirmation 根K线上确认，不会用到未来数据，也不会重绘。
+type Detector struct {
+	Name      string
+	Config    Config
+	indicator []float64
+	data      []Divergence
+	kline     *klines.Item
+}
+
+// NewDetector new Func
+// indicator 与K线一一对应，比K线少时按最后一根右对齐，NaN 的位置不会成为枢轴点
+func NewDetector(klineItem *klines.Item, indicator []float64, config Config) *Detector {
+	var values = make([]float64, len(klineItem.Candles))
+	var offset = len(values) - len(indicator)
+	for i := range values {
+		values[i] = math.NaN()
+		if i-offset >= 0 && i-offset < len(indicator) {
+			values[i] = indicator[i-offset]
+		}
+	}
+	return &Detector{
+		Name:      fmt.Sprintf("Divergence%d-%d", config.PivotLeft, config.PivotRight),
+		Config:    config,
+		indicator: values,
+		kline:     klineItem,
+	}
+}
+
+// NewDefaultDetector new Func
+func NewDefaultDetector(klineItem *klines.Item, indicator []float64) *Detector {
+	return NewDetector(klineItem, indicator, DefaultConfig())
+}
+
+// Series 从指标的 GetData() 中取出一列数据，例如
+//
+//	divergence.Series(rsi.GetData(), func(v trend.RsiData) float64 { return v.Value })
+func Series[T any](data []T, value func(T) float64) []float64 {
+	var result = make([]float64, len(data))
+	for i, v := range data {
+		result[i] = value(v)
+	}
+	return result
+}
+
+// Calculation Func
+func (e *Detector) Calculation() *Detector {
+	e.data = []Divergence{}
+	var ohlc = e.kline.GetOHLC()
+	// 顶背离使用最高价与枢轴高点，底背离使用最低价与枢轴低点
+	e.detect(ohlc.High, false)
+	e.detect(ohlc.Low, true)
+
+	// 按确认的先后排序，同一根K线确认的背离底背离在前
+	sort.SliceStable(e.data, func(i, j int) bool {
+		if e.data[i].ConfirmIndex != e.data[j].ConfirmIndex {
+			return e.data[i].ConfirmIndex < e.data[j].ConfirmIndex
+		}
+		return e.data[i].Kind.IsBullish() && !e.data[j].Kind.IsBullish()
+	})
+	return e
+}
+
+// detect 识别一个方向的背离，bullish 为 true 时使用枢轴低点
+func (e *Detector) detect(prices []float64, bullish bool) {
+	var c = e.Config
+	var values = e.indicator
+	var last = -1
+	for p := range values {
+		if !e.isPivot(p, bullish) {
+			continue
+		}
+		var q = last
+		last = p
+		if q < 0 || p-q < c.MinDistance || c.MaxDistance > 0 && p-q > c.MaxDistance {
+			continue
+		}
+
+		var kind Kind
+		var lowerPrice, lowerValue = prices[p] < prices[q], values[p] < values[q]
+		var higherPrice, higherValue = prices[p] > prices[q], values[p] > values[q]
+		switch {
+		case bullish && c.Regular && lowerPrice && higherValue:
+			kind = RegularBullish
+		case bullish && c.Hidden && higherPrice && lowerValue:
+			kind = HiddenBullish
+		case !bullish && c.Regular && higherPrice && lowerValue:
+			kind = RegularBearish
+		case !bullish && c.Hidden && lowerPrice && higherValue:
+			kind = HiddenBearish
+		default:
+			continue
+		}
+
+		var confirm = p + c.PivotRight + c.Confirmation
+		if confirm >= len(values) || !e.holds(p, confirm, prices, bullish) {
+			continue
+		}
+		e.data = append(e.data, Divergence{
+			Kind:         kind,
+			First:        e.point(q, prices),
+			Second:       e.point(p, prices),
+			ConfirmIndex: confirm,
+			ConfirmTime:  time.Unix(e.kline.Candles[confirm].TimeUnix, 0),
+		})
+	}
+}
+
+// isPivot 指标在 index 左侧 PivotLeft 根K线内严格最低（最高），右侧 PivotRight 根K线内不高于（不低于）其他值
+func (e *Detector) isPivot(index int, low bool) bool {
+	var values = e.indicator
+	var left, right = index - e.Config.PivotLeft, index + e.Config.PivotRight
+	if left < 0 || right >= len(values) || math.IsNaN(values[index]) {
+		return false
+	}
+	for j := left; j <= right; j++ {
+		if j == index {
+			continue
+		}
+		var v = values[j]
+		if math.IsNaN(v) {
+			return false
+		}
+		if low && (j < index && v <= values[index] || j > index && v < values[index]) {
+			return false
+		}
+		if !low && (j < index && v >= values[index] || j > index && v > values[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds 确认等待期间指标与价格都没有突破第二个枢轴点
+func (e *Detector) holds(pivot, confirm int, prices []float64, bullish bool) bool {
+	for j := pivot + e.Config.PivotRight + 1; j <= confirm; j++ {
+		if bullish && (e.indicator[j] < e.indicator[pivot] || prices[j] < prices[pivot]) {
+			return false
+		}
+		if !bullish && (e.indicator[j] > e.indicator[pivot] || prices[j] > prices[pivot]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Detector) point(index int, prices []float64) Point {
+	return Point{
+		Index: index,
+		Time:  time.Unix(e.kline.Candles[index].TimeUnix, 0),
+		Price: prices[index],
+		Value: e.indicator[index],
+	}
+}
+
+// GetData 按确认的先后顺序返回全部背离
+func (e *Detector) GetData() []Divergence {
+	if e.data == nil {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// AnalysisSide Func
+// 确认底背离的K线提供买入操作，确认顶背离的K线提供卖出操作，同时确认两种背离时不操作
+func (e *Detector) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+	for i := range sides {
+		sides[i] = utils.Hold
+	}
+
+	var bullish, bearish = make([]bool, len(sides)), make([]bool, len(sides))
+	for _, v := range e.GetData() {
+		if v.Kind.IsBullish() {
+			bullish[v.ConfirmIndex] = true
+		} else {
+			bearish[v.ConfirmIndex] = true
+		}
+	}
+	for i := range sides {
+		if bullish[i] && !bearish[i] {
+			sides[i] = utils.Buy
+		} else if bearish[i] && !bullish[i] {
+			sides[i] = utils.Sell
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
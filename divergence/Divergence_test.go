@@ -0,0 +1,96 @@
+package divergence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./divergence -run TestDetector
+func TestDetector(t *testing.T) {
+	t.Parallel()
+	// 价格在 3 与 11 创出更低的低点，指标在 11 形成更高的低点
+	var prices = []float64{10, 9, 8, 7, 8, 9, 10, 9, 8, 7, 6, 5, 6, 7, 8, 9, 10}
+	var values = []float64{50, 40, 30, 20, 30, 40, 50, 45, 40, 35, 30, 25, 30, 40, 50, 60, 70}
+	var config = DefaultConfig()
+	config.PivotLeft, config.PivotRight = 2, 2
+	config.MinDistance = 3
+
+	var list = utils.GetCloseKlineItem(klines.OneHour, 0.5, prices...)
+	var data = NewDetector(list, values, config).GetData()
+	if len(data) != 1 {
+		t.Fatalf("expected 1 divergence, got %+v", data)
+	}
+	var v = data[0]
+	if v.Kind != RegularBullish || v.First.Index != 3 || v.Second.Index != 11 || v.ConfirmIndex != 13 || v.Second.Price != 4.5 {
+		t.Fatalf("unexpected divergence %+v", v)
+	}
+
+	// 确认等待期间指标跌破第二个低点时不确认
+	config.Confirmation = 2
+	var broken = append([]float64{}, values...)
+	broken[14] = 24
+	if data := NewDetector(list, broken, config).GetData(); len(data) != 0 {
+		t.Fatalf("expected no divergence, got %+v", data)
+	}
+	var detector = NewDetector(list, values, config)
+	if data := detector.GetData(); len(data) != 1 || data[0].ConfirmIndex != 15 {
+		t.Fatalf("unexpected confirmed divergence %+v", data)
+	}
+	var sides = detector.AnalysisSide()
+	for i, side := range sides.Data {
+		if (i == 15) != (side == utils.Buy) || side == utils.Sell {
+			t.Fatalf("[%d] unexpected side %s", i, side)
+		}
+	}
+
+	// 只识别隐藏背离
+	config.Regular = false
+	if data := NewDetector(list, values, config).GetData(); len(data) != 0 {
+		t.Fatalf("expected no hidden divergence, got %+v", data)
+	}
+}
+
+// RUN
+// go test -v ./divergence -run TestDetectorRepaint
+func TestDetectorRepaint(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(500, 4)
+	var rsi = Series(trend.NewRsi(list, 14).GetData(), func(v trend.RsiData) float64 { return v.Value })
+	var config = DefaultConfig()
+	config.Confirmation = 1
+	var full = NewDetector(list, rsi, config).GetData()
+	if len(full) == 0 {
+		t.Fatal("expected divergences on random data")
+	}
+
+	// 只使用前 n 根K线时，已确认的背离与使用全部K线时相同
+	for _, n := range []int{100, 250, 400} {
+		var item = &klines.Item{Interval: list.Interval, Candles: list.Candles[:n]}
+		var part = NewDetector(item, rsi[:n], config).GetData()
+		var expected []Divergence
+		for _, v := range full {
+			if v.ConfirmIndex < n {
+				expected = append(expected, v)
+			}
+		}
+		if len(part) != len(expected) {
+			t.Fatalf("[%d] %d divergences, expected %d", n, len(part), len(expected))
+		}
+		for i := range part {
+			if part[i] != expected[i] {
+				t.Fatalf("[%d] divergence %+v expected %+v", n, part[i], expected[i])
+			}
+		}
+	}
+
+	for _, v := range full {
+		if v.Second.Index-v.First.Index < config.MinDistance || v.Second.Index-v.First.Index > config.MaxDistance || math.IsNaN(v.First.Value) {
+			t.Fatalf("unexpected divergence %+v", v)
+		}
+	}
+}
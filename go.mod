@@ -2,10 +2,12 @@ module github.com/idoall/stockindicator
 
 go 1.21
 
-require github.com/shopspring/decimal v1.3.1
+require (
+	github.com/bytedance/sonic v1.11.9
+	github.com/shopspring/decimal v1.3.1
+)
 
 require (
-	github.com/bytedance/sonic v1.11.9 // indirect
 	github.com/bytedance/sonic/loader v0.1.1 // indirect
 	github.com/cloudwego/base64x v0.1.4 // indirect
 	github.com/cloudwego/iasm v0.2.0 // indirect
@@ -0,0 +1,261 @@
+package levels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// SwingMethod 识别波段的方式
+type SwingMethod int
+
+const (
+	// PivotSwing 使用左右各 PivotLeft、PivotRight 根K线的枢轴高点与低点
+	PivotSwing SwingMethod = iota
+	// ZigZagSwing 价格从极值反向变动超过 AtrMultiple 倍 Atr 时确认极值
+	ZigZagSwing
+)
+
+// FibonacciConfig 斐波那契参数
+type FibonacciConfig struct {
+	Method     SwingMethod
+	PivotLeft  int
+	PivotRight int
+	AtrPeriod  int
+	// ZigZag 反转需要的 Atr 倍数，必须大于 0，小于等于 0 时不计算波段点
+	AtrMultiple float64
+	// 回撤与扩展比例
+	Retracements []float64
+	Extensions   []float64
+	// 同时保留的最近波段数量
+	Swings int
+	// 黄金口袋的回撤比例范围
+	PocketLow  float64
+	PocketHigh float64
+}
+
+// DefaultFibonacciConfig 默认参数：枢轴点左右各 10 根K线，保留最近一个波段，黄金口袋 0.618-0.65
+func DefaultFibonacciConfig() FibonacciConfig {
+	return FibonacciConfig{
+		Method:       PivotSwing,
+		PivotLeft:    10,
+		PivotRight:   10,
+		AtrPeriod:    14,
+		AtrMultiple:  3,
+		Retracements: []float64{0.236, 0.382, 0.5, 0.618, 0.786},
+		Extensions:   []float64{1.272, 1.618, 2, 2.618},
+		Swings:       1,
+		PocketLow:    0.618,
+		PocketHigh:   0.65,
+	}
+}
+
+// SwingPoint 波段的高点或低点
+type SwingPoint struct {
+	Index int
+	Time  time.Time
+	Price float64
+	High  bool
+	// 确认该点的K线
+	ConfirmIndex int
+}
+
+// FibonacciLevel 一个比例对应的价格
+type FibonacciLevel struct {
+	Ratio float64
+	Price float64
+}
+
+// FibonacciSwing 一个波段的回撤与扩展价格
+// 上涨波段（低点到高点）的回撤从高点向下计算，扩展从低点向上超过高点；下跌波段相反。
+type FibonacciSwing struct {
+	Start        SwingPoint
+	End          SwingPoint
+	Up           bool
+	Retracements []FibonacciLevel
+	Extensions   []FibonacciLevel
+	// 黄金口袋的价格范围
+	PocketLow  float64
+	PocketHigh float64
+}
+
+// FibonacciData 每根K线可以看到的最近波段，最新的波段在前
+type FibonacciData struct {
+	Time   time.Time
+	Swings []FibonacciSwing
+}
+
+// Fibonacci 自动斐波那契回撤与扩展
+// 识别最近确认的波段高点与低点，计算每根K线上最近 Swings 个波段的回撤与扩展价格，波段在确认之后才会出现，不会用到未来数据。
+type Fibonacci struct {
+	Name   string
+	Config FibonacciConfig
+	data   []FibonacciData
+	kline  *klines.Item
+}
+
+// NewFibonacci new Func
+func NewFibonacci(klineItem *klines.Item, config FibonacciConfig) *Fibonacci {
+	var name = fmt.Sprintf("Fibonacci%d-%d", config.PivotLeft, config.PivotRight)
+	if config.Method == ZigZagSwing {
+		name = fmt.Sprintf("FibonacciZigZag%d-%v", config.AtrPeriod, config.AtrMultiple)
+	}
+	return &Fibonacci{
+		Name:   name,
+		Config: config,
+		kline:  klineItem,
+	}
+}
+
+// NewDefaultFibonacci new Func
+func NewDefaultFibonacci(klineItem *klines.Item) *Fibonacci {
+	return NewFibonacci(klineItem, DefaultFibonacciConfig())
+}
+
+// Calculation Func
+func (e *Fibonacci) Calculation() *Fibonacci {
+	var pivots = e.pivots()
+	e.data = make([]FibonacciData, len(e.kline.Candles))
+
+	// points 为截止到当前K线已确认、高低交替的波段点，同类型的点连续出现时保留更极端的一个
+	var points []SwingPoint
+	var next int
+	var swings []FibonacciSwing
+	for i, candle := range e.kline.Candles {
+		var changed bool
+		for ; next < len(pivots) && pivots[next].ConfirmIndex <= i; next++ {
+			var p = pivots[next]
+			if n := len(points); n > 0 && points[n-1].High == p.High {
+				if p.High && p.Price > points[n-1].Price || !p.High && p.Price < points[n-1].Price {
+					points[n-1] = p
+					changed = true
+				}
+				continue
+			}
+			points = append(points, p)
+			changed = true
+		}
+		if changed {
+			swings = nil
+			for x := len(points) - 1; x > 0 && len(swings) < max(e.Config.Swings, 1); x-- {
+				swings = append(swings, e.levels(points[x-1], points[x]))
+			}
+		}
+		e.data[i] = FibonacciData{Time: time.Unix(candle.TimeUnix, 0), Swings: swings}
+	}
+	return e
+}
+
+// pivots 按确认顺序返回波段高点与低点
+func (e *Fibonacci) pivots() []SwingPoint {
+	var ohlc = e.kline.GetOHLC()
+	var points []SwingPoint
+	var add = func(index, confirm int, price float64, high bool) {
+		points = append(points, SwingPoint{Index: index, Time: time.Unix(e.kline.Candles[index].TimeUnix, 0), Price: price, High: high, ConfirmIndex: confirm})
+	}
+
+	if e.Config.Method != ZigZagSwing {
+		var highs = ta.PivotHigh(ohlc.High, e.Config.PivotLeft, e.Config.PivotRight)
+		var lows = ta.PivotLow(ohlc.Low, e.Config.PivotLeft, e.Config.PivotRight)
+		for i := range highs {
+			if lows[i] != 0 {
+				add(i-e.Config.PivotRight, i, lows[i], false)
+			}
+			if highs[i] != 0 {
+				add(i-e.Config.PivotRight, i, highs[i], true)
+			}
+		}
+		return points
+	}
+
+	var length = len(e.kline.Candles)
+	if e.Config.AtrPeriod < 1 || e.Config.AtrMultiple <= 0 || length <= e.Config.AtrPeriod {
+		return nil
+	}
+	var atr = ta.Atr(ohlc.High, ohlc.Low, ohlc.Close, e.Config.AtrPeriod)
+	// direction 为 1 时寻找高点，-1 时寻找低点，0 时两者都在寻找
+	var direction int
+	var highIndex, lowIndex int
+	for i := 0; i < length; i++ {
+		if direction >= 0 && ohlc.High[i] > ohlc.High[highIndex] {
+			highIndex = i
+		}
+		if direction <= 0 && ohlc.Low[i] < ohlc.Low[lowIndex] {
+			lowIndex = i
+		}
+		var threshold = atr[i] * e.Config.AtrMultiple
+		if atr[i] <= 0 {
+			continue
+		}
+		if direction >= 0 && ohlc.High[highIndex]-ohlc.Low[i] >= threshold && highIndex < i {
+			add(highIndex, i, ohlc.High[highIndex], true)
+			direction, lowIndex = -1, i
+		} else if direction <= 0 && ohlc.High[i]-ohlc.Low[lowIndex] >= threshold && lowIndex < i {
+			add(lowIndex, i, ohlc.Low[lowIndex], false)
+			direction, highIndex = 1, i
+		}
+	}
+	return points
+}
+
+// levels 计算从 start 到 end 的波段的回撤与扩展价格
+func (e *Fibonacci) levels(start, end SwingPoint) FibonacciSwing {
+	var swing = FibonacciSwing{Start: start, End: end, Up: end.High}
+	var move = end.Price - start.Price
+	for _, ratio := range e.Config.Retracements {
+		swing.Retracements = append(swing.Retracements, FibonacciLevel{Ratio: ratio, Price: end.Price - ratio*move})
+	}
+	for _, ratio := range e.Config.Extensions {
+		swing.Extensions = append(swing.Extensions, FibonacciLevel{Ratio: ratio, Price: start.Price + ratio*move})
+	}
+	var a, b = end.Price - e.Config.PocketLow*move, end.Price - e.Config.PocketHigh*move
+	swing.PocketLow, swing.PocketHigh = min(a, b), max(a, b)
+	return swing
+}
+
+// GetData return Point
+func (e *Fibonacci) GetData() []FibonacciData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// AnalysisSide Func
+// 上涨波段回撤时，最低价第一次进入黄金口袋且收盘价不低于口袋下沿时提供买入操作；
+// 下跌波段反弹时，最高价第一次进入黄金口袋且收盘价不高于口袋上沿时提供卖出操作。同时出现买入与卖出时不操作。
+func (e *Fibonacci) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+
+	var dataList = e.GetData()
+	for i, v := range dataList {
+		sides[i] = utils.Hold
+		if i < 1 {
+			continue
+		}
+		var candle, prev = e.kline.Candles[i], e.kline.Candles[i-1]
+		var buy, sell bool
+		for _, swing := range v.Swings {
+			// 波段在当前K线才确认时，前一根K线的价格不作为第一次进入的判断依据
+			var first = swing.End.ConfirmIndex == i
+			if swing.Up && candle.Low <= swing.PocketHigh && candle.Close >= swing.PocketLow && (first || prev.Low > swing.PocketHigh) {
+				buy = true
+			}
+			if !swing.Up && candle.High >= swing.PocketLow && candle.Close <= swing.PocketHigh && (first || prev.High < swing.PocketLow) {
+				sell = true
+			}
+		}
+		if buy && !sell {
+			sides[i] = utils.Buy
+		} else if sell && !buy {
+			sides[i] = utils.Sell
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
@@ -0,0 +1,95 @@
+package levels
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./levels -run TestFibonacci
+func TestFibonacci(t *testing.T) {
+	t.Parallel()
+	// 低点 99.5 在第 2 根K线，高点 120.5 在第 6 根K线，随后回撤到黄金口袋 106.85-107.52
+	var list = utils.GetCloseKlineItem(klines.OneHour, 0.5, 115, 110, 100, 105, 110, 115, 120, 118, 116, 114, 112, 110, 108, 107, 109, 111)
+	var config = DefaultFibonacciConfig()
+	config.PivotLeft, config.PivotRight = 2, 2
+	var stock = NewFibonacci(list, config)
+	var dataList = stock.GetData()
+
+	if len(dataList[7].Swings) != 0 {
+		t.Fatalf("swing should not be visible before confirmation %+v", dataList[7].Swings)
+	}
+	var swing = dataList[8].Swings[0]
+	if !swing.Up || swing.Start.Price != 99.5 || swing.End.Price != 120.5 || swing.End.ConfirmIndex != 8 {
+		t.Fatalf("unexpected swing %+v", swing)
+	}
+	if v := swing.Retracements[2]; v.Ratio != 0.5 || v.Price != 110 {
+		t.Fatalf("unexpected 0.5 retracement %+v", v)
+	}
+	if v := swing.Extensions[1]; v.Ratio != 1.618 || math.Abs(v.Price-(99.5+1.618*21)) > 1e-9 {
+		t.Fatalf("unexpected 1.618 extension %+v", v)
+	}
+	if math.Abs(swing.PocketLow-106.85) > 1e-9 || math.Abs(swing.PocketHigh-(120.5-0.618*21)) > 1e-9 {
+		t.Fatalf("unexpected golden pocket %f-%f", swing.PocketLow, swing.PocketHigh)
+	}
+
+	// 第 12 根K线第一次进入黄金口袋，第 13 根K线仍在口袋内不再提示
+	var sides = stock.AnalysisSide()
+	for i, side := range sides.Data {
+		if (i == 12) != (side == utils.Buy) || side == utils.Sell {
+			t.Fatalf("[%d] unexpected side %s", i, side)
+		}
+	}
+
+	// 第 13 根K线的低点在第 15 根K线确认，同时保留两个波段
+	config.Swings = 2
+	var swings = NewFibonacci(list, config).GetData()[15].Swings
+	if len(swings) != 2 || swings[0].Up || swings[0].End.Index != 13 || swings[1].End.Index != 6 {
+		t.Fatalf("unexpected swings %+v", swings)
+	}
+}
+
+// RUN
+// go test -v ./levels -run TestFibonacciZigZag
+func TestFibonacciZigZag(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(400, 6)
+	var config = DefaultFibonacciConfig()
+	config.Method = ZigZagSwing
+	config.Swings = 3
+	var full = NewFibonacci(list, config).GetData()
+
+	var last = full[len(full)-1].Swings
+	if len(last) != 3 {
+		t.Fatalf("expected 3 swings, got %d", len(last))
+	}
+	for i, swing := range last {
+		// 波段高低交替，并且首尾相接
+		if swing.Start.High == swing.End.High || i > 0 && last[i-1].Start != swing.End {
+			t.Fatalf("[%d] unexpected swing %+v", i, swing)
+		}
+		if swing.End.ConfirmIndex <= swing.End.Index {
+			t.Fatalf("[%d] swing confirmed at its own bar %+v", i, swing.End)
+		}
+	}
+
+	// 只使用前 n 根K线时，每根K线的波段与使用全部K线时相同
+	for _, n := range []int{100, 250} {
+		var item = &klines.Item{Interval: list.Interval, Candles: list.Candles[:n]}
+		if part := NewFibonacci(item, config).GetData(); !reflect.DeepEqual(part, full[:n]) {
+			t.Fatalf("[%d] swings differ from full data", n)
+		}
+	}
+
+	// AtrMultiple 不大于 0 时不计算波段点
+	config.AtrMultiple = 0
+	for i, v := range NewFibonacci(list, config).GetData() {
+		if len(v.Swings) != 0 {
+			t.Fatalf("[%d] expected no swings with zero AtrMultiple, got %d", i, len(v.Swings))
+		}
+	}
+}
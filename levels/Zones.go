@@ -0,0 +1,377 @@
+package levels
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// Role 区域当前的作用，价格在区域之上时为支撑，之下时为阻力
+type Role int
+
+const (
+	// Support 支撑
+	Support Role = iota
+	// Resistance 阻力
+	Resistance
+)
+
+// String Func
+func (r Role) String() string {
+	if r == Support {
+		return "Support"
+	}
+	return "Resistance"
+}
+
+// EventKind 区域事件
+type EventKind int
+
+const (
+	// Touch 价格进入区域，前一根K线不在区域内
+	Touch EventKind = iota
+	// Rejection 价格进入区域后收盘回到区域原来的一侧：支撑区域收在上沿之上，阻力区域收在下沿之下
+	Rejection
+	// Break 收盘价穿过区域，支撑变为阻力或阻力变为支撑
+	Break
+)
+
+// String Func
+func (k EventKind) String() string {
+	switch k {
+	case Touch:
+		return "Touch"
+	case Rejection:
+		return "Rejection"
+	case Break:
+		return "Break"
+	}
+	return "Unknown"
+}
+
+// ZonesConfig 支撑阻力区域参数
+type ZonesConfig struct {
+	// 枢轴点左右两侧的K线数量，枢轴点在其右侧 PivotRight 根K线之后才确认
+	PivotLeft  int
+	PivotRight int
+	// Atr 周期，同时用于计算平均成交量
+	AtrPeriod int
+	// 区域宽度为 Atr 的倍数，新的枢轴点距离已有区域不超过半个宽度时合并到该区域
+	Width float64
+	// 触及次数的半衰期（K线数量），越早的触及得分越低
+	HalfLife float64
+	// 成交量对得分的影响，0 表示不考虑成交量，1 表示按触及时成交量与平均成交量的比例计分
+	VolumeWeight float64
+	// 最多保留的区域数量，超过时移除得分最低的区域
+	MaxZones int
+	// 超过 MaxAge 根K线没有被触及的区域会被移除，为 0 时不移除
+	MaxAge int
+	// AnalysisSide 是否使用突破与反弹信号
+	Breakout  bool
+	Rejection bool
+}
+
+// DefaultZonesConfig 默认参数
+func DefaultZonesConfig() ZonesConfig {
+	return ZonesConfig{
+		PivotLeft:    5,
+		PivotRight:   5,
+		AtrPeriod:    14,
+		Width:        0.5,
+		HalfLife:     100,
+		VolumeWeight: 0.5,
+		MaxZones:     10,
+		MaxAge:       300,
+		Breakout:     true,
+		Rejection:    true,
+	}
+}
+
+// Zone 一个支撑阻力区域
+type Zone struct {
+	ID int
+	// 区域的上下沿与成交量加权的中心价格
+	Low   float64
+	High  float64
+	Price float64
+	Role  Role
+	// 触及次数与触及时的成交量合计，形成区域的枢轴点算作一次触及
+	Touches int
+	Volume  float64
+	// 被突破（支撑阻力互换）的次数
+	Breaks int
+	// 形成区域与最后一次触及的K线
+	FirstIndex     int
+	LastTouchIndex int
+	// 按触及次数、时间远近与成交量计算的得分
+	Score float64
+}
+
+// Event 一根K线上发生的区域事件
+type Event struct {
+	Kind   EventKind
+	ZoneID int
+	// 事件之后区域的作用，Break 时为互换之后的作用
+	Role Role
+}
+
+// ZonesData 每根K线收盘时仍然有效的区域，按价格从低到高排列
+type ZonesData struct {
+	Time   time.Time
+	Zones  []Zone
+	Events []Event
+}
+
+// Zones 自动识别支撑阻力区域
+// 把确认的枢轴高点、低点按 Atr 宽度聚合为价格区域，统计每个区域的触及次数、成交量与突破，
+// 每根K线只使用当前及之前的数据，不会用到未来数据。
+type Zones struct {
+	Name   string
+	Config ZonesConfig
+	data   []ZonesData
+	kline  *klines.Item
+}
+
+// NewZones new Func
+func NewZones(klineItem *klines.Item, config ZonesConfig) *Zones {
+	return &Zones{
+		Name:   fmt.Sprintf("Zones%d-%d", config.PivotLeft, config.PivotRight),
+		Config: config,
+		kline:  klineItem,
+	}
+}
+
+// NewDefaultZones new Func
+func NewDefaultZones(klineItem *klines.Item) *Zones {
+	return NewZones(klineItem, DefaultZonesConfig())
+}
+
+// zone 计算过程中的区域
+type zone struct {
+	Zone
+	// 枢轴点价格的范围与半个区域宽度
+	pivotLow, pivotHigh, half float64
+	// 成交量加权的枢轴点价格合计
+	weighted, weight float64
+	touches          []touch
+	overlapped       bool
+}
+
+type touch struct {
+	index  int
+	factor float64
+}
+
+// Calculation Func
+func (e *Zones) Calculation() *Zones {
+	var c = e.Config
+	var ohlc = e.kline.GetOHLC()
+	var length = len(e.kline.Candles)
+	e.data = make([]ZonesData, length)
+
+	var atr = make([]float64, length)
+	if c.AtrPeriod > 0 && length > c.AtrPeriod {
+		atr = ta.Atr(ohlc.High, ohlc.Low, ohlc.Close, c.AtrPeriod)
+	}
+	var avgVolume = ta.Sma(max(c.AtrPeriod, 1), ohlc.Volume)
+	var pivotHighs = ta.PivotHigh(ohlc.High, c.PivotLeft, c.PivotRight)
+	var pivotLows = ta.PivotLow(ohlc.Low, c.PivotLeft, c.PivotRight)
+
+	// factor 触及时成交量对得分的影响
+	var factor = func(i int) float64 {
+		if avgVolume[i] <= 0 {
+			return 1
+		}
+		return math.Max(1+c.VolumeWeight*(ohlc.Volume[i]/avgVolume[i]-1), 0)
+	}
+
+	var zones []*zone
+	var nextID int
+	for i := 0; i < length; i++ {
+		var candle = e.kline.Candles[i]
+		var events []Event
+
+		// 更新已有区域的触及与突破
+		for _, z := range zones {
+			var overlapped = candle.Low <= z.High && candle.High >= z.Low
+			switch {
+			case z.Role == Support && candle.Close < z.Low:
+				z.Role = Resistance
+				z.Breaks++
+				events = append(events, Event{Kind: Break, ZoneID: z.ID, Role: z.Role})
+			case z.Role == Resistance && candle.Close > z.High:
+				z.Role = Support
+				z.Breaks++
+				events = append(events, Event{Kind: Break, ZoneID: z.ID, Role: z.Role})
+			case overlapped && !z.overlapped:
+				z.addTouch(i, candle.Volume, factor(i))
+				events = append(events, Event{Kind: Touch, ZoneID: z.ID, Role: z.Role})
+				if z.Role == Support && candle.Close > z.High || z.Role == Resistance && candle.Close < z.Low {
+					events = append(events, Event{Kind: Rejection, ZoneID: z.ID, Role: z.Role})
+				}
+			}
+			z.overlapped = overlapped
+		}
+
+		// 第 i 根K线确认 i-PivotRight 为枢轴点
+		var p = i - c.PivotRight
+		for _, pivot := range []struct {
+			price float64
+			high  bool
+		}{{pivotHighs[i], true}, {pivotLows[i], false}} {
+			if pivot.price == 0 || atr[i] <= 0 {
+				continue
+			}
+			var half = atr[i] * c.Width / 2
+			var volume = e.kline.Candles[p].Volume
+			if z := nearest(zones, pivot.price, half); z != nil {
+				z.pivotLow = math.Min(z.pivotLow, pivot.price)
+				z.pivotHigh = math.Max(z.pivotHigh, pivot.price)
+				z.weighted += pivot.price * math.Max(volume, 1)
+				z.weight += math.Max(volume, 1)
+				z.bounds()
+				continue
+			}
+			var z = &zone{
+				Zone:      Zone{ID: nextID, FirstIndex: p, Role: Support},
+				pivotLow:  pivot.price,
+				pivotHigh: pivot.price,
+				half:      half,
+				weighted:  pivot.price * math.Max(volume, 1),
+				weight:    math.Max(volume, 1),
+			}
+			nextID++
+			z.bounds()
+			if candle.Close < z.Low || pivot.high && candle.Close <= z.High {
+				z.Role = Resistance
+			}
+			z.addTouch(p, volume, factor(p))
+			z.overlapped = candle.Low <= z.High && candle.High >= z.Low
+			zones = append(zones, z)
+		}
+
+		// 计算得分并移除过期与得分最低的区域
+		var active = zones[:0]
+		for _, z := range zones {
+			if c.MaxAge > 0 && i-z.LastTouchIndex > c.MaxAge {
+				continue
+			}
+			z.score(i, c.HalfLife)
+			active = append(active, z)
+		}
+		zones = active
+		if c.MaxZones > 0 && len(zones) > c.MaxZones {
+			sort.SliceStable(zones, func(a, b int) bool { return zones[a].Score > zones[b].Score })
+			zones = zones[:c.MaxZones]
+		}
+		sort.SliceStable(zones, func(a, b int) bool { return zones[a].Price < zones[b].Price })
+
+		var snapshot = make([]Zone, len(zones))
+		for x, z := range zones {
+			snapshot[x] = z.Zone
+		}
+		e.data[i] = ZonesData{Time: time.Unix(candle.TimeUnix, 0), Zones: snapshot, Events: events}
+	}
+	return e
+}
+
+// nearest 返回与 price 距离不超过 half 的最近区域
+func nearest(zones []*zone, price, half float64) *zone {
+	var result *zone
+	var distance = math.Inf(1)
+	for _, z := range zones {
+		if price < z.Low-half || price > z.High+half {
+			continue
+		}
+		if d := math.Abs(price - z.Price); d < distance {
+			result, distance = z, d
+		}
+	}
+	return result
+}
+
+func (z *zone) bounds() {
+	z.Low = z.pivotLow - z.half
+	z.High = z.pivotHigh + z.half
+	z.Price = z.weighted / z.weight
+}
+
+func (z *zone) addTouch(index int, volume, factor float64) {
+	z.Touches++
+	z.Volume += volume
+	z.LastTouchIndex = max(z.LastTouchIndex, index)
+	z.touches = append(z.touches, touch{index: index, factor: factor})
+}
+
+// score 每次触及按半衰期衰减后乘以成交量因子再求和
+func (z *zone) score(index int, halfLife float64) {
+	z.Score = 0
+	for _, t := range z.touches {
+		var decay = 1.0
+		if halfLife > 0 {
+			decay = math.Exp2(-float64(index-t.index) / halfLife)
+		}
+		z.Score += decay * t.factor
+	}
+}
+
+// GetData return Point
+func (e *Zones) GetData() []ZonesData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// Support 收盘价之下最近的支撑区域，没有时返回 nil
+func (d ZonesData) Support() *Zone {
+	for i := len(d.Zones) - 1; i >= 0; i-- {
+		if d.Zones[i].Role == Support {
+			return &d.Zones[i]
+		}
+	}
+	return nil
+}
+
+// Resistance 收盘价之上最近的阻力区域，没有时返回 nil
+func (d ZonesData) Resistance() *Zone {
+	for i := range d.Zones {
+		if d.Zones[i].Role == Resistance {
+			return &d.Zones[i]
+		}
+	}
+	return nil
+}
+
+// AnalysisSide Func
+// Breakout：收盘价向上突破阻力区域时提供买入操作，向下跌破支撑区域时提供卖出操作；
+// Rejection：触及支撑区域后收在上沿之上时提供买入操作，触及阻力区域后收在下沿之下时提供卖出操作。
+// 同一根K线同时出现买入与卖出信号时不操作。
+func (e *Zones) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+
+	for i, v := range e.GetData() {
+		var buy, sell bool
+		for _, event := range v.Events {
+			if event.Kind == Break && e.Config.Breakout || event.Kind == Rejection && e.Config.Rejection {
+				buy = buy || event.Role == Support
+				sell = sell || event.Role == Resistance
+			}
+		}
+		sides[i] = utils.Hold
+		if buy && !sell {
+			sides[i] = utils.Buy
+		} else if sell && !buy {
+			sides[i] = utils.Sell
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
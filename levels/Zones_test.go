@@ -0,0 +1,91 @@
+package levels
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./levels -run TestZones
+func TestZones(t *testing.T) {
+	t.Parallel()
+	// 在 100 与 110 之间震荡三次后向上突破
+	var closes []float64
+	for x := 0; x < 3; x++ {
+		closes = append(closes, 100, 102, 104, 106, 108, 110, 108, 106, 104, 102)
+	}
+	closes = append(closes, 100, 102, 104, 106, 108, 110, 112, 114, 116, 118, 120)
+	var list = utils.GetCloseKlineItem(klines.OneHour, 0.5, closes...)
+
+	var config = DefaultZonesConfig()
+	config.PivotLeft, config.PivotRight = 2, 2
+	config.AtrPeriod = 3
+	var stock = NewZones(list, config)
+	var dataList = stock.GetData()
+
+	// 第三个高点确认后有 110 附近的阻力与 100 附近的支撑
+	var v = dataList[28]
+	if len(v.Zones) != 2 {
+		t.Fatalf("expected 2 zones, got %+v", v.Zones)
+	}
+	var support, resistance = v.Support(), v.Resistance()
+	if support == nil || resistance == nil || support.Low > 99.5 || support.High < 99.5 || resistance.Low > 110.5 || resistance.High < 110.5 {
+		t.Fatalf("unexpected zones %+v", v.Zones)
+	}
+	// 第一根K线左侧没有足够的K线，不是枢轴低点
+	if resistance.Touches != 3 || support.Touches != 2 || resistance.Score <= support.Score {
+		t.Fatalf("unexpected touches %+v", v.Zones)
+	}
+
+	// 收盘价 112 突破阻力，阻力变为支撑
+	var breakout = -1
+	for i, v := range dataList {
+		for _, event := range v.Events {
+			if event.Kind == Break && event.ZoneID == resistance.ID {
+				breakout = i
+			}
+		}
+	}
+	if breakout != 36 || dataList[36].Support().ID != resistance.ID || dataList[36].Support().Breaks != 1 {
+		t.Fatalf("unexpected breakout at %d %+v", breakout, dataList[36])
+	}
+	var sides = stock.AnalysisSide()
+	if sides.Data[36] != utils.Buy {
+		t.Fatalf("expected buy on breakout, got %s", sides.Data[36])
+	}
+	stock.Config.Breakout = false
+	if sides := stock.AnalysisSide(); sides.Data[36] != utils.Hold {
+		t.Fatalf("expected hold without breakout signals, got %s", sides.Data[36])
+	}
+}
+
+// RUN
+// go test -v ./levels -run TestZonesLookahead
+func TestZonesLookahead(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(400, 5)
+	var full = NewDefaultZones(list).GetData()
+
+	var zones int
+	for _, v := range full {
+		zones += len(v.Zones)
+		if len(v.Zones) > DefaultZonesConfig().MaxZones {
+			t.Fatalf("too many zones %d", len(v.Zones))
+		}
+	}
+	if zones == 0 {
+		t.Fatal("expected zones on random data")
+	}
+
+	// 只使用前 n 根K线时，每根K线的区域与使用全部K线时相同
+	for _, n := range []int{50, 200, 350} {
+		var item = &klines.Item{Interval: list.Interval, Candles: list.Candles[:n]}
+		var part = NewDefaultZones(item).GetData()
+		if !reflect.DeepEqual(part, full[:n]) {
+			t.Fatalf("[%d] zones differ from full data", n)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package levels
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+// 注册支撑阻力类指标，参数与 ZonesConfig、FibonacciConfig 一致
+func init() {
+	var d = DefaultZonesConfig()
+	registry.Register(registry.Definition{
+		Name: "Zones", Aliases: []string{"sr"}, Category: registry.Levels, Description: "支撑阻力区域",
+		Params: []registry.Param{
+			registry.IntParam("pivotLeft", d.PivotLeft, 1),
+			registry.IntParam("pivotRight", d.PivotRight, 1),
+			registry.IntParam("atrPeriod", d.AtrPeriod, 1),
+			registry.FloatParam("width", d.Width, 0, 100),
+			registry.IntParam("maxZones", d.MaxZones, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			var config = DefaultZonesConfig()
+			config.PivotLeft = p.Int("pivotLeft")
+			config.PivotRight = p.Int("pivotRight")
+			config.AtrPeriod = p.Int("atrPeriod")
+			config.Width = p.Float("width")
+			config.MaxZones = p.Int("maxZones")
+			return NewZones(item, config)
+		},
+	})
+	var f = DefaultFibonacciConfig()
+	registry.Register(registry.Definition{
+		Name: "Fibonacci", Aliases: []string{"fib"}, Category: registry.Levels, Description: "自动斐波那契回撤",
+		// method: 0 枢轴点 1 ZigZag
+		Params: []registry.Param{
+			registry.IntRangeParam("method", int(f.Method), 0, 1),
+			registry.IntParam("pivot", f.PivotLeft, 1),
+			registry.IntParam("atrPeriod", f.AtrPeriod, 1),
+			registry.FloatParam("atrMultiple", f.AtrMultiple, 0.1, 100),
+			registry.IntParam("swings", f.Swings, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			var config = DefaultFibonacciConfig()
+			config.Method = SwingMethod(p.Int("method"))
+			config.PivotLeft = p.Int("pivot")
+			config.PivotRight = p.Int("pivot")
+			config.AtrPeriod = p.Int("atrPeriod")
+			config.AtrMultiple = p.Float("atrMultiple")
+			config.Swings = p.Int("swings")
+			return NewFibonacci(item, config)
+		},
+	})
+}
@@ -10,11 +10,16 @@ import (
 )
 
 // Camarilla 卡玛里拉轨道
+// 使用上一根高周期K线的最高价、最低价、收盘价计算，与 PivotPoints 的划分方式相同
 type Camarilla struct {
 	Name      string
 	EMAPeriod int
-	data      []*CamarillaData
-	kline     *klines.Item
+	// 高周期，默认 klines.OneDay
+	Interval klines.Interval
+	// 划分高周期的时区，为空时使用 UTC
+	Location *time.Location
+	data     []*CamarillaData
+	kline    *klines.Item
 }
 
 // CamarillaData.
@@ -42,6 +47,7 @@ func NewCamarilla(klineItem *klines.Item, emaPeriod int) *Camarilla {
 		Name:      fmt.Sprintf("Camarilla-%d", emaPeriod),
 		kline:     klineItem,
 		EMAPeriod: emaPeriod,
+		Interval:  klines.OneDay,
 	}
 	return m
 }
@@ -52,27 +58,22 @@ func NewDefaultCamarilla(klineItem *klines.Item) *Camarilla {
 }
 
 // Calculation Func
+// 第一个高周期内的K线没有上一根高周期K线，轨道为 0
 func (e *Camarilla) Calculation() *Camarilla {
 
-	var ohlc = e.kline.GetOHLC()
-	var hlc3 = e.kline.HLC3()
-	var closes = ohlc.Close
-	var highs = ohlc.High
-	var lows = ohlc.Low
-
 	var emas = ta.Ema(e.EMAPeriod, e.kline.GetOHLC().Close)
+	var previous = previousPeriods(e.kline, e.Interval, e.Location)
 
 	var data = make([]*CamarillaData, len(e.kline.Candles))
 
 	for i := 0; i < len(e.kline.Candles); i++ {
-		var high = highs[i]
-		var low = lows[i]
-		var close = closes[i]
-
-		camarillaData := e.getCamarilla(high, low, close)
+		var camarillaData = &CamarillaData{}
+		if bar := previous[i]; bar != nil {
+			camarillaData = e.getCamarilla(bar.high, bar.low, bar.close)
+			camarillaData.Pivot = (bar.high + bar.low + bar.close) / 3
+		}
 		camarillaData.Time = time.Unix(e.kline.Candles[i].TimeUnix, 0)
 		camarillaData.EMA = emas[i]
-		camarillaData.Pivot = hlc3[i]
 
 		data[i] = camarillaData
 	}
@@ -112,36 +113,26 @@ func (e *Camarilla) getCamarilla(high, low, close float64) *CamarillaData {
 }
 
 // AnalysisSide Func
-func (e *Camarilla) AnalysisSide(klineItem *klines.Item) utils.SideData {
+// 收盘价低于 EMA 且由下向上穿越 High4 时提供买入操作，收盘价高于 EMA 且由上向下穿越 High4 时提供卖出操作
+func (e *Camarilla) AnalysisSide() utils.SideData {
 	sides := make([]utils.Side, len(e.kline.Candles))
 
 	if len(e.data) == 0 {
 		e = e.Calculation()
 	}
 
-	// 计算另一个时间维度的K线
-	camarillaNewData := NewCamarilla(klineItem, e.EMAPeriod).GetData()
-
-	var securityCamarillaData *CamarillaData
-
 	var closes = e.kline.GetOHLC().Close
 	var opens = e.kline.GetOHLC().Open
 
 	for i, v := range e.data {
-		for _, camarillaDayDataItem := range camarillaNewData {
-			if camarillaDayDataItem.Time.Year() == v.Time.Year() && camarillaDayDataItem.Time.Month() == v.Time.Month() && camarillaDayDataItem.Time.Day() == v.Time.Day() {
-				securityCamarillaData = camarillaDayDataItem
-				break
-			}
-		}
-
 		var close = closes[i]
 		var open = opens[i]
 
-		// 穿越到零線上方時，會出現看漲金叉。
-		if securityCamarillaData != nil && close < v.EMA && close > securityCamarillaData.High4 && open < securityCamarillaData.High4 {
+		if v.Pivot == 0 {
+			sides[i] = utils.Hold
+		} else if close < v.EMA && close > v.High4 && open < v.High4 {
 			sides[i] = utils.Buy
-		} else if securityCamarillaData != nil && close > v.EMA && close < securityCamarillaData.High4 && open > securityCamarillaData.High4 {
+		} else if close > v.EMA && close < v.High4 && open > v.High4 {
 			sides[i] = utils.Sell
 		} else {
 			sides[i] = utils.Hold
@@ -154,6 +145,13 @@ func (e *Camarilla) AnalysisSide(klineItem *klines.Item) utils.SideData {
 	}
 }
 
+// GetPivotData 以 PivotPoints 的数据结构返回，R1-R4、S1-S4 对应 High1-High4、Low1-Low4
+func (e *Camarilla) GetPivotData() []PivotPointsData {
+	m := NewPivotPoints(e.kline, CamarillaPivot, e.Interval)
+	m.Location = e.Location
+	return m.GetData()
+}
+
 // GetData Func
 func (e *Camarilla) GetData() []*CamarillaData {
 	if len(e.data) == 0 {
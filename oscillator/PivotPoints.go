@@ -0,0 +1,276 @@
+package oscillator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// PivotType 枢轴点的计算方式
+type PivotType int
+
+const (
+	// ClassicPivot 经典（Floor）枢轴点
+	ClassicPivot PivotType = iota
+	// FibonacciPivot 斐波那契枢轴点，只有 R1-R3、S1-S3
+	FibonacciPivot
+	// WoodiePivot 伍迪枢轴点，收盘价的权重加倍
+	WoodiePivot
+	// DeMarkPivot 迪马克枢轴点，只有 R1、S1
+	DeMarkPivot
+	// CentralPivotRange 中枢区间 (CPR)，TC、BC 为区间上下沿，阻力与支撑同经典枢轴点
+	CentralPivotRange
+	// CamarillaPivot 卡玛里拉枢轴点
+	CamarillaPivot
+)
+
+// String Func
+func (t PivotType) String() string {
+	switch t {
+	case ClassicPivot:
+		return "Classic"
+	case FibonacciPivot:
+		return "Fibonacci"
+	case WoodiePivot:
+		return "Woodie"
+	case DeMarkPivot:
+		return "DeMark"
+	case CentralPivotRange:
+		return "CPR"
+	case CamarillaPivot:
+		return "Camarilla"
+	}
+	return "Unknown"
+}
+
+// PivotSignal AnalysisSide 使用的信号类型
+type PivotSignal int
+
+const (
+	// PivotTouch 触及反弹：最低价触及支撑位后收盘价在支撑位之上时买入，最高价触及阻力位后收盘价在阻力位之下时卖出
+	PivotTouch PivotSignal = iota
+	// PivotBreak 突破：收盘价向上突破阻力位时买入，向下跌破支撑位时卖出
+	PivotBreak
+)
+
+// PivotPoints 枢轴点
+// 使用上一根高周期K线（日、周、月）的最高价、最低价、收盘价计算，投影到当前高周期内的每一根K线，
+// 高周期按 Location 时区的自然日、自然周（周一开始）、自然月划分，第一个高周期内的K线没有数据。
+type PivotPoints struct {
+	Name string
+	Type PivotType
+	// 高周期，klines.OneDay、klines.OneWeek、klines.OneMonth，小于一天时按 UTC 时间取整
+	Interval klines.Interval
+	// 划分高周期的时区，为空时使用 UTC
+	Location *time.Location
+	// AnalysisSide 使用的信号类型与第几层支撑阻力，1-4
+	Signal PivotSignal
+	Level  int
+	data   []PivotPointsData
+	kline  *klines.Item
+}
+
+// PivotPointsData 没有的层级为 NaN，TC、BC 只在 CPR 中有值
+type PivotPointsData struct {
+	Time time.Time
+	PP   float64
+	R1   float64
+	R2   float64
+	R3   float64
+	R4   float64
+	S1   float64
+	S2   float64
+	S3   float64
+	S4   float64
+	TC   float64
+	BC   float64
+}
+
+// NewPivotPoints new Func
+func NewPivotPoints(klineItem *klines.Item, pivotType PivotType, interval klines.Interval) *PivotPoints {
+	return &PivotPoints{
+		Name:     fmt.Sprintf("PivotPoints%s-%s", pivotType, interval),
+		Type:     pivotType,
+		Interval: interval,
+		Level:    1,
+		kline:    klineItem,
+	}
+}
+
+// NewDefaultPivotPoints new Func
+func NewDefaultPivotPoints(klineItem *klines.Item) *PivotPoints {
+	return NewPivotPoints(klineItem, ClassicPivot, klines.OneDay)
+}
+
+// Calculation Func
+func (e *PivotPoints) Calculation() *PivotPoints {
+	var previous = previousPeriods(e.kline, e.Interval, e.Location)
+	e.data = make([]PivotPointsData, len(e.kline.Candles))
+	for i, candle := range e.kline.Candles {
+		e.data[i] = pivotLevels(e.Type, previous[i])
+		e.data[i].Time = time.Unix(candle.TimeUnix, 0)
+	}
+	return e
+}
+
+// GetData Func
+func (e *PivotPoints) GetData() []PivotPointsData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// levels 返回第 Level 层阻力与支撑，没有该层时向下取最近的一层
+func (e *PivotPoints) levels(v PivotPointsData) (resistance, support float64) {
+	var r = []float64{v.R1, v.R2, v.R3, v.R4}
+	var s = []float64{v.S1, v.S2, v.S3, v.S4}
+	for i := min(max(e.Level, 1), 4) - 1; i >= 0; i-- {
+		if !math.IsNaN(r[i]) {
+			return r[i], s[i]
+		}
+	}
+	return math.NaN(), math.NaN()
+}
+
+// AnalysisSide Func
+// PivotTouch：最低价触及支撑位且收盘价在支撑位之上时提供买入操作，最高价触及阻力位且收盘价在阻力位之下时提供卖出操作；
+// PivotBreak：收盘价从阻力位之下向上突破时提供买入操作，从支撑位之上向下跌破时提供卖出操作。
+func (e *PivotPoints) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+
+	for i, v := range e.data {
+		sides[i] = utils.Hold
+		var candle = e.kline.Candles[i]
+		var resistance, support = e.levels(v)
+		if math.IsNaN(resistance) {
+			continue
+		}
+		switch e.Signal {
+		case PivotBreak:
+			if i < 1 {
+				continue
+			}
+			var prevClose = e.kline.Candles[i-1].Close
+			if candle.Close > resistance && prevClose <= resistance {
+				sides[i] = utils.Buy
+			} else if candle.Close < support && prevClose >= support {
+				sides[i] = utils.Sell
+			}
+		default:
+			if candle.Low <= support && candle.Close > support {
+				sides[i] = utils.Buy
+			} else if candle.High >= resistance && candle.Close < resistance {
+				sides[i] = utils.Sell
+			}
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
+
+// pivotBar 一根高周期K线
+type pivotBar struct {
+	open, high, low, close float64
+}
+
+// previousPeriods 按高周期划分K线，返回每根K线上一个高周期的开高低收，第一个高周期为 nil
+func previousPeriods(klineItem *klines.Item, interval klines.Interval, loc *time.Location) []*pivotBar {
+	var result = make([]*pivotBar, len(klineItem.Candles))
+	var current, previous *pivotBar
+	var currentStart time.Time
+	for i, candle := range klineItem.Candles {
+		var start = periodStart(time.Unix(candle.TimeUnix, 0), interval, loc)
+		if current == nil || !start.Equal(currentStart) {
+			previous = current
+			current = &pivotBar{open: candle.Open, high: candle.High, low: candle.Low}
+			currentStart = start
+		}
+		current.high = math.Max(current.high, candle.High)
+		current.low = math.Min(current.low, candle.Low)
+		current.close = candle.Close
+		result[i] = previous
+	}
+	return result
+}
+
+// periodStart 返回 t 所在高周期的开始时间
+func periodStart(t time.Time, interval klines.Interval, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if interval < klines.OneDay {
+		return t.Truncate(max(interval.Duration(), time.Second))
+	}
+	var local = t.In(loc)
+	var day = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	switch {
+	case interval >= klines.OneMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	case interval >= klines.OneWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// pivotLevels 使用高周期K线计算枢轴点，bar 为 nil 时全部为 NaN
+func pivotLevels(pivotType PivotType, bar *pivotBar) PivotPointsData {
+	var nan = math.NaN()
+	var p = PivotPointsData{PP: nan, R1: nan, R2: nan, R3: nan, R4: nan, S1: nan, S2: nan, S3: nan, S4: nan, TC: nan, BC: nan}
+	if bar == nil {
+		return p
+	}
+	var high, low, close = bar.high, bar.low, bar.close
+	var r = high - low
+
+	switch pivotType {
+	case FibonacciPivot:
+		p.PP = (high + low + close) / 3
+		p.R1, p.S1 = p.PP+0.382*r, p.PP-0.382*r
+		p.R2, p.S2 = p.PP+0.618*r, p.PP-0.618*r
+		p.R3, p.S3 = p.PP+r, p.PP-r
+	case WoodiePivot:
+		p.PP = (high + low + 2*close) / 4
+		p.R1, p.S1 = 2*p.PP-low, 2*p.PP-high
+		p.R2, p.S2 = p.PP+r, p.PP-r
+		p.R3, p.S3 = high+2*(p.PP-low), low-2*(high-p.PP)
+		p.R4, p.S4 = p.R3+r, p.S3-r
+	case DeMarkPivot:
+		var x = high + low + 2*close
+		if close < bar.open {
+			x = high + 2*low + close
+		} else if close > bar.open {
+			x = 2*high + low + close
+		}
+		p.PP = x / 4
+		p.R1, p.S1 = x/2-low, x/2-high
+	case CamarillaPivot:
+		p.PP = (high + low + close) / 3
+		p.R1, p.S1 = close+r*1.1/12, close-r*1.1/12
+		p.R2, p.S2 = close+r*1.1/6, close-r*1.1/6
+		p.R3, p.S3 = close+r*1.1/4, close-r*1.1/4
+		p.R4, p.S4 = close+r*1.1/2, close-r*1.1/2
+	default:
+		// ClassicPivot 与 CentralPivotRange
+		p.PP = (high + low + close) / 3
+		p.R1, p.S1 = 2*p.PP-low, 2*p.PP-high
+		p.R2, p.S2 = p.PP+r, p.PP-r
+		p.R3, p.S3 = p.PP+2*r, p.PP-2*r
+		p.R4, p.S4 = p.PP+3*r, p.PP-3*r
+		if pivotType == CentralPivotRange {
+			var bc = (high + low) / 2
+			var tc = 2*p.PP - bc
+			p.TC, p.BC = math.Max(tc, bc), math.Min(tc, bc)
+		}
+	}
+	return p
+}
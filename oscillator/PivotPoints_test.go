@@ -0,0 +1,118 @@
+package oscillator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./oscillator -run TestPivotPoints
+func TestPivotPoints(t *testing.T) {
+	t.Parallel()
+	// 从 2023-11-14 22:00 UTC 开始的 30 分钟K线，前 4 根为第一天
+	var list = utils.GetRandomKlineItem(100, 1)
+	var high, low = math.Inf(-1), math.Inf(1)
+	for _, c := range list.Candles[:4] {
+		high, low = math.Max(high, c.High), math.Min(low, c.Low)
+	}
+	var close = list.Candles[3].Close
+	var pp = (high + low + close) / 3
+
+	var dataList = NewDefaultPivotPoints(list).GetData()
+	for i := 0; i < 4; i++ {
+		if !math.IsNaN(dataList[i].PP) {
+			t.Fatalf("[%d] expected NaN in the first period, got %+v", i, dataList[i])
+		}
+	}
+	for i := 4; i < 52; i++ {
+		var v = dataList[i]
+		if v.PP != pp || v.R1 != 2*pp-low || v.S1 != 2*pp-high || v.R4 != pp+3*(high-low) || !math.IsNaN(v.TC) {
+			t.Fatalf("[%d] unexpected classic pivot %+v", i, v)
+		}
+	}
+	if dataList[52].PP == pp {
+		t.Fatal("pivot did not change on the next day")
+	}
+
+	for _, pivotType := range []PivotType{FibonacciPivot, WoodiePivot, DeMarkPivot, CentralPivotRange, CamarillaPivot} {
+		var v = NewPivotPoints(list, pivotType, klines.OneDay).GetData()[10]
+		if !(v.S1 < v.PP && v.PP < v.R1) && pivotType != CamarillaPivot || v.S1 >= v.R1 {
+			t.Fatalf("%s unexpected levels %+v", pivotType, v)
+		}
+		switch pivotType {
+		case FibonacciPivot:
+			if math.Abs(v.R3-v.PP-(high-low)) > 1e-9 || !math.IsNaN(v.R4) {
+				t.Fatalf("unexpected fibonacci pivot %+v", v)
+			}
+		case DeMarkPivot:
+			if !math.IsNaN(v.R2) {
+				t.Fatalf("unexpected demark pivot %+v", v)
+			}
+		case CentralPivotRange:
+			if v.BC > v.PP || v.TC < v.PP || v.BC != math.Min((high+low)/2, 2*pp-(high+low)/2) {
+				t.Fatalf("unexpected central pivot range %+v", v)
+			}
+		}
+	}
+
+	// 同一周内没有上一周的数据
+	for i, v := range NewPivotPoints(list, ClassicPivot, klines.OneWeek).GetData() {
+		if !math.IsNaN(v.PP) {
+			t.Fatalf("[%d] expected NaN weekly pivot, got %+v", i, v)
+		}
+	}
+}
+
+// RUN
+// go test -v ./oscillator -run TestPivotPointsAnalysisSide
+func TestPivotPointsAnalysisSide(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(300, 2)
+	var stock = NewDefaultPivotPoints(list)
+	var dataList = stock.GetData()
+
+	for _, signal := range []PivotSignal{PivotTouch, PivotBreak} {
+		stock.Signal = signal
+		var sides = stock.AnalysisSide()
+		if len(sides.Data) != len(list.Candles) {
+			t.Fatalf("expected %d sides, got %d", len(list.Candles), len(sides.Data))
+		}
+		for i, side := range sides.Data {
+			var c = list.Candles[i]
+			switch {
+			case side == utils.Buy && signal == PivotTouch && !(c.Low <= dataList[i].S1 && c.Close > dataList[i].S1):
+				t.Fatalf("[%d] unexpected touch buy", i)
+			case side == utils.Buy && signal == PivotBreak && !(c.Close > dataList[i].R1 && list.Candles[i-1].Close <= dataList[i].R1):
+				t.Fatalf("[%d] unexpected break buy", i)
+			}
+		}
+	}
+}
+
+// RUN
+// go test -v ./oscillator -run TestCamarilla
+func TestCamarilla(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(100, 1)
+	var stock = NewDefaultCamarilla(list)
+	var dataList = stock.GetData()
+	var pivots = stock.GetPivotData()
+
+	for i, v := range dataList {
+		if i < 4 {
+			if v.High4 != 0 || !math.IsNaN(pivots[i].R4) {
+				t.Fatalf("[%d] expected no levels in the first period", i)
+			}
+			continue
+		}
+		if math.Abs(v.High4-pivots[i].R4) > 1e-9 || math.Abs(v.Low3-pivots[i].S3) > 1e-9 || v.Pivot != pivots[i].PP {
+			t.Fatalf("[%d] camarilla %+v pivot %+v", i, v, pivots[i])
+		}
+	}
+	if sides := stock.AnalysisSide(); len(sides.Data) != len(list.Candles) {
+		t.Fatalf("expected %d sides, got %d", len(list.Candles), len(sides.Data))
+	}
+}
@@ -0,0 +1,103 @@
+package oscillator
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+// 注册震荡类指标，参数顺序与 NewXxx 一致
+func init() {
+	registry.Register(registry.Definition{
+		Name: "AbsolutePriceOscillator", Aliases: []string{"apo"}, Category: registry.Oscillator, Description: "绝对价格振荡器",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAbsolutePriceOscillator(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "AwesomeOscillator", Aliases: []string{"ao"}, Category: registry.Oscillator, Description: "动量震荡指标",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAwesomeOscillator(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Camarilla", Category: registry.Oscillator, Description: "卡玛里拉轨道",
+		Params: []registry.Param{registry.IntParam("emaPeriod", 8, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewCamarilla(item, p.Int("emaPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "ChaikinOscillator", Aliases: []string{"chaikin"}, Category: registry.Oscillator, Description: "蔡金振荡器",
+		Params: []registry.Param{registry.IntParam("fastPeriod", 3, 1), registry.IntParam("slowPeriod", 10, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewChaikinOscillator(item, p.Int("fastPeriod"), p.Int("slowPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "IchimokuCloud", Aliases: []string{"ichimoku"}, Category: registry.Oscillator, Description: "一目均衡表",
+		Params: []registry.Param{
+			registry.IntParam("conversionPeriod", 20, 1),
+			registry.IntParam("leadingSpanBPeriod", 60, 1),
+			registry.IntParam("laggingLinePeriod", 120, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewIchimokuCloud(item, p.Int("conversionPeriod"), p.Int("leadingSpanBPeriod"), p.Int("laggingLinePeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "PercentagePriceOscillator", Aliases: []string{"ppo"}, Category: registry.Oscillator, Description: "价格震荡百分比指标",
+		Params: []registry.Param{
+			registry.IntParam("fastPeriod", 12, 1),
+			registry.IntParam("slowPeriod", 26, 1),
+			registry.IntParam("signalPeriod", 9, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewPercentagePriceOscillator(item, p.Int("fastPeriod"), p.Int("slowPeriod"), p.Int("signalPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "PivotPoints", Aliases: []string{"pivot"}, Category: registry.Oscillator, Description: "枢轴点",
+		// type: 0 经典 1 斐波那契 2 伍迪 3 迪马克 4 CPR 5 卡玛里拉；timeframe: 0 日 1 周 2 月；signal: 0 触及 1 突破
+		Params: []registry.Param{
+			registry.IntRangeParam("type", 0, 0, 5),
+			registry.IntRangeParam("timeframe", 0, 0, 2),
+			registry.IntRangeParam("signal", 0, 0, 1),
+			registry.IntRangeParam("level", 1, 1, 4),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			var interval = []klines.Interval{klines.OneDay, klines.OneWeek, klines.OneMonth}[p.Int("timeframe")]
+			m := NewPivotPoints(item, PivotType(p.Int("type")), interval)
+			m.Signal = PivotSignal(p.Int("signal"))
+			m.Level = p.Int("level")
+			return m
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "ProjectionOscillator", Category: registry.Oscillator, Description: "投影振荡器",
+		Params: []registry.Param{registry.IntParam("period", 13, 1), registry.IntParam("smooth", 3, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewProjectionOscillator(item, p.Int("period"), p.Int("smooth"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "StochasticOscillator", Aliases: []string{"stoch"}, Category: registry.Oscillator, Description: "随机振荡器",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewStochasticOscillator(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "VolumeOscillator", Category: registry.Oscillator, Description: "成交量振荡器",
+		Params: []registry.Param{registry.IntParam("shortLength", 5, 1), registry.IntParam("longLength", 10, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewVolumeOscillator(item, p.Int("shortLength"), p.Int("longLength"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "WilliamsR", Category: registry.Oscillator, Description: "威廉指标",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewWilliamsR(item, p.Int("period"))
+		},
+	})
+}
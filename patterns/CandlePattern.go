@@ -0,0 +1,290 @@
+package patterns
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// Thresholds K线形态的判断阈值，比例均相对于K线的振幅（最高价 - 最低价）
+type Thresholds struct {
+	// 实体不超过振幅的比例时为十字星，默认 0.1
+	DojiBody float64
+	// 实体不超过振幅的比例时为小实体，用于锤子线、纺锤线、星线，默认 0.3
+	SmallBody float64
+	// 实体不小于振幅的比例时为长实体，用于吞没、孕线、刺透、三兵，默认 0.6
+	LongBody float64
+	// 实体不小于振幅的比例时为光头光脚，默认 0.95
+	MarubozuBody float64
+	// 长影线至少是实体的倍数，用于锤子线、流星线，默认 2
+	LongShadow float64
+	// 短影线不超过振幅的比例，默认 0.1
+	ShortShadow float64
+	// 镊子顶底两根K线最高价或最低价的差值不超过振幅的比例，默认 0.05
+	Tolerance float64
+	// 趋势过滤的周期，形态开始前一根K线的收盘价低于前 TrendPeriod 根收盘价的均值为下降趋势，高于为上升趋势。
+	// 反转形态只在对应的趋势中出现，为 0 时不过滤，锤子线与上吊线等形状相同的形态会同时出现
+	TrendPeriod int
+}
+
+// DefaultThresholds 默认阈值
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		DojiBody:     0.1,
+		SmallBody:    0.3,
+		LongBody:     0.6,
+		MarubozuBody: 0.95,
+		LongShadow:   2,
+		ShortShadow:  0.1,
+		Tolerance:    0.05,
+		TrendPeriod:  10,
+	}
+}
+
+// CandlePattern K线形态识别
+type CandlePattern struct {
+	Name       string
+	Thresholds Thresholds
+	// AnalysisSide 使用的形态，默认为全部看涨与看跌形态
+	Signals Pattern
+	data    []CandlePatternData
+	kline   *klines.Item
+}
+
+// CandlePatternData 每根K线上完成的形态，多根K线的形态记录在最后一根K线上
+type CandlePatternData struct {
+	Time    time.Time
+	Pattern Pattern
+}
+
+// NewCandlePattern new Func
+func NewCandlePattern(klineItem *klines.Item, thresholds Thresholds) *CandlePattern {
+	return &CandlePattern{
+		Name:       fmt.Sprintf("CandlePattern%d", thresholds.TrendPeriod),
+		Thresholds: thresholds,
+		Signals:    Bullish | Bearish,
+		kline:      klineItem,
+	}
+}
+
+// NewDefaultCandlePattern new Func
+func NewDefaultCandlePattern(klineItem *klines.Item) *CandlePattern {
+	return NewCandlePattern(klineItem, DefaultThresholds())
+}
+
+// candle K线的实体与影线
+type candle struct {
+	open, high, low, close     float64
+	body, upper, lower, length float64
+}
+
+func newCandle(v *klines.Candle) candle {
+	return candle{
+		open:   v.Open,
+		high:   v.High,
+		low:    v.Low,
+		close:  v.Close,
+		body:   math.Abs(v.Close - v.Open),
+		upper:  v.High - math.Max(v.Open, v.Close),
+		lower:  math.Min(v.Open, v.Close) - v.Low,
+		length: v.High - v.Low,
+	}
+}
+
+func (c candle) bull() bool { return c.close > c.open }
+func (c candle) bear() bool { return c.close < c.open }
+func (c candle) top() float64 {
+	return math.Max(c.open, c.close)
+}
+func (c candle) bottom() float64 {
+	return math.Min(c.open, c.close)
+}
+func (c candle) mid() float64 {
+	return (c.open + c.close) / 2
+}
+
+// Calculation Func
+func (e *CandlePattern) Calculation() *CandlePattern {
+	var t = e.Thresholds
+	var candles = make([]candle, len(e.kline.Candles))
+	for i, v := range e.kline.Candles {
+		candles[i] = newCandle(v)
+	}
+
+	// trend 返回形态开始前一根K线的趋势，1 上升，-1 下降，0 无法判断或不过滤
+	var trend = func(start int) int {
+		var end = start - 1
+		if t.TrendPeriod <= 0 || end-t.TrendPeriod < 0 {
+			return 0
+		}
+		var sum float64
+		for i := end - t.TrendPeriod; i < end; i++ {
+			sum += candles[i].close
+		}
+		var avg = sum / float64(t.TrendPeriod)
+		switch {
+		case candles[end].close > avg:
+			return 1
+		case candles[end].close < avg:
+			return -1
+		}
+		return 0
+	}
+	// down 与 up 判断是否满足反转形态需要的趋势，不过滤时总是满足
+	var down = func(start int) bool { return t.TrendPeriod <= 0 || trend(start) < 0 }
+	var up = func(start int) bool { return t.TrendPeriod <= 0 || trend(start) > 0 }
+
+	var long = func(c candle) bool { return c.length > 0 && c.body >= t.LongBody*c.length }
+	var small = func(c candle) bool { return c.length > 0 && c.body <= t.SmallBody*c.length }
+
+	e.data = make([]CandlePatternData, len(candles))
+	for i, c := range candles {
+		var p Pattern
+
+		// 单根K线
+		if c.length > 0 {
+			var doji = c.body <= t.DojiBody*c.length
+			if doji {
+				p |= Doji
+				switch {
+				case c.upper <= t.ShortShadow*c.length && c.lower >= t.SmallBody*c.length:
+					p |= DragonflyDoji
+				case c.lower <= t.ShortShadow*c.length && c.upper >= t.SmallBody*c.length:
+					p |= GravestoneDoji
+				case c.upper >= t.SmallBody*c.length && c.lower >= t.SmallBody*c.length:
+					p |= LongLeggedDoji
+				}
+			} else if small(c) {
+				if c.lower >= t.LongShadow*c.body && c.upper <= t.ShortShadow*c.length {
+					if down(i) {
+						p |= Hammer
+					}
+					if up(i) {
+						p |= HangingMan
+					}
+				} else if c.upper >= t.LongShadow*c.body && c.lower <= t.ShortShadow*c.length {
+					if down(i) {
+						p |= InvertedHammer
+					}
+					if up(i) {
+						p |= ShootingStar
+					}
+				} else if c.upper > c.body && c.lower > c.body {
+					p |= SpinningTop
+				}
+			}
+			if c.body >= t.MarubozuBody*c.length {
+				if c.bull() {
+					p |= BullishMarubozu
+				} else if c.bear() {
+					p |= BearishMarubozu
+				}
+			}
+		}
+
+		// 两根K线
+		if i >= 1 {
+			var a = candles[i-1]
+			switch {
+			case a.bear() && c.bull() && c.open <= a.close && c.close >= a.open && c.body > a.body && down(i-1):
+				p |= BullishEngulfing
+			case a.bull() && c.bear() && c.open >= a.close && c.close <= a.open && c.body > a.body && up(i-1):
+				p |= BearishEngulfing
+			}
+			switch {
+			case a.bear() && long(a) && c.top() <= a.open && c.bottom() >= a.close && c.body < a.body && down(i-1):
+				p |= BullishHarami
+			case a.bull() && long(a) && c.top() <= a.close && c.bottom() >= a.open && c.body < a.body && up(i-1):
+				p |= BearishHarami
+			}
+			switch {
+			case a.bear() && long(a) && c.bull() && c.open < a.close && c.close > a.mid() && c.close < a.open && down(i-1):
+				p |= PiercingLine
+			case a.bull() && long(a) && c.bear() && c.open > a.close && c.close < a.mid() && c.close > a.open && up(i-1):
+				p |= DarkCloudCover
+			}
+			var tolerance = t.Tolerance * math.Max(a.length, c.length)
+			switch {
+			case a.bear() && c.bull() && math.Abs(a.low-c.low) <= tolerance && down(i-1):
+				p |= TweezerBottom
+			case a.bull() && c.bear() && math.Abs(a.high-c.high) <= tolerance && up(i-1):
+				p |= TweezerTop
+			}
+		}
+
+		// 三根K线
+		if i >= 2 {
+			var a, b = candles[i-2], candles[i-1]
+			switch {
+			case a.bear() && long(a) && small(b) && b.top() <= a.close && c.bull() && c.close > a.mid() && down(i-2):
+				p |= MorningStar
+			case a.bull() && long(a) && small(b) && b.bottom() >= a.close && c.bear() && c.close < a.mid() && up(i-2):
+				p |= EveningStar
+			}
+			var soldier = func(prev, cur candle) bool {
+				return cur.bull() && long(cur) && cur.close > prev.close &&
+					cur.open >= prev.open && cur.open <= prev.close && cur.upper <= t.SmallBody*cur.length
+			}
+			var crow = func(prev, cur candle) bool {
+				return cur.bear() && long(cur) && cur.close < prev.close &&
+					cur.open <= prev.open && cur.open >= prev.close && cur.lower <= t.SmallBody*cur.length
+			}
+			switch {
+			case a.bull() && long(a) && soldier(a, b) && soldier(b, c):
+				p |= ThreeWhiteSoldiers
+			case a.bear() && long(a) && crow(a, b) && crow(b, c):
+				p |= ThreeBlackCrows
+			}
+			switch {
+			case e.data[i-1].Pattern.Has(BullishHarami) && c.bull() && c.close > a.open:
+				p |= ThreeInsideUp
+			case e.data[i-1].Pattern.Has(BearishHarami) && c.bear() && c.close < a.open:
+				p |= ThreeInsideDown
+			}
+		}
+
+		e.data[i] = CandlePatternData{
+			Time:    time.Unix(e.kline.Candles[i].TimeUnix, 0),
+			Pattern: p,
+		}
+	}
+	return e
+}
+
+// AnalysisSide Func
+// 出现 Signals 中的看涨形态为买入信号，看跌形态为卖出信号，同时出现或都没有出现时为 Hold
+func (e *CandlePattern) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+
+	for i, v := range e.data {
+		var p = v.Pattern & e.Signals
+		var bullish, bearish = p&Bullish != 0, p&Bearish != 0
+		switch {
+		case bullish && !bearish:
+			sides[i] = utils.Buy
+		case bearish && !bullish:
+			sides[i] = utils.Sell
+		default:
+			sides[i] = utils.Hold
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
+
+// GetData Func
+func (e *CandlePattern) GetData() []CandlePatternData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
@@ -0,0 +1,132 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// newPatternTestItem 使用开高低收创建每小时一根的K线
+func newPatternTestItem(ohlc ...[4]float64) *klines.Item {
+	var item = &klines.Item{Interval: klines.OneHour}
+	for i, v := range ohlc {
+		item.Candles = append(item.Candles, &klines.Candle{
+			TimeUnix: 1699999200 + int64(i)*3600,
+			Open:     v[0],
+			High:     v[1],
+			Low:      v[2],
+			Close:    v[3],
+		})
+	}
+	return item
+}
+
+// downtrend 逐根下跌的K线
+func downtrend(count int, from float64) [][4]float64 {
+	var result [][4]float64
+	for i := 0; i < count; i++ {
+		var open = from - float64(i)
+		result = append(result, [4]float64{open, open + 0.2, open - 1.2, open - 1})
+	}
+	return result
+}
+
+// RUN
+// go test -v ./patterns -run TestCandlePattern
+func TestCandlePattern(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		candles  [][4]float64
+		expected Pattern
+	}{
+		{"Hammer", [][4]float64{{90, 90.1, 87, 89.5}}, Hammer},
+		{"DragonflyDoji", [][4]float64{{90, 90.05, 87, 90}}, Doji | DragonflyDoji},
+		{"BullishEngulfing", [][4]float64{{90, 90.2, 88.8, 89}, {88.8, 91, 88.7, 90.5}}, BullishEngulfing},
+		{"PiercingLine", [][4]float64{{90, 90.1, 87.9, 88}, {87.8, 89.6, 87.7, 89.5}}, PiercingLine},
+		{"MorningStar", [][4]float64{{92, 92.1, 87.9, 88}, {87.7, 88, 87.3, 87.6}, {87.8, 91.5, 87.7, 91.2}}, MorningStar},
+		{"BullishHarami", [][4]float64{{92, 92.1, 87.9, 88}, {89, 90.5, 88.8, 90}}, BullishHarami},
+		{"ThreeInsideUp", [][4]float64{{92, 92.1, 87.9, 88}, {89, 90.5, 88.8, 90}, {90, 93, 89.9, 92.8}}, ThreeInsideUp},
+	}
+	for _, test := range tests {
+		var item = newPatternTestItem(append(downtrend(12, 102), test.candles...)...)
+		var data = NewDefaultCandlePattern(item).GetData()
+		var last = data[len(data)-1].Pattern
+		if !last.Has(test.expected) || last&test.expected != test.expected {
+			t.Errorf("%s: received %s expected %s", test.name, last, test.expected)
+		}
+		if last.Has(Bearish) {
+			t.Errorf("%s: unexpected bearish pattern %s in downtrend", test.name, last)
+		}
+	}
+
+	// 上升趋势中同样形状的锤子线为上吊线
+	var rising [][4]float64
+	for i := 0; i < 12; i++ {
+		var open = 80 + float64(i)
+		rising = append(rising, [4]float64{open, open + 1.2, open - 0.2, open + 1})
+	}
+	var item = newPatternTestItem(append(rising, [4]float64{92, 92.1, 89, 91.5})...)
+	if last := NewDefaultCandlePattern(item).GetData()[12].Pattern; !last.Has(HangingMan) || last.Has(Hammer) {
+		t.Fatalf("received %s expected %s", last, HangingMan)
+	}
+
+	// 不过滤趋势时形状相同的形态同时出现
+	var thresholds = DefaultThresholds()
+	thresholds.TrendPeriod = 0
+	item = newPatternTestItem([4]float64{92, 92.1, 89, 91.5})
+	if last := NewCandlePattern(item, thresholds).GetData()[0].Pattern; last&(Hammer|HangingMan) != Hammer|HangingMan {
+		t.Fatalf("received %s expected %s", last, Hammer|HangingMan)
+	}
+
+	var soldiers = newPatternTestItem([4]float64{10, 11.1, 9.9, 11}, [4]float64{10.5, 12.1, 10.4, 12}, [4]float64{11.5, 13.1, 11.4, 13})
+	if last := NewDefaultCandlePattern(soldiers).GetData()[2].Pattern; !last.Has(ThreeWhiteSoldiers) {
+		t.Fatalf("received %s expected %s", last, ThreeWhiteSoldiers)
+	}
+}
+
+// RUN
+// go test -v ./patterns -run TestCandlePatternAnalysisSide
+func TestCandlePatternAnalysisSide(t *testing.T) {
+	t.Parallel()
+	var item = newPatternTestItem(append(downtrend(12, 102), [4]float64{90, 90.2, 88.8, 89}, [4]float64{88.8, 91, 88.7, 90.5})...)
+	var stock = NewDefaultCandlePattern(item)
+	var sides = stock.AnalysisSide()
+	if len(sides.Data) != len(item.Candles) || sides.Name != stock.Name {
+		t.Fatalf("unexpected sides %+v", sides)
+	}
+	if sides.Data[len(sides.Data)-1] != utils.Buy {
+		t.Fatalf("received %s expected %s", sides.Data[len(sides.Data)-1], utils.Buy)
+	}
+
+	stock = NewDefaultCandlePattern(item)
+	stock.Signals = Bearish
+	if side := stock.AnalysisSide().Data[len(item.Candles)-1]; side != utils.Hold {
+		t.Fatalf("received %s expected %s", side, utils.Hold)
+	}
+
+	// 随机数据上的信号只有 Buy、Sell、Hold
+	var list = utils.GetRandomKlineItem(500, 1)
+	for _, v := range NewDefaultCandlePattern(list).AnalysisSide().Data {
+		if v != utils.Buy && v != utils.Sell && v != utils.Hold {
+			t.Fatalf("unexpected side %d", v)
+		}
+	}
+}
+
+// RUN
+// go test -v ./patterns -run TestPatternString
+func TestPatternString(t *testing.T) {
+	t.Parallel()
+	var p = Doji | DragonflyDoji | ThreeInsideDown
+	if p.String() != "Doji|DragonflyDoji|ThreeInsideDown" {
+		t.Fatalf("unexpected %s", p)
+	}
+	if list := p.List(); len(list) != 3 || list[2] != ThreeInsideDown {
+		t.Fatalf("unexpected %v", list)
+	}
+	if Bullish&Bearish != 0 {
+		t.Fatal("bullish and bearish patterns overlap")
+	}
+}
@@ -0,0 +1,104 @@
+package patterns
+
+import "strings"
+
+// Pattern K线形态，多个形态可以同时出现，按位组合
+type Pattern uint64
+
+const (
+	// Doji 十字星
+	Doji Pattern = 1 << iota
+	// LongLeggedDoji 长腿十字星
+	LongLeggedDoji
+	// DragonflyDoji T 字线（蜻蜓十字星）
+	DragonflyDoji
+	// GravestoneDoji 倒 T 字线（墓碑十字星）
+	GravestoneDoji
+	// SpinningTop 纺锤线
+	SpinningTop
+	// Hammer 锤子线，下降趋势中出现
+	Hammer
+	// HangingMan 上吊线，上升趋势中出现
+	HangingMan
+	// InvertedHammer 倒锤子线，下降趋势中出现
+	InvertedHammer
+	// ShootingStar 流星线，上升趋势中出现
+	ShootingStar
+	// BullishMarubozu 光头光脚阳线
+	BullishMarubozu
+	// BearishMarubozu 光头光脚阴线
+	BearishMarubozu
+	// BullishEngulfing 看涨吞没
+	BullishEngulfing
+	// BearishEngulfing 看跌吞没
+	BearishEngulfing
+	// BullishHarami 看涨孕线
+	BullishHarami
+	// BearishHarami 看跌孕线
+	BearishHarami
+	// PiercingLine 刺透形态
+	PiercingLine
+	// DarkCloudCover 乌云盖顶
+	DarkCloudCover
+	// TweezerBottom 镊子底
+	TweezerBottom
+	// TweezerTop 镊子顶
+	TweezerTop
+	// MorningStar 启明星
+	MorningStar
+	// EveningStar 黄昏星
+	EveningStar
+	// ThreeWhiteSoldiers 红三兵
+	ThreeWhiteSoldiers
+	// ThreeBlackCrows 三只乌鸦
+	ThreeBlackCrows
+	// ThreeInsideUp 三内部上涨
+	ThreeInsideUp
+	// ThreeInsideDown 三内部下跌
+	ThreeInsideDown
+)
+
+const (
+	// Bullish 全部看涨形态
+	Bullish = DragonflyDoji | Hammer | InvertedHammer | BullishMarubozu | BullishEngulfing | BullishHarami |
+		PiercingLine | TweezerBottom | MorningStar | ThreeWhiteSoldiers | ThreeInsideUp
+	// Bearish 全部看跌形态
+	Bearish = GravestoneDoji | HangingMan | ShootingStar | BearishMarubozu | BearishEngulfing | BearishHarami |
+		DarkCloudCover | TweezerTop | EveningStar | ThreeBlackCrows | ThreeInsideDown
+)
+
+var patternNames = []string{
+	"Doji", "LongLeggedDoji", "DragonflyDoji", "GravestoneDoji", "SpinningTop",
+	"Hammer", "HangingMan", "InvertedHammer", "ShootingStar",
+	"BullishMarubozu", "BearishMarubozu", "BullishEngulfing", "BearishEngulfing",
+	"BullishHarami", "BearishHarami", "PiercingLine", "DarkCloudCover",
+	"TweezerBottom", "TweezerTop", "MorningStar", "EveningStar",
+	"ThreeWhiteSoldiers", "ThreeBlackCrows", "ThreeInsideUp", "ThreeInsideDown",
+}
+
+// Has 是否包含 flag 中的任一形态
+func (p Pattern) Has(flag Pattern) bool {
+	return p&flag != 0
+}
+
+// List 拆分为单个形态
+func (p Pattern) List() []Pattern {
+	var result []Pattern
+	for i := range patternNames {
+		if flag := Pattern(1) << i; p&flag != 0 {
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+// String implements the stringer interface, 多个形态用 | 连接
+func (p Pattern) String() string {
+	var names []string
+	for i, name := range patternNames {
+		if p&(Pattern(1)<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
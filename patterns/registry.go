@@ -0,0 +1,36 @@
+package patterns
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+// 注册K线形态，阈值参数与 Thresholds 一致
+func init() {
+	var d = DefaultThresholds()
+	registry.Register(registry.Definition{
+		Name: "CandlePattern", Aliases: []string{"candle", "pattern"}, Category: registry.Pattern, Description: "K线形态识别",
+		Params: []registry.Param{
+			registry.IntParam("trendPeriod", d.TrendPeriod, 0),
+			registry.FloatParam("dojiBody", d.DojiBody, 0, 1),
+			registry.FloatParam("smallBody", d.SmallBody, 0, 1),
+			registry.FloatParam("longBody", d.LongBody, 0, 1),
+			registry.FloatParam("marubozuBody", d.MarubozuBody, 0, 1),
+			registry.FloatParam("longShadow", d.LongShadow, 0, 100),
+			registry.FloatParam("shortShadow", d.ShortShadow, 0, 1),
+			registry.FloatParam("tolerance", d.Tolerance, 0, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewCandlePattern(item, Thresholds{
+				DojiBody:     p.Float("dojiBody"),
+				SmallBody:    p.Float("smallBody"),
+				LongBody:     p.Float("longBody"),
+				MarubozuBody: p.Float("marubozuBody"),
+				LongShadow:   p.Float("longShadow"),
+				ShortShadow:  p.Float("shortShadow"),
+				Tolerance:    p.Float("tolerance"),
+				TrendPeriod:  p.Int("trendPeriod"),
+			})
+		},
+	})
+}
@@ -15,6 +15,19 @@ type Atr struct {
 	Name   string
 	data   []AtrData
 	kline  *klines.Item
+	// 增量计算状态
+	stream *atrStream
+}
+
+// atrStream 增量计算时保存的 Rma 累计值，last 为最后一根K线之前的状态，用于 UpdateLast
+type atrStream struct {
+	last  atrState
+	state atrState
+}
+
+type atrState struct {
+	sum float64
+	atr float64
 }
 
 type AtrData struct {
@@ -119,3 +132,75 @@ func (e *Atr) GetData() []AtrData {
 	}
 	return e.data
 }
+
+// Update 追加一根已完成的K线，返回最新的 Atr 数据
+func (e *Atr) Update(candle *klines.Candle) AtrData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	var tr = e.trueRange(index)
+	e.stream.last = e.stream.state
+	e.stream.state = e.step(e.stream.last, tr, index)
+
+	var p = AtrData{Time: time.Unix(candle.TimeUnix, 0), TR: tr, Atr: e.stream.state.atr}
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Atr 数据
+func (e *Atr) UpdateLast(candle *klines.Candle) AtrData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	var tr = e.trueRange(index)
+	e.stream.state = e.step(e.stream.last, tr, index)
+
+	e.data[index] = AtrData{Time: time.Unix(candle.TimeUnix, 0), TR: tr, Atr: e.stream.state.atr}
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Atr) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &atrStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state = e.step(e.stream.last, e.data[i].TR, i)
+	}
+}
+
+// trueRange 与 Calculation 中 TR 的计算方式一致
+func (e *Atr) trueRange(index int) float64 {
+	var candle = e.kline.Candles[index]
+	var prevClose float64
+	if index != 0 {
+		prevClose = e.kline.Candles[index-1].Close
+	}
+	return math.Max(candle.High-candle.Low, math.Max(candle.High-prevClose, candle.Low-prevClose))
+}
+
+// step 与 ta.Rma 的计算方式一致
+func (e *Atr) step(state atrState, tr float64, index int) atrState {
+	if index < 1 {
+		return state
+	}
+	var count = index + 1
+	if index < e.Period {
+		state.sum += tr
+	} else {
+		state.sum = (state.atr * float64(e.Period-1)) + tr
+		count = e.Period
+	}
+	state.atr = state.sum / float64(count)
+	return state
+}
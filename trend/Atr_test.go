@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // RUN
@@ -35,3 +36,20 @@ func TestAtr(t *testing.T) {
 		)
 	}
 }
+
+// RUN
+// go test -v ./trend -run TestAtrUpdate
+func TestAtrUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 5)
+	batch := NewDefaultAtr(list).GetData()
+
+	stream := NewDefaultAtr(&klines.Item{Interval: list.Interval})
+	for i, candle := range list.Candles {
+		stream.Update(partialCandle(candle))
+		v := stream.UpdateLast(candle)
+		if !almostEqual(v.TR, batch[i].TR) || !almostEqual(v.Atr, batch[i].Atr) {
+			t.Fatalf("[%d] stream %+v batch %+v", i, v, batch[i])
+		}
+	}
+}
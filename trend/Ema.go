@@ -6,6 +6,7 @@ import (
 
 	"github.com/idoall/stockindicator/utils/klines"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/shopspring/decimal"
 )
 
 // Ema struct
@@ -14,6 +15,14 @@ type Ema struct {
 	Period int //默认计算几天的Ema
 	data   []EmaData
 	kline  *klines.Item
+	// 增量计算状态
+	stream *emaStream
+}
+
+// emaStream 增量计算时保存的状态，last 为最后一根K线之前的状态，用于 UpdateLast
+type emaStream struct {
+	last  float64
+	state float64
 }
 
 type EmaData struct {
@@ -74,6 +83,63 @@ func (e *Ema) GetValues() []float64 {
 	return val
 }
 
+// GetDecimalValues 使用 decimal 计算，返回与 GetValues 一一对应的值，多个 Ema 串联时不会累积浮点误差
+func (e *Ema) GetDecimalValues() []decimal.Decimal {
+	return ta.DecimalEma(e.Period, e.kline.GetDecimalOHLC().Close)
+}
+
+// Update 追加一根已完成的K线，返回最新的 Ema 值
+func (e *Ema) Update(candle *klines.Candle) EmaData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	e.stream.last = e.stream.state
+	e.stream.state = e.step(e.stream.last, candle.Close, index)
+
+	var p = EmaData{Time: time.Unix(candle.TimeUnix, 0), Value: e.stream.state}
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Ema 值
+func (e *Ema) UpdateLast(candle *klines.Candle) EmaData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	e.stream.state = e.step(e.stream.last, candle.Close, index)
+
+	e.data[index] = EmaData{Time: time.Unix(candle.TimeUnix, 0), Value: e.stream.state}
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Ema) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &emaStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state = e.step(e.stream.last, e.kline.Candles[i].Close, i)
+	}
+}
+
+// step 与 ta.Ema 的计算方式一致
+func (e *Ema) step(prev, value float64, index int) float64 {
+	if index == 0 {
+		return value
+	}
+	return (2*value + float64(e.Period-1)*prev) / float64(e.Period+1)
+}
+
 // Add adds a new Value to Ema
 // 使用方法，先添加最早日期的数据,最后一条应该是当前日期的数据，结果与 AICoin 对比完全一致
 // func (e *Ema) add(timestamp time.Time, value float64) {
@@ -2,9 +2,11 @@ package trend
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // RUN
@@ -27,3 +29,45 @@ func TestEma(t *testing.T) {
 		fmt.Printf("\t[%d]Time:%s\tEma%d:%f\n", i, v.Time.Format("2006-01-02 15:04:05"), stock.Period, v.Value)
 	}
 }
+
+// RUN
+// go test -v ./trend -run TestEmaUpdate
+func TestEmaUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 1)
+	batch := NewEma(list, 20).GetData()
+
+	stream := NewEma(&klines.Item{Interval: list.Interval}, 20)
+	for i, candle := range list.Candles {
+		stream.Update(partialCandle(candle))
+		v := stream.UpdateLast(candle)
+		if !almostEqual(v.Value, batch[i].Value) {
+			t.Fatalf("[%d] stream %f batch %f", i, v.Value, batch[i].Value)
+		}
+	}
+}
+
+// partialCandle 模拟尚未完成的K线，收盘价等于开盘价
+func partialCandle(candle *klines.Candle) *klines.Candle {
+	partial := *candle
+	partial.Close = candle.Open
+	return &partial
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// RUN
+// go test -v ./trend -run TestEmaDecimal
+func TestEmaDecimal(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 9)
+	stock := NewEma(list, 20)
+	batch := stock.GetValues()
+	for i, v := range stock.GetDecimalValues() {
+		if f, _ := v.Float64(); math.Abs(f-batch[i]) > 1e-9 {
+			t.Fatalf("[%d] decimal %s float %f", i, v, batch[i])
+		}
+	}
+}
@@ -14,6 +14,19 @@ type Kdj struct {
 	Period int //默认计算几天的
 	data   []KdjData
 	kline  *klines.Item
+	// 增量计算状态
+	stream *kdjStream
+}
+
+// kdjStream 增量计算时保存的状态，last 为最后一根K线之前的状态，用于 UpdateLast
+type kdjStream struct {
+	last  kdjState
+	state kdjState
+}
+
+type kdjState struct {
+	k float64
+	d float64
 }
 
 type KdjData struct {
@@ -243,6 +256,93 @@ func (e *Kdj) calculationJ() []float64 {
 // 	return LowestLine
 // }
 
+// Update 追加一根已完成的K线，返回最新的 Kdj 数据
+func (e *Kdj) Update(candle *klines.Candle) KdjData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	var rsv float64
+	e.stream.last = e.stream.state
+	e.stream.state, rsv = e.step(e.stream.last, index)
+
+	var p = e.point(e.stream.state, rsv, candle)
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Kdj 数据
+func (e *Kdj) UpdateLast(candle *klines.Candle) KdjData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	var rsv float64
+	e.stream.state, rsv = e.step(e.stream.last, index)
+
+	e.data[index] = e.point(e.stream.state, rsv, candle)
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Kdj) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &kdjStream{state: kdjState{k: 50, d: 50}}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state, _ = e.step(e.stream.last, i)
+	}
+}
+
+// step 与 calculationKD 的计算方式一致，K线数量不足 Period 时 K、D 为 50
+func (e *Kdj) step(prev kdjState, index int) (kdjState, float64) {
+	if index < e.Period-1 {
+		return kdjState{k: 50, d: 50}, 0
+	}
+
+	var candles = e.kline.Candles[index-e.Period+1 : index+1]
+	var lowest = candles[0].Low
+	var highest = candles[0].High
+	for _, v := range candles[1:] {
+		if v.Low < lowest {
+			lowest = v.Low
+		}
+		if v.High > highest {
+			highest = v.High
+		}
+	}
+
+	var rsv float64
+	if highest-lowest < 0.000001 {
+		rsv = 100
+	} else {
+		rsv = (e.kline.Candles[index].Close - lowest) / (highest - lowest) * 100
+	}
+
+	var state kdjState
+	state.k = (2.0/3)*prev.k + 1.0/3*rsv
+	state.d = (2.0/3)*prev.d + 1.0/3*state.k
+	return state, rsv
+}
+
+func (e *Kdj) point(state kdjState, rsv float64, candle *klines.Candle) KdjData {
+	return KdjData{
+		Time: time.Unix(candle.TimeUnix, 0),
+		RSV:  rsv,
+		K:    state.k,
+		D:    state.d,
+		J:    3*state.k - 2*state.d,
+	}
+}
+
 func (e *Kdj) arrayLowest(priceArray []float64) float64 {
 	length := len(priceArray)
 	var lowest = priceArray[0]
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // Run
@@ -28,3 +29,20 @@ func TestKdj(t *testing.T) {
 	}
 
 }
+
+// Run
+// go test -v ./trend -test.run TestKdjUpdate
+func TestKdjUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 6)
+	batch := NewDefaultKdj(list).GetData()
+
+	stream := NewDefaultKdj(&klines.Item{Interval: list.Interval})
+	for i, candle := range list.Candles {
+		stream.Update(partialCandle(candle))
+		v := stream.UpdateLast(candle)
+		if !almostEqual(v.K, batch[i].K) || !almostEqual(v.D, batch[i].D) || !almostEqual(v.J, batch[i].J) {
+			t.Fatalf("[%d] stream %+v batch %+v", i, v, batch[i])
+		}
+	}
+}
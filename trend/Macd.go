@@ -7,6 +7,7 @@ import (
 	"github.com/idoall/stockindicator/utils"
 	"github.com/idoall/stockindicator/utils/klines"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 /*
@@ -30,8 +31,26 @@ type Macd struct {
 	PeriodShort  int //默认12
 	PeriodSignal int //信号长度默认9
 	PeriodLong   int //默认26
-	data         []MacdData
-	kline        *klines.Item
+	// DEA 信号线的均线类型，默认 EMA
+	SignalMATypes types.MATypes
+	data          []MacdData
+	kline         *klines.Item
+	// 增量计算状态
+	stream *macdStream
+}
+
+// macdStream 增量计算时保存的状态，last 为最后一根K线之前的状态，用于 UpdateLast
+type macdStream struct {
+	last  macdState
+	state macdState
+}
+
+type macdState struct {
+	emaShort float64
+	emaLong  float64
+	dea      float64
+	// DEA 不是 EMA 时的均线状态
+	ma ta.MAState
 }
 
 type MacdData struct {
@@ -46,11 +65,23 @@ type MacdData struct {
 // 使用方法，先添加最早日期的数据,最后一条应该是当前日期的数据，结果与 AICoin 对比完全一致
 func NewMacd(klineItem *klines.Item, short, signal, long int) *Macd {
 	m := &Macd{
-		Name:         fmt.Sprintf("Macd%d-%d-%d", short, signal, long),
-		PeriodShort:  short,
-		PeriodSignal: signal,
-		PeriodLong:   long,
-		kline:        klineItem,
+		Name:          fmt.Sprintf("Macd%d-%d-%d", short, signal, long),
+		PeriodShort:   short,
+		PeriodSignal:  signal,
+		PeriodLong:    long,
+		SignalMATypes: types.EMA,
+		kline:         klineItem,
+	}
+	return m
+}
+
+// NewMacdMA new Func
+// DEA 信号线使用 maType 指定的均线
+func NewMacdMA(klineItem *klines.Item, short, signal, long int, maType types.MATypes) *Macd {
+	m := NewMacd(klineItem, short, signal, long)
+	m.SignalMATypes = maType
+	if maType != types.EMA {
+		m.Name = fmt.Sprintf("Macd%d-%d-%d-%s", short, signal, long, maType)
 	}
 	return m
 }
@@ -70,7 +101,12 @@ func (e *Macd) Calculation() *Macd {
 		ta.Ema(e.PeriodLong, closes),
 	)
 	// 计算DEA
-	deas := ta.Ema(e.PeriodSignal, difs)
+	var deas []float64
+	if e.isEmaSignal() {
+		deas = ta.Ema(e.PeriodSignal, difs)
+	} else {
+		deas = ta.MovingAverage(e.SignalMATypes, e.PeriodSignal, difs)
+	}
 
 	e.data = make([]MacdData, len(e.kline.Candles))
 	for i, dif := range difs {
@@ -145,3 +181,92 @@ func (e *Macd) GetMACDs() []float64 {
 	}
 	return val
 }
+
+// Update 追加一根已完成的K线，返回最新的 Macd 数据。
+// DEA 为 TRIMA、MAMA 时没有增量状态，调用时 panic，见 ta.CanStreamMA
+func (e *Macd) Update(candle *klines.Candle) MacdData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	e.stream.last = e.stream.state
+	e.stream.state = e.step(e.stream.last, candle.Close, index)
+
+	var p = e.point(e.stream.state, candle)
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Macd 数据，耗时与 Update 相同
+func (e *Macd) UpdateLast(candle *klines.Candle) MacdData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	e.stream.state = e.step(e.stream.last, candle.Close, index)
+
+	e.data[index] = e.point(e.stream.state, candle)
+	return e.data[index]
+}
+
+// isEmaSignal DEA 是否为 EMA，未设置均线类型时按 EMA 处理
+func (e *Macd) isEmaSignal() bool {
+	return e.SignalMATypes == types.EMA || e.SignalMATypes == types.UnknownMATypes
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Macd) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if !e.isEmaSignal() && !ta.CanStreamMA(e.SignalMATypes) {
+		panic(fmt.Errorf("%w: %s", ta.ErrMAStreaming, e.SignalMATypes))
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &macdStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state = e.step(e.stream.last, e.kline.Candles[i].Close, i)
+	}
+}
+
+// step 与 Calculation 中的 ta.Ema 计算方式一致，DEA 不是 EMA 时使用 ta.MAState
+func (e *Macd) step(prev macdState, value float64, index int) macdState {
+	var state = macdState{emaShort: value, emaLong: value}
+	if index > 0 {
+		state.emaShort = (2*value + float64(e.PeriodShort-1)*prev.emaShort) / float64(e.PeriodShort+1)
+		state.emaLong = (2*value + float64(e.PeriodLong-1)*prev.emaLong) / float64(e.PeriodLong+1)
+	}
+	var dif = state.emaShort - state.emaLong
+	if !e.isEmaSignal() {
+		state.ma, state.dea = prev.ma.Next(e.SignalMATypes, e.PeriodSignal, e.difWindow(index, dif))
+	} else if index > 0 {
+		state.dea = (2*dif + float64(e.PeriodSignal-1)*prev.dea) / float64(e.PeriodSignal+1)
+	}
+	return state
+}
+
+// difWindow 最近 PeriodSignal+1 根K线的 DIF，最后一个为当前值
+func (e *Macd) difWindow(index int, dif float64) []float64 {
+	var start = max(index-e.PeriodSignal, 0)
+	var window = make([]float64, 0, index-start+1)
+	for i := start; i < index; i++ {
+		window = append(window, e.data[i].DIF)
+	}
+	return append(window, dif)
+}
+
+func (e *Macd) point(state macdState, candle *klines.Candle) MacdData {
+	var dif = state.emaShort - state.emaLong
+	return MacdData{
+		Time: time.Unix(candle.TimeUnix, 0),
+		DIF:  dif,
+		DEA:  state.dea,
+		Macd: (dif - state.dea) * 2,
+	}
+}
@@ -1,10 +1,14 @@
 package trend
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 // Run:
@@ -30,3 +34,56 @@ func TestMacd(t *testing.T) {
 	}
 
 }
+
+// Run:
+// go test -v ./trend -run TestMacdUpdate
+func TestMacdUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 3)
+	batch := NewDefaultMacd(list).GetData()
+
+	// 先用前一半K线批量计算，再增量计算剩下的K线
+	half := &klines.Item{Interval: list.Interval, Candles: append([]*klines.Candle{}, list.Candles[:150]...)}
+	stream := NewDefaultMacd(half)
+	stream.GetData()
+	for i := 150; i < len(list.Candles); i++ {
+		stream.Update(partialCandle(list.Candles[i]))
+		v := stream.UpdateLast(list.Candles[i])
+		if !almostEqual(v.DIF, batch[i].DIF) || !almostEqual(v.DEA, batch[i].DEA) || !almostEqual(v.Macd, batch[i].Macd) {
+			t.Fatalf("[%d] stream %+v batch %+v", i, v, batch[i])
+		}
+	}
+	if len(half.Candles) != len(list.Candles) {
+		t.Fatalf("expected %d candles, got %d", len(list.Candles), len(half.Candles))
+	}
+}
+
+// Run:
+// go test -v ./trend -run TestMacdMAUpdate
+func TestMacdMAUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(200, 3)
+	for _, maType := range []types.MATypes{types.SMA, types.WMA, types.DEMA, types.TEMA, types.KAMA, types.T3MA} {
+		batch := NewMacdMA(list, 12, 9, 26, maType).GetData()
+
+		// 先用前一半K线批量计算，再增量计算剩下的K线
+		half := &klines.Item{Interval: list.Interval, Candles: append([]*klines.Candle{}, list.Candles[:100]...)}
+		stream := NewMacdMA(half, 12, 9, 26, maType)
+		stream.GetData()
+		for i := 100; i < len(list.Candles); i++ {
+			stream.Update(partialCandle(list.Candles[i]))
+			v := stream.UpdateLast(list.Candles[i])
+			if !almostEqual(v.DEA, batch[i].DEA) || !almostEqual(v.Macd, batch[i].Macd) {
+				t.Fatalf("[%d] %s stream %+v batch %+v", i, maType, v, batch[i])
+			}
+		}
+	}
+
+	// TRIMA、MAMA 没有增量状态，拒绝增量计算
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ta.ErrMAStreaming) {
+			t.Fatalf("received '%v' expected '%v'", err, ta.ErrMAStreaming)
+		}
+	}()
+	NewMacdMA(list, 12, 9, 26, types.TRIMA).Update(list.Candles[0])
+}
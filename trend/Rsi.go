@@ -14,6 +14,20 @@ type Rsi struct {
 	Period int //默认计算几天的
 	data   []RsiData
 	kline  *klines.Item
+	// 增量计算状态
+	stream *rsiStream
+}
+
+// rsiStream 增量计算时保存的状态，last 为最后一根K线之前的状态，用于 UpdateLast
+type rsiStream struct {
+	last  rsiState
+	state rsiState
+}
+
+type rsiState struct {
+	prevValue float64
+	gain      float64
+	loss      float64
 }
 
 type RsiData struct {
@@ -100,6 +114,85 @@ func (e *Rsi) GetValue() []float64 {
 	return result
 }
 
+// Update 追加一根已完成的K线，返回最新的 Rsi 值
+func (e *Rsi) Update(candle *klines.Candle) RsiData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	var value float64
+	e.stream.last = e.stream.state
+	e.stream.state, value = e.step(e.stream.last, candle.Close, index)
+
+	var p = RsiData{Time: time.Unix(candle.TimeUnix, 0), Value: value}
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Rsi 值
+func (e *Rsi) UpdateLast(candle *klines.Candle) RsiData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	var value float64
+	e.stream.state, value = e.step(e.stream.last, candle.Close, index)
+
+	e.data[index] = RsiData{Time: time.Unix(candle.TimeUnix, 0), Value: value}
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Rsi) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &rsiStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state, _ = e.step(e.stream.last, e.kline.Candles[i].Close, i)
+	}
+}
+
+// step 与 rsi 的计算方式一致：前 Period 根K线的涨跌取平均值，之后使用 Wilder 平滑
+func (e *Rsi) step(state rsiState, value float64, index int) (rsiState, float64) {
+	var period = e.Period
+	if index == 0 || period < 2 {
+		state.prevValue = value
+		return state, 0
+	}
+
+	var diff = value - state.prevValue
+	state.prevValue = value
+
+	if index > period {
+		state.loss *= float64(period - 1)
+		state.gain *= float64(period - 1)
+	}
+	if diff < 0 {
+		state.loss -= diff
+	} else {
+		state.gain += diff
+	}
+	if index < period {
+		return state, 0
+	}
+	state.loss /= float64(period)
+	state.gain /= float64(period)
+
+	var total = state.gain + state.loss
+	if !((-0.00000000000001 < total) && (total < 0.00000000000001)) {
+		return state, 100.0 * (state.gain / total)
+	}
+	return state, 0
+}
+
 func (e *Rsi) rsi(inReal []float64, inTimePeriod int) []float64 {
 
 	outReal := make([]float64, len(inReal))
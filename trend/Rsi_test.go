@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // Run:
@@ -28,3 +29,20 @@ func TestRsi(t *testing.T) {
 		fmt.Printf("\t[%d]Time:%s\t Value:%f\tSide:%s\n", i, v.Time.Format("2006-01-02 15:04:05"), v.Value, side.Data[i])
 	}
 }
+
+// Run:
+// go test -v ./trend -run TestRsiUpdate
+func TestRsiUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 4)
+	batch := NewDefaultRsi(list).GetData()
+
+	stream := NewDefaultRsi(&klines.Item{Interval: list.Interval})
+	for i, candle := range list.Candles {
+		stream.Update(partialCandle(candle))
+		v := stream.UpdateLast(candle)
+		if !almostEqual(v.Value, batch[i].Value) {
+			t.Fatalf("[%d] stream %f batch %f", i, v.Value, batch[i].Value)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package trend
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/idoall/stockindicator/utils/klines"
@@ -14,6 +15,14 @@ type Sma struct {
 	Period int //默认计算几天的MA,KDJ一般是9，OBV是10、20、30
 	data   []SmaData
 	kline  *klines.Item
+	// 增量计算状态
+	stream *smaStream
+}
+
+// smaStream 增量计算时保存的窗口累计值，last 为最后一根K线之前的状态，用于 UpdateLast
+type smaStream struct {
+	last  float64
+	state float64
 }
 
 type SmaData struct {
@@ -74,3 +83,65 @@ func (e *Sma) GetValues() []float64 {
 	// fmt.Println(val)
 	return val
 }
+
+// Update 追加一根已完成的K线，返回最新的 Sma 值
+func (e *Sma) Update(candle *klines.Candle) SmaData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	var value float64
+	e.stream.last = e.stream.state
+	e.stream.state, value = e.step(e.stream.last, index)
+
+	var p = SmaData{Time: time.Unix(candle.TimeUnix, 0), Value: value}
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Sma 值
+func (e *Sma) UpdateLast(candle *klines.Candle) SmaData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	var value float64
+	e.stream.state, value = e.step(e.stream.last, index)
+
+	e.data[index] = SmaData{Time: time.Unix(candle.TimeUnix, 0), Value: value}
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Sma) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &smaStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state, _ = e.step(e.stream.last, i)
+	}
+}
+
+// step 与 ta.Sma 的计算方式一致，K线数量不足 Period 时按已有数量求平均
+func (e *Sma) step(sum float64, index int) (float64, float64) {
+	var count = index + 1
+	sum += e.kline.Candles[index].Close
+	if index >= e.Period {
+		sum -= e.kline.Candles[index-e.Period].Close
+		count = e.Period
+	}
+
+	var val = sum / float64(count)
+	if math.IsNaN(val) || math.IsInf(val, -1) {
+		val = 0
+	}
+	return sum, val
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // RUN
@@ -31,3 +32,20 @@ func TestSma(t *testing.T) {
 		)
 	}
 }
+
+// RUN
+// go test -v ./trend -run TestSmaUpdate
+func TestSmaUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 2)
+	batch := NewSma(list, 20).GetData()
+
+	stream := NewSma(&klines.Item{Interval: list.Interval}, 20)
+	for i, candle := range list.Candles {
+		stream.Update(partialCandle(candle))
+		v := stream.UpdateLast(candle)
+		if !almostEqual(v.Value, batch[i].Value) {
+			t.Fatalf("[%d] stream %f batch %f", i, v.Value, batch[i].Value)
+		}
+	}
+}
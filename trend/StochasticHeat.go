@@ -37,7 +37,7 @@ type StochasticHeatData struct {
 //		smoothFast Smooth Fast
 //		smoothSlow Smooth Slow
 //		plotNum 1-28
-//		maTypes Only "SMA","EMA","WMA"
+//		maTypes 均线类型，支持 types 中定义的所有类型
 func NewStochasticHeat(klineItem *klines.Item, inc, smoothFast, smoothSlow, plotNum int, maTypes types.MATypes) *StochasticHeat {
 	m := &StochasticHeat{
 		Name:       fmt.Sprintf("StochasticHeat%d-%d-%d-%d-%d", inc, smoothFast, smoothSlow, plotNum, maTypes),
@@ -105,14 +105,12 @@ func (e *StochasticHeat) Calculation() *StochasticHeat {
 	for i := range e.kline.Candles {
 		var getAverage = (stoch1[i] + stoch2[i] + stoch3[i] + stoch4[i] + stoch5[i] + stoch6[i] + stoch7[i] + stoch8[i] + stoch9[i] + stoch10[i] + stoch11[i] + stoch12[i] + stoch13[i] + stoch14[i] + stoch15[i] + stoch16[i] + stoch17[i] + stoch18[i] + stoch19[i] + stoch20[i] + stoch21[i] + stoch22[i] + stoch23[i] + stoch24[i] + stoch25[i] + stoch26[i] + stoch27[i] + stoch28[i]) / float64(e.PlotNum)
 		fast[i] = ((getAverage / 100) * float64(e.PlotNum))
+	}
 
-		if e.MATypes == types.EMA {
-			slow = ta.Ema(e.SmoothSlow, fast)
-		} else if e.MATypes == types.SMA {
-			slow = ta.Sma(e.SmoothSlow, fast)
-		} else {
-			slow = ta.Wma(e.SmoothSlow, fast)
-		}
+	if e.MATypes == types.UnknownMATypes {
+		slow = ta.Wma(e.SmoothSlow, fast)
+	} else {
+		slow = ta.MovingAverage(e.MATypes, e.SmoothSlow, fast)
 	}
 	for i := 0; i < len(e.kline.Candles); i++ {
 		e.data = append(e.data, StochasticHeatData{
@@ -167,16 +165,10 @@ func (e *StochasticHeat) getStoch(i int, closing, highs, lows []float64) []float
 
 	var c = i * e.Increment
 	var k, _ = ta.Stochastic(closing, highs, lows, c)
-	switch e.MATypes {
-	case types.EMA:
-		return ta.Ema(e.SmoothFast, k)
-	case types.SMA:
-		return ta.Sma(e.SmoothFast, k)
-	case types.WMA:
-		return ta.Wma(e.SmoothFast, k)
-	default:
+	if e.MATypes == types.UnknownMATypes {
 		return k
 	}
+	return ta.MovingAverage(e.MATypes, e.SmoothFast, k)
 }
 
 // AnalysisSide Func
@@ -22,6 +22,25 @@ type SuperTrend struct {
 	data      []SuperTrendData
 	ohlc      *klines.OHLC
 	hl2       []float64
+	// 增量计算状态
+	stream *superTrendStream
+}
+
+// superTrendStream 增量计算时保存的状态，last 为最后一根K线之前的状态，用于 UpdateLast
+type superTrendStream struct {
+	last  superTrendState
+	state superTrendState
+	// ChangeAtr 为 false 时 Sma 需要用到的 Natr 历史值
+	natrs []float64
+}
+
+type superTrendState struct {
+	trSum   float64 // 前 AtrPeriod 根K线的真实波幅累计
+	atr     float64 // Wilder 平滑后的 Atr
+	natrSum float64 // Natr 的 Sma 窗口累计
+	up      float64
+	dn      float64
+	trend   float64
 }
 
 type SuperTrendData struct {
@@ -193,3 +212,202 @@ func (e *SuperTrend) AnalysisSide() utils.SideData {
 		Data: sides,
 	}
 }
+
+// Update 追加一根已完成的K线，返回最新的 SuperTrend 数据
+func (e *SuperTrend) Update(candle *klines.Candle) SuperTrendData {
+	e.initStream()
+	var index = len(e.data)
+	e.setCandle(index, candle)
+
+	var p SuperTrendData
+	e.stream.last = e.stream.state
+	e.stream.state, p = e.step(e.stream.last, index)
+
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 SuperTrend 数据
+func (e *SuperTrend) UpdateLast(candle *klines.Candle) SuperTrendData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.setCandle(index, candle)
+
+	e.stream.state, e.data[index] = e.step(e.stream.last, index)
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *SuperTrend) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.ohlc.Close) > 0 {
+		e.Calculation()
+	}
+	e.stream = &superTrendStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state, _ = e.step(e.stream.last, i)
+	}
+}
+
+// setCandle 设置第 index 根K线的 OHLC 数据，index 等于K线数量时追加
+func (e *SuperTrend) setCandle(index int, candle *klines.Candle) {
+	var hl2 = (candle.High + candle.Low) / 2
+	if index < len(e.ohlc.Close) {
+		e.ohlc.Open[index] = candle.Open
+		e.ohlc.High[index] = candle.High
+		e.ohlc.Low[index] = candle.Low
+		e.ohlc.Close[index] = candle.Close
+		e.ohlc.Volume[index] = candle.Volume
+		e.ohlc.BullMarket[index] = candle.IsBullMarket
+		e.ohlc.TimeUnix[index] = candle.TimeUnix
+		e.hl2[index] = hl2
+		return
+	}
+	e.ohlc.Open = append(e.ohlc.Open, candle.Open)
+	e.ohlc.High = append(e.ohlc.High, candle.High)
+	e.ohlc.Low = append(e.ohlc.Low, candle.Low)
+	e.ohlc.Close = append(e.ohlc.Close, candle.Close)
+	e.ohlc.Volume = append(e.ohlc.Volume, candle.Volume)
+	e.ohlc.BullMarket = append(e.ohlc.BullMarket, candle.IsBullMarket)
+	e.ohlc.TimeUnix = append(e.ohlc.TimeUnix, candle.TimeUnix)
+	e.hl2 = append(e.hl2, hl2)
+}
+
+// trueRange 与 ta.TRange 的计算方式一致
+func (e *SuperTrend) trueRange(index int) float64 {
+	if index < 1 {
+		return 0
+	}
+	var high = e.ohlc.High[index]
+	var low = e.ohlc.Low[index]
+	var prevClose = e.ohlc.Close[index-1]
+	greatest := high - low
+	if val := math.Abs(prevClose - high); val > greatest {
+		greatest = val
+	}
+	if val := math.Abs(prevClose - low); val > greatest {
+		greatest = val
+	}
+	return greatest
+}
+
+// stepAtr 与 Calculation 中 ta.Atr 或 ta.Natr + ta.Sma 的计算方式一致
+func (e *SuperTrend) stepAtr(state superTrendState, index int) (superTrendState, float64) {
+	var period = e.AtrPeriod
+	var periodF = float64(period)
+	var tr = e.trueRange(index)
+
+	if e.ChangeAtr {
+		switch {
+		case period < 1:
+			return state, 0
+		case period == 1:
+			return state, tr
+		case index < 1:
+			return state, 0
+		case index < period:
+			state.trSum += tr
+			return state, 0
+		case index == period:
+			state.atr = ((state.trSum/periodF)*(periodF-1) + tr) / periodF
+		default:
+			state.atr *= periodF - 1.0
+			state.atr += tr
+			state.atr /= periodF
+		}
+		return state, state.atr
+	}
+
+	var natr float64
+	switch {
+	case period < 1:
+	case period == 1:
+		natr = tr
+	case index <= period:
+		state.trSum += tr
+		if index == period {
+			state.atr = state.trSum / periodF
+			natr = e.normalize(state.atr, index)
+		}
+	default:
+		state.atr *= periodF - 1.0
+		state.atr += tr
+		state.atr /= periodF
+		natr = e.normalize(state.atr, index)
+	}
+
+	if index < len(e.stream.natrs) {
+		e.stream.natrs[index] = natr
+	} else {
+		e.stream.natrs = append(e.stream.natrs, natr)
+	}
+
+	var count = index + 1
+	state.natrSum += natr
+	if index >= period {
+		state.natrSum -= e.stream.natrs[index-period]
+		count = period
+	}
+	var atr = state.natrSum / float64(count)
+	if math.IsNaN(atr) || math.IsInf(atr, -1) {
+		atr = 0
+	}
+	return state, atr
+}
+
+// normalize 与 ta.Natr 一致，收盘价为 0 时返回 0
+func (e *SuperTrend) normalize(atr float64, index int) float64 {
+	if e.ohlc.Close[index] == 0 {
+		return 0
+	}
+	return (atr / e.ohlc.Close[index]) * 100.0
+}
+
+// step 与 Calculation 的计算方式一致
+func (e *SuperTrend) step(prev superTrendState, index int) (superTrendState, SuperTrendData) {
+	var state, atr = e.stepAtr(prev, index)
+	var p = SuperTrendData{Time: time.Unix(e.ohlc.TimeUnix[index], 0)}
+
+	if index < 1 {
+		state.up, state.dn, state.trend = 0, 0, 1
+		return state, p
+	}
+
+	var src = e.hl2[index]
+	var closing = e.ohlc.Close[index]
+	var prevClosing = e.ohlc.Close[index-1]
+
+	state.up = src - (float64(e.AtrMultiplier) * atr)
+	var up1 = ta.Nz(prev.up, state.up)
+	if prevClosing > up1 {
+		state.up = math.Max(state.up, up1)
+	}
+
+	state.dn = src + (float64(e.AtrMultiplier) * atr)
+	var dn1 = ta.Nz(prev.dn, state.dn)
+	if prevClosing < dn1 {
+		state.dn = math.Min(state.dn, dn1)
+	}
+
+	state.trend = ta.Nz(prev.trend, 1)
+	if state.trend == -1 && closing > dn1 {
+		state.trend = 1
+	} else if state.trend == 1 && closing < up1 {
+		state.trend = -1
+	}
+
+	p.UpTrend = state.up
+	p.DownTrend = state.dn
+	if state.trend == 1 && prev.trend == -1 {
+		p.UpTrendBegin = state.up
+	} else if state.trend == -1 && prev.trend == 1 {
+		p.DownTrendBegin = state.dn
+	}
+	return state, p
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // RUN
@@ -29,3 +30,25 @@ func TestSuperTrend(t *testing.T) {
 	}
 
 }
+
+// RUN
+// go test -v ./trend -run TestSuperTrendUpdate
+func TestSuperTrendUpdate(t *testing.T) {
+	t.Parallel()
+	for _, changeAtr := range []bool{true, false} {
+		list := utils.GetRandomKlineItem(300, 7)
+		batch := NewSuperTrend(list, 10, 3, changeAtr).GetData()
+
+		stream := NewSuperTrend(&klines.Item{Interval: list.Interval}, 10, 3, changeAtr)
+		for i, candle := range list.Candles {
+			stream.Update(partialCandle(candle))
+			v := stream.UpdateLast(candle)
+			if !almostEqual(v.UpTrend, batch[i].UpTrend) ||
+				!almostEqual(v.DownTrend, batch[i].DownTrend) ||
+				!almostEqual(v.UpTrendBegin, batch[i].UpTrendBegin) ||
+				!almostEqual(v.DownTrendBegin, batch[i].DownTrendBegin) {
+				t.Fatalf("[%d] changeAtr %v stream %+v batch %+v", i, changeAtr, v, batch[i])
+			}
+		}
+	}
+}
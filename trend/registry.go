@@ -0,0 +1,219 @@
+package trend
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+	"github.com/idoall/stockindicator/utils/types"
+)
+
+// 注册趋势类指标，参数顺序与 NewXxx 一致
+func init() {
+	registry.Register(registry.Definition{
+		Name: "AiCoinCCI", Category: registry.Trend, Description: "AiCoin 版本的顺势指标",
+		Params: []registry.Param{registry.IntParam("period", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAiCoinCCI(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Atr", Category: registry.Trend, Description: "平均真实波幅",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAtr(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "AverageDirectionalIndex", Aliases: []string{"adx"}, Category: registry.Trend, Description: "平均方向指数",
+		Params: []registry.Param{registry.IntParam("period", 30, 1), registry.IntParam("length", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAverageDirectionalIndex(item, p.Int("period"), p.Int("length"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "BreakoutProbability", Category: registry.Trend, Description: "突破概率",
+		Params: []registry.Param{
+			registry.FloatParam("percentageStep", 1.2, 0, 100),
+			registry.IntParam("numberOfLines", 5, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewBreakoutProbability(item, p.Float("percentageStep"), p.Int("numberOfLines"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Cci", Category: registry.Trend, Description: "顺势指标",
+		Params: []registry.Param{registry.IntParam("period", 20, 1), registry.IntParam("smaPeriod", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewCci(item, p.Int("period"), p.Int("smaPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Dema", Category: registry.Trend, Description: "双重指数移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewDema(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Ema", Category: registry.Trend, Description: "指数移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 5, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewEma(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "EMAVegas", Category: registry.Trend, Description: "维加斯通道",
+		Params: []registry.Param{
+			registry.IntParam("period", 12, 1),
+			registry.IntParam("periodShort1", 144, 1),
+			registry.IntParam("periodShort2", 169, 1),
+			registry.IntParam("periodLong1", 575, 1),
+			registry.IntParam("periodLong2", 676, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewEMAVegas(item, p.Int("period"), p.Int("periodShort1"), p.Int("periodShort2"), p.Int("periodLong1"), p.Int("periodLong2"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Kdj", Category: registry.Trend, Description: "随机指标",
+		Params: []registry.Param{registry.IntParam("period", 9, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewKdj(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "LinearRegressionCandles", Category: registry.Trend, Description: "线性回归K线",
+		Params: []registry.Param{
+			registry.IntParam("signalSmoothing", 7, 1),
+			registry.IntParam("linearRegressionLength", 11, 1),
+			registry.BoolParam("smaSignal", true),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewLinearRegressionCandles(item, p.Int("signalSmoothing"), p.Int("linearRegressionLength"), p.Bool("smaSignal"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Ma", Category: registry.Trend, Description: "移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewMa(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Macd", Category: registry.Trend, Description: "指数平滑异同移动平均线",
+		Params: []registry.Param{
+			registry.IntParam("short", 12, 1),
+			registry.IntParam("signal", 9, 1),
+			registry.IntParam("long", 26, 1),
+			registry.MATypeParam("signalMAType", types.EMA),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewMacdMA(item, p.Int("short"), p.Int("signal"), p.Int("long"), p.MAType("signalMAType"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "PivotPointSuperTrend", Category: registry.Trend, Description: "枢轴点超级趋势",
+		Params: []registry.Param{
+			registry.IntParam("period", 3, 1),
+			registry.IntParam("atrPeriod", 8, 1),
+			registry.FloatParam("atrFactor", 2.8, 0, 100),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewPivotPointSuperTrend(item, p.Int("period"), p.Int("atrPeriod"), p.Float("atrFactor"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "ReversalSignals", Category: registry.Trend, Description: "反转信号",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewReversalSignals(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Rma", Category: registry.Trend, Description: "滚动移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 13, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewRma(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Rsi", Category: registry.Trend, Description: "相对强弱指标",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewRsi(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Sma", Category: registry.Trend, Description: "简单移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 9, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewSma(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "SmartMoneyConcepts", Aliases: []string{"smc"}, Category: registry.Trend, Description: "聪明钱概念",
+		Params: []registry.Param{
+			registry.IntParam("swingLength", 50, 1),
+			registry.IntParam("barsConfirmation", 3, 1),
+			registry.IntParam("threshold", 1, 0),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewSmartMoneyConcepts(item, p.Int("swingLength"), p.Int("barsConfirmation"), p.Int("threshold"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "StochasticHeat", Category: registry.Trend, Description: "随机热力图",
+		Params: []registry.Param{
+			registry.IntParam("inc", 8, 1),
+			registry.IntParam("smoothFast", 7, 1),
+			registry.IntParam("smoothSlow", 26, 1),
+			registry.IntParam("plotNum", 25, 1),
+			registry.MATypeParam("maType", types.WMA),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewStochasticHeat(item, p.Int("inc"), p.Int("smoothFast"), p.Int("smoothSlow"), p.Int("plotNum"), p.MAType("maType"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "StochRsi", Category: registry.Trend, Description: "随机相对强弱指标",
+		Params: []registry.Param{
+			registry.IntParam("smoothK", 3, 1),
+			registry.IntParam("smoothD", 3, 1),
+			registry.IntParam("rsiLength", 14, 1),
+			registry.IntParam("stochLength", 14, 1),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewStochRsi(item, p.Int("smoothK"), p.Int("smoothD"), p.Int("rsiLength"), p.Int("stochLength"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "SuperTrend", Category: registry.Trend, Description: "超级趋势",
+		Params: []registry.Param{
+			registry.IntParam("atrPeriod", 10, 1),
+			registry.IntParam("atrMultiplier", 3, 1),
+			registry.BoolParam("changeAtr", true),
+		},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewSuperTrend(item, p.Int("atrPeriod"), p.Int("atrMultiplier"), p.Bool("changeAtr"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "TraderXO", Category: registry.Trend, Description: "TraderXO 均线交叉",
+		Params: []registry.Param{registry.IntParam("fastPeriod", 12, 1), registry.IntParam("slowPeriod", 25, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewTraderXO(item, p.Int("fastPeriod"), p.Int("slowPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "UTBot", Category: registry.Trend, Description: "UT Bot 警报",
+		Params: []registry.Param{registry.IntParam("period", 2, 1), registry.IntParam("atrPeriod", 1, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewUTBot(item, p.Int("period"), p.Int("atrPeriod"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Vortex", Category: registry.Trend, Description: "涡旋指标",
+		Params: []registry.Param{registry.IntParam("period", 9, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewVortex(item, p.Int("period"))
+		},
+	})
+}
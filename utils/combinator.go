@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 组合策略，每个组合策略本身也实现了 IStrategy，可以相互嵌套，
+// 也可以直接传给 RunStrategies 或回测。
+//
+// 子策略返回的信号数量不一致时，以第一个策略为准，缺少的信号按 Hold 处理。
+
+// AllAgree 所有策略方向一致时才发出信号
+type AllAgree struct {
+	Name       string
+	strategies []IStrategy
+}
+
+// NewAllAgree new Func
+func NewAllAgree(strategies ...IStrategy) *AllAgree {
+	return &AllAgree{
+		Name:       combinatorName("All", strategies),
+		strategies: strategies,
+	}
+}
+
+// AnalysisSide Func
+func (e *AllAgree) AnalysisSide() SideData {
+	var all = RunStrategies(e.strategies...)
+	var sides = make([]Side, sideLength(all))
+	for i := range sides {
+		var buy, sell = countSides(all, i)
+		if buy == len(all) && buy > 0 {
+			sides[i] = Buy
+		} else if sell == len(all) && sell > 0 {
+			sides[i] = Sell
+		} else {
+			sides[i] = Hold
+		}
+	}
+	return SideData{Name: e.Name, Data: sides}
+}
+
+// AnyOf 任意一个策略发出信号即发出信号，买卖信号冲突时为 Hold
+type AnyOf struct {
+	Name       string
+	strategies []IStrategy
+}
+
+// NewAnyOf new Func
+func NewAnyOf(strategies ...IStrategy) *AnyOf {
+	return &AnyOf{
+		Name:       combinatorName("Any", strategies),
+		strategies: strategies,
+	}
+}
+
+// AnalysisSide Func
+func (e *AnyOf) AnalysisSide() SideData {
+	var all = RunStrategies(e.strategies...)
+	var sides = make([]Side, sideLength(all))
+	for i := range sides {
+		var buy, sell = countSides(all, i)
+		if buy > 0 && sell == 0 {
+			sides[i] = Buy
+		} else if sell > 0 && buy == 0 {
+			sides[i] = Sell
+		} else {
+			sides[i] = Hold
+		}
+	}
+	return SideData{Name: e.Name, Data: sides}
+}
+
+// Majority 超过半数的策略方向一致时发出信号
+type Majority struct {
+	Name       string
+	strategies []IStrategy
+}
+
+// NewMajority new Func
+func NewMajority(strategies ...IStrategy) *Majority {
+	return &Majority{
+		Name:       combinatorName("Majority", strategies),
+		strategies: strategies,
+	}
+}
+
+// AnalysisSide Func
+func (e *Majority) AnalysisSide() SideData {
+	var all = RunStrategies(e.strategies...)
+	var sides = make([]Side, sideLength(all))
+	for i := range sides {
+		var buy, sell = countSides(all, i)
+		if buy*2 > len(all) {
+			sides[i] = Buy
+		} else if sell*2 > len(all) {
+			sides[i] = Sell
+		} else {
+			sides[i] = Hold
+		}
+	}
+	return SideData{Name: e.Name, Data: sides}
+}
+
+// WeightedStrategy 带权重的策略
+type WeightedStrategy struct {
+	Strategy IStrategy
+	Weight   float64
+}
+
+// Weighted 加权评分，Buy 记 +Weight，Sell 记 -Weight，Hold 记 0，
+// 评分 >= BuyThreshold 时买入，评分 <= -SellThreshold 时卖出，
+// 阈值小于等于 0 时评分也必须不为 0，全部为 Hold 的K线不会发出信号
+type Weighted struct {
+	Name          string
+	BuyThreshold  float64
+	SellThreshold float64
+	strategies    []WeightedStrategy
+	scores        []float64
+}
+
+// NewWeighted new Func
+func NewWeighted(buyThreshold, sellThreshold float64, strategies ...WeightedStrategy) *Weighted {
+	var names = make([]string, len(strategies))
+	for i, v := range strategies {
+		names[i] = fmt.Sprintf("%s*%g", StrategyName(v.Strategy), v.Weight)
+	}
+	return &Weighted{
+		Name:          fmt.Sprintf("Weighted(%s)", strings.Join(names, ",")),
+		BuyThreshold:  buyThreshold,
+		SellThreshold: sellThreshold,
+		strategies:    strategies,
+	}
+}
+
+// AnalysisSide Func
+func (e *Weighted) AnalysisSide() SideData {
+	var all = make([]SideData, len(e.strategies))
+	for i, v := range e.strategies {
+		all[i] = v.Strategy.AnalysisSide()
+	}
+
+	var sides = make([]Side, sideLength(all))
+	e.scores = make([]float64, len(sides))
+	for i := range sides {
+		var score float64
+		for x, v := range all {
+			score += e.strategies[x].Weight * sideScore(sideAt(v, i))
+		}
+		e.scores[i] = score
+
+		if score > 0 && score >= e.BuyThreshold {
+			sides[i] = Buy
+		} else if score < 0 && score <= -e.SellThreshold {
+			sides[i] = Sell
+		} else {
+			sides[i] = Hold
+		}
+	}
+	return SideData{Name: e.Name, Data: sides}
+}
+
+// GetScores 返回每根K线的加权评分
+func (e *Weighted) GetScores() []float64 {
+	if e.scores == nil {
+		e.AnalysisSide()
+	}
+	return e.scores
+}
+
+// Confirm 主策略的信号在 Bars 根K线内得到确认策略同方向信号时发出信号，
+// 信号出现在两者中较晚的那根K线上，不会用到未来数据。
+//
+// 例如 Macd 金叉后 3 根K线内 Rsi 也给出买入信号：
+//
+//	NewConfirm(trend.NewDefaultMacd(list), trend.NewDefaultRsi(list), 3)
+type Confirm struct {
+	Name         string
+	Bars         int
+	primary      IStrategy
+	confirmation IStrategy
+}
+
+// NewConfirm new Func
+func NewConfirm(primary, confirmation IStrategy, bars int) *Confirm {
+	return &Confirm{
+		Name:         fmt.Sprintf("Confirm(%s,%s,%d)", StrategyName(primary), StrategyName(confirmation), bars),
+		Bars:         bars,
+		primary:      primary,
+		confirmation: confirmation,
+	}
+}
+
+// AnalysisSide Func
+func (e *Confirm) AnalysisSide() SideData {
+	var primary = e.primary.AnalysisSide()
+	var confirmation = e.confirmation.AnalysisSide()
+
+	var sides = make([]Side, len(primary.Data))
+	for i := range sides {
+		sides[i] = Hold
+		for _, side := range []Side{Buy, Sell} {
+			// 主策略刚出现信号，确认信号已在窗口内出现
+			if sideAt(primary, i) == side && e.within(confirmation, side, i, 0) {
+				sides[i] = side
+				break
+			}
+			// 确认信号刚出现，主策略信号已在窗口内出现
+			if sideAt(confirmation, i) == side && e.within(primary, side, i, 1) {
+				sides[i] = side
+				break
+			}
+		}
+	}
+	return SideData{Name: e.Name, Data: sides}
+}
+
+// within 判断 [index-Bars, index-skip] 区间内是否出现过 side 信号
+func (e *Confirm) within(data SideData, side Side, index, skip int) bool {
+	for i := index - skip; i >= index-e.Bars && i >= 0; i-- {
+		if sideAt(data, i) == side {
+			return true
+		}
+	}
+	return false
+}
+
+// sideLength 以第一个策略的信号数量为准
+func sideLength(all []SideData) int {
+	if len(all) == 0 {
+		return 0
+	}
+	return len(all[0].Data)
+}
+
+// sideAt 返回第 i 根K线的信号，越界时返回 Hold。
+// 策略不计算第一根K线，信号为零值 Buy，也按 Hold 处理
+func sideAt(data SideData, i int) Side {
+	if i <= 0 || i >= len(data.Data) {
+		return Hold
+	}
+	return data.Data[i]
+}
+
+// countSides 统计第 i 根K线的买卖信号数量
+func countSides(all []SideData, i int) (buy, sell int) {
+	for _, v := range all {
+		switch sideAt(v, i) {
+		case Buy:
+			buy++
+		case Sell:
+			sell++
+		}
+	}
+	return buy, sell
+}
+
+func sideScore(s Side) float64 {
+	switch s {
+	case Buy:
+		return 1
+	case Sell:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// StrategyName 获取策略或指标的 Name 字段，没有 Name 字段或为空时使用类型名，不会计算策略
+func StrategyName(strategy interface{}) string {
+	var value = reflect.Indirect(reflect.ValueOf(strategy))
+	if value.Kind() == reflect.Struct {
+		if name := value.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && name.String() != "" {
+			return name.String()
+		}
+	}
+	return value.Type().Name()
+}
+
+func combinatorName(kind string, strategies []IStrategy) string {
+	var names = make([]string, len(strategies))
+	for i, v := range strategies {
+		names[i] = StrategyName(v)
+	}
+	return fmt.Sprintf("%s(%s)", kind, strings.Join(names, ","))
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// countingStrategy 记录 AnalysisSide 的调用次数
+type countingStrategy struct {
+	Name  string
+	calls *int
+}
+
+func (e countingStrategy) AnalysisSide() SideData {
+	*e.calls++
+	return SideData{Name: e.Name, Data: []Side{Buy}}
+}
+
+// RUN
+// go test -v ./utils -run TestCombinators
+func TestCombinators(t *testing.T) {
+	t.Parallel()
+	var a = GetSidesStrategy("a", Buy, Buy, Sell, Hold, Sell)
+	var b = GetSidesStrategy("b", Buy, Sell, Sell, Buy, Hold)
+	var c = GetSidesStrategy("c", Buy, Buy, Hold, Hold, Sell)
+
+	var tests = []struct {
+		name     string
+		strategy IStrategy
+		expected []Side
+	}{
+		{"All", NewAllAgree(a, b, c), []Side{Hold, Hold, Hold, Hold, Hold}},
+		{"Any", NewAnyOf(a, b), []Side{Hold, Hold, Sell, Buy, Sell}},
+		{"Majority", NewMajority(a, b, c), []Side{Hold, Buy, Sell, Hold, Sell}},
+		{"Weighted", NewWeighted(2, 2,
+			WeightedStrategy{Strategy: a, Weight: 2},
+			WeightedStrategy{Strategy: b, Weight: 1},
+		), []Side{Hold, Hold, Sell, Hold, Sell}},
+		{"Nested", NewAnyOf(NewAllAgree(a, c), b), []Side{Hold, Hold, Sell, Buy, Sell}},
+	}
+
+	for _, test := range tests {
+		var side = test.strategy.AnalysisSide()
+		if !reflect.DeepEqual(side.Data, test.expected) {
+			t.Fatalf("%s: received %v expected %v", test.name, side.Data, test.expected)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils -run TestCombinatorFirstBar
+func TestCombinatorFirstBar(t *testing.T) {
+	t.Parallel()
+	// 第一根K线没有计算，信号为零值 Buy
+	var primary = GetSidesStrategy("primary", Buy, Hold, Hold, Hold)
+	var confirm = GetSidesStrategy("confirm", Buy, Buy, Hold, Hold)
+	var hold = GetSidesStrategy("hold", Buy, Hold, Hold, Buy)
+
+	var tests = []struct {
+		name     string
+		strategy IStrategy
+		expected []Side
+	}{
+		{"Confirm", NewConfirm(primary, confirm, 1), []Side{Hold, Hold, Hold, Hold}},
+		{"All", NewAllAgree(primary, confirm), []Side{Hold, Hold, Hold, Hold}},
+		// 阈值为 0 时全部为 Hold 的K线不会买入
+		{"Weighted", NewWeighted(0, 0, WeightedStrategy{Strategy: hold, Weight: 1}), []Side{Hold, Hold, Hold, Buy}},
+	}
+	for _, test := range tests {
+		var side = test.strategy.AnalysisSide()
+		if !reflect.DeepEqual(side.Data, test.expected) {
+			t.Fatalf("%s: received %v expected %v", test.name, side.Data, test.expected)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils -run TestCombinatorName
+func TestCombinatorName(t *testing.T) {
+	t.Parallel()
+	var calls int
+	var leaf = countingStrategy{Name: "leaf", calls: &calls}
+
+	// 创建时只读取名称，不计算子策略
+	var strategy IStrategy = leaf
+	for i := 0; i < 10; i++ {
+		strategy = NewAnyOf(strategy, leaf)
+	}
+	var nested = NewConfirm(NewWeighted(1, 1, WeightedStrategy{Strategy: strategy, Weight: 1}), NewMajority(leaf, SidesStrategy{}), 2)
+	if calls != 0 {
+		t.Fatalf("received %d calls expected 0", calls)
+	}
+	if expected := "Majority(leaf,SidesStrategy)"; !strings.HasSuffix(nested.Name, expected+",2)") {
+		t.Fatalf("received %s expected suffix %s", nested.Name, expected)
+	}
+	if expected := "Any(Any(leaf,leaf),leaf)"; NewAnyOf(NewAnyOf(leaf, leaf), leaf).Name != expected {
+		t.Fatalf("received %s expected %s", NewAnyOf(NewAnyOf(leaf, leaf), leaf).Name, expected)
+	}
+}
+
+// RUN
+// go test -v ./utils -run TestConfirm
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+	var primary = GetSidesStrategy("primary", Hold, Buy, Hold, Hold, Hold, Hold, Sell, Hold)
+	var confirm = GetSidesStrategy("confirm", Hold, Hold, Hold, Buy, Hold, Sell, Hold, Hold)
+
+	var side = NewConfirm(primary, confirm, 2).AnalysisSide()
+	// 买入在第 3 根K线得到确认，卖出的确认信号早于主信号 1 根K线
+	var expected = []Side{Hold, Hold, Hold, Buy, Hold, Hold, Sell, Hold}
+	if !reflect.DeepEqual(side.Data, expected) {
+		t.Fatalf("received %v expected %v", side.Data, expected)
+	}
+
+	side = NewConfirm(primary, confirm, 1).AnalysisSide()
+	expected = []Side{Hold, Hold, Hold, Hold, Hold, Hold, Sell, Hold}
+	if !reflect.DeepEqual(side.Data, expected) {
+		t.Fatalf("received %v expected %v", side.Data, expected)
+	}
+}
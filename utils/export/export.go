@@ -0,0 +1,219 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoCandles 没有K线数据
+	ErrNoCandles = errors.New("export: no candle data")
+	// ErrNotIndicator 指标没有 GetData() 方法，或返回的不是结构体切片
+	ErrNotIndicator = errors.New("export: value has no GetData() returning a slice of structs")
+)
+
+// Table 导出的宽表，每根K线一行
+//
+//	Columns 列名，依次为 time、open、high、low、close、volume、各指标字段（指标名.字段名）、各策略信号
+//	Rows 每行的值，类型为 time.Time、float64、int64、bool 或 string，没有数据时为 nil
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Exporter 把K线、指标与策略信号按时间对齐导出
+type Exporter struct {
+	// 时间格式，默认为 time.RFC3339
+	TimeLayout string
+	// 输出时间使用的时区，默认为 UTC
+	Location *time.Location
+
+	kline      *klines.Item
+	indicators []interface{}
+	strategies []utils.IStrategy
+}
+
+// NewExporter new Func
+func NewExporter(klineItem *klines.Item) *Exporter {
+	return &Exporter{
+		TimeLayout: time.RFC3339,
+		Location:   time.UTC,
+		kline:      klineItem,
+	}
+}
+
+// AddIndicator 添加指标，指标需要有 GetData() 方法返回带 Time 字段的结构体切片，
+// 列名前缀使用指标的 Name 字段
+func (e *Exporter) AddIndicator(indicators ...interface{}) *Exporter {
+	e.indicators = append(e.indicators, indicators...)
+	return e
+}
+
+// AddStrategy 添加策略，每个策略导出一列信号
+func (e *Exporter) AddStrategy(strategies ...utils.IStrategy) *Exporter {
+	e.strategies = append(e.strategies, strategies...)
+	return e
+}
+
+// Table 生成宽表
+//
+// 指标数据按 Time 字段对齐到K线，找不到对应K线的数据会被忽略；
+// 所有 Time 都为空的指标以及策略信号按最后一根K线右对齐。
+func (e *Exporter) Table() (*Table, error) {
+	if e.kline == nil || len(e.kline.Candles) == 0 {
+		return nil, ErrNoCandles
+	}
+
+	var candles = e.kline.Candles
+	var table = &Table{
+		Columns: []string{"time", "open", "high", "low", "close", "volume"},
+		Rows:    make([][]interface{}, len(candles)),
+	}
+	var index = make(map[int64]int, len(candles))
+	for i, candle := range candles {
+		index[candle.TimeUnix] = i
+		table.Rows[i] = []interface{}{
+			time.Unix(candle.TimeUnix, 0),
+			candle.Open,
+			candle.High,
+			candle.Low,
+			candle.Close,
+			candle.Volume,
+		}
+	}
+
+	for _, indicator := range e.indicators {
+		columns, values, times, err := indicatorColumns(indicator)
+		if err != nil {
+			return nil, err
+		}
+		var offset = len(table.Columns)
+		table.Columns = append(table.Columns, columns...)
+		for i := range table.Rows {
+			table.Rows[i] = append(table.Rows[i], make([]interface{}, len(columns))...)
+		}
+
+		var aligned = false
+		for _, t := range times {
+			if !t.IsZero() {
+				aligned = true
+				break
+			}
+		}
+		for i, row := range values {
+			var target int
+			if aligned {
+				var ok bool
+				if target, ok = index[times[i].Unix()]; !ok {
+					continue
+				}
+			} else {
+				target = len(candles) - len(values) + i
+				if target < 0 {
+					continue
+				}
+			}
+			copy(table.Rows[target][offset:], row)
+		}
+	}
+
+	for _, strategy := range e.strategies {
+		var side = strategy.AnalysisSide()
+		table.Columns = append(table.Columns, side.Name)
+		var offset = len(candles) - len(side.Data)
+		for i := range table.Rows {
+			var value interface{}
+			if x := i - offset; x >= 0 && x < len(side.Data) {
+				value = side.Data[x].String()
+			}
+			table.Rows[i] = append(table.Rows[i], value)
+		}
+	}
+
+	return table, nil
+}
+
+// indicatorColumns 通过反射读取指标数据，返回列名、每行的值与时间
+func indicatorColumns(indicator interface{}) (columns []string, values [][]interface{}, times []time.Time, err error) {
+	var method = reflect.ValueOf(indicator).MethodByName("GetData")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, nil, nil, fmt.Errorf("%w: %T", ErrNotIndicator, indicator)
+	}
+	var data = method.Call(nil)[0]
+	if data.Kind() != reflect.Slice {
+		return nil, nil, nil, fmt.Errorf("%w: %T", ErrNotIndicator, indicator)
+	}
+
+	var elem = data.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, nil, fmt.Errorf("%w: %T", ErrNotIndicator, indicator)
+	}
+
+	var name = utils.StrategyName(indicator)
+	var fields []int
+	var timeField = -1
+	for i := 0; i < elem.NumField(); i++ {
+		var field = elem.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type == reflect.TypeOf(time.Time{}) {
+			if field.Name == "Time" {
+				timeField = i
+			}
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Bool, reflect.String:
+			fields = append(fields, i)
+			columns = append(columns, name+"."+field.Name)
+		}
+	}
+
+	values = make([][]interface{}, data.Len())
+	times = make([]time.Time, data.Len())
+	for x := 0; x < data.Len(); x++ {
+		var item = data.Index(x)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		if timeField >= 0 {
+			times[x] = item.Field(timeField).Interface().(time.Time)
+		}
+		var row = make([]interface{}, len(fields))
+		for i, f := range fields {
+			row[i] = cellValue(item.Field(f))
+		}
+		values[x] = row
+	}
+	return columns, values, times, nil
+}
+
+func cellValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Bool:
+		return v.Bool()
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/idoall/stockindicator/channel"
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./utils/export -run TestExportCSV
+func TestExportCSV(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(50, 1)
+	var boll = channel.NewBoll(list, 20, 2)
+	var macd = trend.NewDefaultMacd(list)
+
+	var buf bytes.Buffer
+	if err := NewExporter(list).AddIndicator(boll, macd).AddStrategy(macd).WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(list.Candles)+1 {
+		t.Fatalf("expected %d records, got %d", len(list.Candles)+1, len(records))
+	}
+
+	var header = records[0]
+	var column = func(name string) int {
+		for i, v := range header {
+			if v == name {
+				return i
+			}
+		}
+		t.Fatalf("column %s not found in %v", name, header)
+		return -1
+	}
+
+	var bollData = boll.GetData()
+	var last = records[len(records)-1]
+	upper, err := strconv.ParseFloat(last[column(boll.Name+".Upper")], 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upper != bollData[len(bollData)-1].Upper {
+		t.Fatalf("unexpected upper %f, expected %f", upper, bollData[len(bollData)-1].Upper)
+	}
+
+	var sides = macd.AnalysisSide()
+	if last[column(sides.Name)] != sides.Data[len(sides.Data)-1].String() {
+		t.Fatalf("unexpected side %s", last[column(sides.Name)])
+	}
+}
+
+// RUN
+// go test -v ./utils/export -run TestExportJSON
+func TestExportJSON(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(30, 2)
+
+	var buf bytes.Buffer
+	if err := NewExporter(list).AddIndicator(trend.NewSma(list, 5)).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(list.Candles) {
+		t.Fatalf("expected %d rows, got %d", len(list.Candles), len(rows))
+	}
+	if rows[29]["close"] != list.Candles[29].Close {
+		t.Fatalf("unexpected close %v", rows[29]["close"])
+	}
+	if _, ok := rows[29]["Sma5.Value"]; !ok {
+		t.Fatalf("missing Sma5.Value in %v", rows[29])
+	}
+
+	if err := NewExporter(&klines.Item{}).WriteJSON(&buf); !errors.Is(err, ErrNoCandles) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNoCandles)
+	}
+	if err := NewExporter(list).AddIndicator(list).WriteJSON(&buf); !errors.Is(err, ErrNotIndicator) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNotIndicator)
+	}
+}
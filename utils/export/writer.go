@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Records 返回格式化为字符串的宽表，第一行为列名，NaN 按 "NaN" 输出，没有数据时为空字符串
+func (e *Exporter) Records() ([][]string, error) {
+	table, err := e.Table()
+	if err != nil {
+		return nil, err
+	}
+
+	var records = make([][]string, 0, len(table.Rows)+1)
+	records = append(records, table.Columns)
+	for _, row := range table.Rows {
+		var record = make([]string, len(row))
+		for i, v := range row {
+			record[i] = e.format(v)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// WriteCSV 把宽表写为 CSV，第一行为列名
+func (e *Exporter) WriteCSV(w io.Writer) error {
+	records, err := e.Records()
+	if err != nil {
+		return err
+	}
+
+	var writer = csv.NewWriter(w)
+	if err = writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// WriteJSON 把宽表写为 JSON 数组，每行一个对象，键的顺序与列顺序一致，NaN 与 Inf 写为 null
+func (e *Exporter) WriteJSON(w io.Writer) error {
+	table, err := e.Table()
+	if err != nil {
+		return err
+	}
+
+	var keys = make([][]byte, len(table.Columns))
+	for i, column := range table.Columns {
+		if keys[i], err = json.Marshal(column); err != nil {
+			return err
+		}
+	}
+
+	var writer = bufio.NewWriter(w)
+	writer.WriteString("[")
+	for x, row := range table.Rows {
+		if x > 0 {
+			writer.WriteString(",")
+		}
+		writer.WriteString("\n{")
+		for i, v := range row {
+			if i > 0 {
+				writer.WriteString(",")
+			}
+			writer.Write(keys[i])
+			writer.WriteString(":")
+
+			value, err := e.jsonValue(v)
+			if err != nil {
+				return err
+			}
+			writer.Write(value)
+		}
+		writer.WriteString("}")
+	}
+	writer.WriteString("\n]\n")
+	return writer.Flush()
+}
+
+func (e *Exporter) format(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return e.formatTime(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case bool:
+		return strconv.FormatBool(value)
+	case string:
+		return value
+	default:
+		return ""
+	}
+}
+
+func (e *Exporter) jsonValue(v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case time.Time:
+		return json.Marshal(e.formatTime(value))
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return []byte("null"), nil
+		}
+	}
+	return json.Marshal(v)
+}
+
+func (e *Exporter) formatTime(t time.Time) string {
+	var location = e.Location
+	if location == nil {
+		location = time.UTC
+	}
+	var layout = e.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.In(location).Format(layout)
+}
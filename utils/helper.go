@@ -1,14 +1,16 @@
 package utils
 
 import (
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 
-	"github.com/idoall/stockindicator/utils/commonutils"
 	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/loader"
 )
 
-// ReadKlineFromFile
+// GetTestKlineItem 读取当前目录下的 data/test.json，读取失败时 panic，仅用于测试
 func GetTestKlineItem() *klines.Item {
 
 	workPath, err := os.Getwd()
@@ -16,28 +18,84 @@ func GetTestKlineItem() *klines.Item {
 		panic(err)
 	}
 
-	var filePath = filepath.Join(workPath, "./")
+	var opts = loader.DefaultOptions()
+	opts.Exchange = "testExchange"
+	opts.Interval = klines.ThirtyMin
 
-	filePath = filepath.Join(filePath, "data")
-	filePath = filepath.Join(filePath, "test.json")
-
-	// 读取文件内容
-	file, err := commonutils.ReadFile(filePath)
+	item, err := loader.LoadFile(filepath.Join(workPath, "data", "test.json"), opts)
 	if err != nil {
 		panic(err)
 	}
+	return item
+}
 
-	var candles []*klines.Candle
-	// 进行 JSON 解析
-	err = commonutils.JSONDecode(file, &candles)
-	if err != nil {
-		panic(err)
-	}
-	return &klines.Item{
+// GetRandomKlineItem 生成 count 根随机游走的K线，相同的 seed 生成相同的数据，用于不依赖数据文件的测试
+func GetRandomKlineItem(count int, seed int64) *klines.Item {
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec // used for generating test data, no need to import crypo/rand
+
+	item := &klines.Item{
 		Exchange: "testExchange",
 		Interval: klines.ThirtyMin,
-		Candles:  candles,
+		Candles:  make([]*klines.Candle, count),
+	}
+
+	start := int64(1700000000) / 1800 * 1800
+	price := 100.0
+	for i := 0; i < count; i++ {
+		open := price
+		closing := math.Max(open*(1+r.NormFloat64()*0.01), 0.01)
+		high := math.Max(open, closing) * (1 + r.Float64()*0.005)
+		low := math.Min(open, closing) * (1 - r.Float64()*0.005)
+		item.Candles[i] = &klines.Candle{
+			TimeUnix:      start + int64(i)*1800,
+			Open:          open,
+			High:          high,
+			Low:           low,
+			Close:         closing,
+			Volume:        1000 + r.Float64()*1000,
+			ChangePercent: (closing - open) / open,
+			IsBullMarket:  closing > open,
+		}
+		price = closing
 	}
+	return item
+}
+
+// GetCloseKlineItem 按收盘价生成K线，开盘价等于收盘价，最高价与最低价为收盘价 ±spread，成交量为 100，
+// 用于需要构造特定走势的测试
+func GetCloseKlineItem(interval klines.Interval, spread float64, closes ...float64) *klines.Item {
+	item := &klines.Item{
+		Exchange: "testExchange",
+		Interval: interval,
+		Candles:  make([]*klines.Candle, len(closes)),
+	}
+
+	var seconds = int64(interval.Duration().Seconds())
+	start := int64(1700000000) / seconds * seconds
+	for i, v := range closes {
+		item.Candles[i] = &klines.Candle{
+			TimeUnix: start + int64(i)*seconds,
+			Open:     v,
+			High:     v + spread,
+			Low:      v - spread,
+			Close:    v,
+			Volume:   100,
+		}
+	}
+	return item
+}
+
+// SidesStrategy 直接返回给定信号的策略，用于测试
+type SidesStrategy SideData
+
+// AnalysisSide Func
+func (e SidesStrategy) AnalysisSide() SideData {
+	return SideData(e)
+}
+
+// GetSidesStrategy 返回给定名称与信号的 SidesStrategy，用于测试
+func GetSidesStrategy(name string, sides ...Side) SidesStrategy {
+	return SidesStrategy{Name: name, Data: sides}
 }
 
 // func GetTestKline() Klines {
@@ -1,9 +1,20 @@
 package utils
 
+import "github.com/idoall/stockindicator/utils/klines"
+
 type IStrategy interface {
 	AnalysisSide() SideData
 }
 
+// IStreaming 增量计算接口，实盘中每根K线只需要 O(1) 更新，不需要重新计算全部历史
+//
+//	Update		追加一根已完成的K线，返回最新的数据点
+//	UpdateLast	更新最后一根（未完成的）K线，返回最新的数据点
+type IStreaming[T any] interface {
+	Update(candle *klines.Candle) T
+	UpdateLast(candle *klines.Candle) T
+}
+
 // RunStrategies 运行多个策略
 func RunStrategies(strategies ...IStrategy) []SideData {
 	actions := make([]SideData, len(strategies))
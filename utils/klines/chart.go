@@ -0,0 +1,335 @@
+package klines
+
+import (
+	"errors"
+	"math"
+
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// ErrInvalidBoxSize 砖块、反转幅度或区间大小必须大于 0
+var ErrInvalidBoxSize = errors.New("box size must be greater than zero")
+
+// 以下转换只按价格生成新的K线，与时间无关，生成的K线使用形成它的原始K线时间，
+// 同一根原始K线可能生成多根时间相同的K线。返回的 Item 可以直接用于所有指标。
+
+// newChartItem 创建与 e 相同品种的空 Item
+func (e *Item) newChartItem() *Item {
+	return &Item{
+		Exchange: e.Exchange,
+		Interval: e.Interval,
+		Symbol:   e.Symbol,
+		Code:     e.Code,
+	}
+}
+
+// newChartCandle 生成开盘价到收盘价的K线
+func newChartCandle(timeUnix int64, open, close, volume float64) *Candle {
+	var candle = &Candle{
+		Open:         open,
+		Close:        close,
+		High:         math.Max(open, close),
+		Low:          math.Min(open, close),
+		Volume:       volume,
+		TimeUnix:     timeUnix,
+		IsBullMarket: close > open,
+	}
+	if open != 0 {
+		candle.ChangePercent = (close - open) / open
+	}
+	return candle
+}
+
+// ToRenko 转换为砖形图，使用收盘价，每块砖的高度为 boxSize。
+// 同方向需要超过上一块砖 1 个 boxSize，反方向需要超过 2 个 boxSize，只返回已完成的砖块，
+// 成交量平均分配给同一根原始K线形成的砖块，没有形成砖块的K线成交量计入下一块砖。
+func (e *Item) ToRenko(boxSize float64) (*Item, error) {
+	if boxSize <= 0 || math.IsNaN(boxSize) {
+		return nil, ErrInvalidBoxSize
+	}
+	var result = e.newChartItem()
+	if len(e.Candles) == 0 {
+		return result, nil
+	}
+
+	// top 与 bottom 为最后一块砖的上下沿，上涨砖块从 top 开始，下跌砖块从 bottom 开始，
+	// 因此反方向的砖块需要价格超过上一块砖 2 个 boxSize
+	var top, bottom = e.Candles[0].Close, e.Candles[0].Close
+	var volume float64
+	for _, candle := range e.Candles {
+		volume += candle.Volume
+		var bricks []*Candle
+		for {
+			if candle.Close >= top+boxSize {
+				bricks = append(bricks, newChartCandle(candle.TimeUnix, top, top+boxSize, 0))
+				bottom, top = top, top+boxSize
+			} else if candle.Close <= bottom-boxSize {
+				bricks = append(bricks, newChartCandle(candle.TimeUnix, bottom, bottom-boxSize, 0))
+				top, bottom = bottom, bottom-boxSize
+			} else {
+				break
+			}
+		}
+		if len(bricks) == 0 {
+			continue
+		}
+		for _, brick := range bricks {
+			brick.Volume = volume / float64(len(bricks))
+		}
+		volume = 0
+		result.Candles = append(result.Candles, bricks...)
+	}
+	return result, nil
+}
+
+// ToRenkoATR 使用最后一根K线的 Atr(period) 作为砖块高度转换为砖形图
+func (e *Item) ToRenkoATR(period int) (*Item, error) {
+	if period < 1 || len(e.Candles) <= period {
+		return nil, ErrInvalidBoxSize
+	}
+	var ohlc = e.GetOHLC()
+	var atr = ta.Atr(ohlc.High, ohlc.Low, ohlc.Close, period)
+	return e.ToRenko(atr[len(atr)-1])
+}
+
+// ToKagi 转换为卡吉图，使用收盘价，价格反向变动超过 reversal 时转折。
+// 每根K线为一段卡吉线，开盘价为起点，收盘价为终点，最后一段为尚未完成的线段；
+// IsBullMarket 表示阳线（粗线）：价格突破前一个肩部时变为阳线，跌破前一个腰部时变为阴线。
+func (e *Item) ToKagi(reversal float64) (*Item, error) {
+	if reversal <= 0 || math.IsNaN(reversal) {
+		return nil, ErrInvalidBoxSize
+	}
+	var result = e.newChartItem()
+	if len(e.Candles) == 0 {
+		return result, nil
+	}
+
+	var current = newChartCandle(e.Candles[0].TimeUnix, e.Candles[0].Close, e.Candles[0].Close, 0)
+	var direction int
+	// shoulder 与 waist 为最近的高点与低点，yang 为当前线段是否为阳线
+	var shoulder, waist = math.NaN(), math.NaN()
+	var yang bool
+	var volume float64
+
+	for _, candle := range e.Candles {
+		var price = candle.Close
+		volume += candle.Volume
+		switch {
+		case direction >= 0 && price > current.Close || direction <= 0 && price < current.Close:
+			// 同方向延伸
+			if direction == 0 {
+				direction = 1
+				if price < current.Close {
+					direction = -1
+				}
+			}
+			current.Close = price
+			current.TimeUnix = candle.TimeUnix
+			current.Volume += volume
+		case direction > 0 && current.Close-price >= reversal || direction < 0 && price-current.Close >= reversal:
+			// 反转，当前线段结束
+			if direction > 0 {
+				shoulder = current.Close
+			} else {
+				waist = current.Close
+			}
+			result.Candles = append(result.Candles, current)
+			current = newChartCandle(candle.TimeUnix, current.Close, price, volume)
+			direction = -direction
+		default:
+			continue
+		}
+		volume = 0
+		if !math.IsNaN(shoulder) && current.Close > shoulder {
+			yang = true
+		} else if !math.IsNaN(waist) && current.Close < waist {
+			yang = false
+		} else if math.IsNaN(shoulder) && math.IsNaN(waist) {
+			yang = direction > 0
+		}
+		current.High = math.Max(current.Open, current.Close)
+		current.Low = math.Min(current.Open, current.Close)
+		current.IsBullMarket = yang
+		if current.Open != 0 {
+			current.ChangePercent = (current.Close - current.Open) / current.Open
+		}
+	}
+	if direction != 0 {
+		current.Volume += volume
+		result.Candles = append(result.Candles, current)
+	}
+	return result, nil
+}
+
+// ToLineBreak 转换为 N 线突破图（常用 3 线），使用收盘价。
+// 收盘价高于前 lines 根线的最高价时画一根上涨线，低于最低价时画一根下跌线，
+// 上涨线从前一根线的最高价开始，下跌线从前一根线的最低价开始；没有形成新线的K线成交量计入下一根线。
+func (e *Item) ToLineBreak(lines int) (*Item, error) {
+	if lines < 1 {
+		return nil, ErrInvalidBoxSize
+	}
+	var result = e.newChartItem()
+	var volume float64
+	for i, candle := range e.Candles {
+		volume += candle.Volume
+		var count = len(result.Candles)
+		if count == 0 {
+			if i > 0 && candle.Close != e.Candles[0].Close {
+				result.Candles = append(result.Candles, newChartCandle(candle.TimeUnix, e.Candles[0].Close, candle.Close, volume))
+				volume = 0
+			}
+			continue
+		}
+
+		var high, low = math.Inf(-1), math.Inf(1)
+		for _, line := range result.Candles[max(0, count-lines):] {
+			high = math.Max(high, line.High)
+			low = math.Min(low, line.Low)
+		}
+		var last = result.Candles[count-1]
+		switch {
+		case candle.Close > high:
+			result.Candles = append(result.Candles, newChartCandle(candle.TimeUnix, last.High, candle.Close, volume))
+		case candle.Close < low:
+			result.Candles = append(result.Candles, newChartCandle(candle.TimeUnix, last.Low, candle.Close, volume))
+		default:
+			continue
+		}
+		volume = 0
+	}
+	return result, nil
+}
+
+// ToRangeBars 转换为区间K线，每根K线的最高价与最低价之差为 rangeSize，最后一根为尚未完成的K线。
+// 原始K线内的价格按阳线 开-低-高-收、阴线 开-高-低-收 的顺序变化，
+// 新的区间K线从上一根的收盘价开始，原始K线的成交量平均分配给它经过的区间K线。
+func (e *Item) ToRangeBars(rangeSize float64) (*Item, error) {
+	if rangeSize <= 0 || math.IsNaN(rangeSize) {
+		return nil, ErrInvalidBoxSize
+	}
+	var result = e.newChartItem()
+	if len(e.Candles) == 0 {
+		return result, nil
+	}
+
+	var first = e.Candles[0]
+	var current = &Candle{Open: first.Open, High: first.Open, Low: first.Open, Close: first.Open, TimeUnix: first.TimeUnix}
+	var finish = func(c *Candle) {
+		c.IsBullMarket = c.Close > c.Open
+		if c.Open != 0 {
+			c.ChangePercent = (c.Close - c.Open) / c.Open
+		}
+	}
+
+	for _, candle := range e.Candles {
+		var path = []float64{candle.Open, candle.Low, candle.High, candle.Close}
+		if candle.Close < candle.Open {
+			path = []float64{candle.Open, candle.High, candle.Low, candle.Close}
+		}
+		var touched = []*Candle{current}
+		for _, price := range path {
+			for {
+				current.TimeUnix = candle.TimeUnix
+				if price > current.Low+rangeSize {
+					current.High = current.Low + rangeSize
+					current.Close = current.High
+				} else if price < current.High-rangeSize {
+					current.Low = current.High - rangeSize
+					current.Close = current.Low
+				} else {
+					current.High = math.Max(current.High, price)
+					current.Low = math.Min(current.Low, price)
+					current.Close = price
+					break
+				}
+				finish(current)
+				result.Candles = append(result.Candles, current)
+				current = &Candle{Open: current.Close, High: current.Close, Low: current.Close, Close: current.Close, TimeUnix: candle.TimeUnix}
+				touched = append(touched, current)
+			}
+		}
+		for _, c := range touched {
+			c.Volume += candle.Volume / float64(len(touched))
+		}
+	}
+	finish(current)
+	result.Candles = append(result.Candles, current)
+	return result, nil
+}
+
+// PointAndFigureColumn 点数图的一列，X 列（上涨）或 O 列（下跌）
+type PointAndFigureColumn struct {
+	// 是否为 X 列
+	Up bool
+	// 最高与最低的格子价格
+	Top    float64
+	Bottom float64
+	// 格子数量
+	Boxes int
+	// 开始与最后一次变化的K线时间
+	StartTimeUnix int64
+	EndTimeUnix   int64
+}
+
+// ToPointAndFigure 转换为点数图，使用最高价与最低价，价格按 boxSize 取整为格子。
+// X 列在最高价达到更高的格子时延伸，反向超过 reversal 个格子时转为 O 列，O 列与之相反。
+func (e *Item) ToPointAndFigure(boxSize float64, reversal int) ([]PointAndFigureColumn, error) {
+	if boxSize <= 0 || math.IsNaN(boxSize) || reversal < 1 {
+		return nil, ErrInvalidBoxSize
+	}
+	if len(e.Candles) == 0 {
+		return nil, nil
+	}
+
+	// 使用格子序号计算，避免浮点误差
+	var floor = func(price float64) int64 { return int64(math.Floor(price/boxSize + 1e-9)) }
+	var ceil = func(price float64) int64 { return int64(math.Ceil(price/boxSize - 1e-9)) }
+
+	type column struct {
+		up          bool
+		top, bottom int64
+		start, end  int64
+	}
+	var columns []column
+	var base = floor(e.Candles[0].Close)
+
+	for _, candle := range e.Candles {
+		var high, low = floor(candle.High), ceil(candle.Low)
+		if len(columns) == 0 {
+			if high > base {
+				columns = append(columns, column{up: true, bottom: base + 1, top: high, start: candle.TimeUnix, end: candle.TimeUnix})
+			} else if low < base {
+				columns = append(columns, column{up: false, top: base - 1, bottom: low, start: candle.TimeUnix, end: candle.TimeUnix})
+			}
+			continue
+		}
+
+		var last = &columns[len(columns)-1]
+		if last.up {
+			if high > last.top {
+				last.top, last.end = high, candle.TimeUnix
+			} else if last.top-low >= int64(reversal) {
+				columns = append(columns, column{up: false, top: last.top - 1, bottom: low, start: candle.TimeUnix, end: candle.TimeUnix})
+			}
+		} else {
+			if low < last.bottom {
+				last.bottom, last.end = low, candle.TimeUnix
+			} else if high-last.bottom >= int64(reversal) {
+				columns = append(columns, column{up: true, bottom: last.bottom + 1, top: high, start: candle.TimeUnix, end: candle.TimeUnix})
+			}
+		}
+	}
+
+	var result = make([]PointAndFigureColumn, len(columns))
+	for i, c := range columns {
+		result[i] = PointAndFigureColumn{
+			Up:            c.up,
+			Top:           float64(c.top) * boxSize,
+			Bottom:        float64(c.bottom) * boxSize,
+			Boxes:         int(c.top-c.bottom) + 1,
+			StartTimeUnix: c.start,
+			EndTimeUnix:   c.end,
+		}
+	}
+	return result, nil
+}
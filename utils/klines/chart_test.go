@@ -0,0 +1,176 @@
+package klines
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// newChartTestItem 按收盘价生成K线，开盘价为前一根收盘价
+func newChartTestItem(closes ...float64) *Item {
+	var item = &Item{Interval: OneHour, Symbol: "test"}
+	for i, v := range closes {
+		var open = v
+		if i > 0 {
+			open = closes[i-1]
+		}
+		item.Candles = append(item.Candles, &Candle{
+			TimeUnix: 1699977600 + int64(i)*3600,
+			Open:     open,
+			High:     math.Max(open, v),
+			Low:      math.Min(open, v),
+			Close:    v,
+			Volume:   10,
+		})
+	}
+	return item
+}
+
+// RUN
+// go test -v ./utils/klines -run TestToRenko
+func TestToRenko(t *testing.T) {
+	t.Parallel()
+	var item = newChartTestItem(10, 10.5, 12.2, 11.5, 10.9, 9.8, 9.9, 8.7)
+	renko, err := item.ToRenko(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12.2 上涨两块 10-11-12，10.9 不足反转，9.8 从 11 下跌到 10，8.7 再下跌到 9
+	var expected = [][2]float64{{10, 11}, {11, 12}, {11, 10}, {10, 9}}
+	if len(renko.Candles) != len(expected) || renko.Symbol != "test" {
+		t.Fatalf("unexpected renko %d bricks", len(renko.Candles))
+	}
+	for i, v := range expected {
+		if c := renko.Candles[i]; c.Open != v[0] || c.Close != v[1] || c.IsBullMarket != (v[1] > v[0]) {
+			t.Fatalf("[%d] brick %v expected %v", i, [2]float64{c.Open, c.Close}, v)
+		}
+	}
+	// 12.2 形成两块砖，平均分配前三根K线的成交量
+	if renko.Candles[0].Volume != 15 || renko.Candles[0].TimeUnix != item.Candles[2].TimeUnix {
+		t.Fatalf("unexpected first brick %+v", renko.Candles[0])
+	}
+	var volume float64
+	for _, c := range renko.Candles {
+		volume += c.Volume
+	}
+	if volume != 80 {
+		t.Fatalf("volume %f expected 80", volume)
+	}
+
+	if _, err = item.ToRenko(0); !errors.Is(err, ErrInvalidBoxSize) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrInvalidBoxSize)
+	}
+	if atr, err := item.ToRenkoATR(3); err != nil || len(atr.Candles) == 0 {
+		t.Fatalf("unexpected atr renko %v %v", atr, err)
+	}
+}
+
+// RUN
+// go test -v ./utils/klines -run TestToKagi
+func TestToKagi(t *testing.T) {
+	t.Parallel()
+	var item = newChartTestItem(10, 11, 12, 11.5, 10.5, 11, 12.5, 12, 9.5)
+	kagi, err := item.ToKagi(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10->12 阳线，12->10.5 反转，10.5->12.5 突破肩部 12，12.5->9.5 跌破腰部 10.5
+	var expected = [][2]float64{{10, 12}, {12, 10.5}, {10.5, 12.5}, {12.5, 9.5}}
+	var yang = []bool{true, true, true, false}
+	if len(kagi.Candles) != len(expected) {
+		t.Fatalf("unexpected kagi %d lines", len(kagi.Candles))
+	}
+	for i, v := range expected {
+		if c := kagi.Candles[i]; c.Open != v[0] || c.Close != v[1] || c.IsBullMarket != yang[i] {
+			t.Fatalf("[%d] line %+v expected %v yang %v", i, c, v, yang[i])
+		}
+	}
+	var volume float64
+	for _, c := range kagi.Candles {
+		volume += c.Volume
+	}
+	if volume != 90 {
+		t.Fatalf("volume %f expected 90", volume)
+	}
+}
+
+// RUN
+// go test -v ./utils/klines -run TestToLineBreak
+func TestToLineBreak(t *testing.T) {
+	t.Parallel()
+	var item = newChartTestItem(10, 11, 12, 13, 12.5, 10.5, 9.5, 10)
+	lines, err := item.ToLineBreak(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12.5 与 10.5 没有跌破前三根线的最低价 10，9.5 跌破后从最后一根线的最低价 12 开始
+	var expected = [][2]float64{{10, 11}, {11, 12}, {12, 13}, {12, 9.5}}
+	if len(lines.Candles) != len(expected) {
+		t.Fatalf("unexpected %d lines", len(lines.Candles))
+	}
+	for i, v := range expected {
+		if c := lines.Candles[i]; c.Open != v[0] || c.Close != v[1] {
+			t.Fatalf("[%d] line %v expected %v", i, [2]float64{c.Open, c.Close}, v)
+		}
+	}
+	if lines.Candles[3].Volume != 30 {
+		t.Fatalf("volume %f expected 30", lines.Candles[3].Volume)
+	}
+}
+
+// RUN
+// go test -v ./utils/klines -run TestToRangeBars
+func TestToRangeBars(t *testing.T) {
+	t.Parallel()
+	var item = newChartTestItem(10, 12.5, 12, 13)
+	bars, err := item.ToRangeBars(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range bars.Candles[:len(bars.Candles)-1] {
+		if math.Abs(c.High-c.Low-1) > 1e-9 {
+			t.Fatalf("[%d] range %f expected 1", i, c.High-c.Low)
+		}
+		if i > 0 && c.Open != bars.Candles[i-1].Close {
+			t.Fatalf("[%d] open %f expected previous close", i, c.Open)
+		}
+	}
+	var last = bars.Candles[len(bars.Candles)-1]
+	if last.Close != 13 || last.High-last.Low > 1 {
+		t.Fatalf("unexpected last bar %+v", last)
+	}
+	var volume float64
+	for _, c := range bars.Candles {
+		volume += c.Volume
+	}
+	if math.Abs(volume-40) > 1e-9 {
+		t.Fatalf("volume %f expected 40", volume)
+	}
+}
+
+// RUN
+// go test -v ./utils/klines -run TestToPointAndFigure
+func TestToPointAndFigure(t *testing.T) {
+	t.Parallel()
+	var item = newChartTestItem(10, 12.5, 14, 13, 11, 12, 10.5, 13.2)
+	columns, err := item.ToPointAndFigure(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// X 11-14，13 与 12 不足 3 格反转，11 反转为 O 13-11，10.5 向上取整仍为 11，13.2 距离 11 不足 3 格
+	var expected = []PointAndFigureColumn{
+		{Up: true, Bottom: 11, Top: 14, Boxes: 4},
+		{Up: false, Bottom: 11, Top: 13, Boxes: 3},
+	}
+	if len(columns) != len(expected) {
+		t.Fatalf("unexpected columns %+v", columns)
+	}
+	for i, v := range expected {
+		if c := columns[i]; c.Up != v.Up || c.Top != v.Top || c.Bottom != v.Bottom || c.Boxes != v.Boxes {
+			t.Fatalf("[%d] column %+v expected %+v", i, c, v)
+		}
+	}
+	if _, err = item.ToPointAndFigure(1, 0); !errors.Is(err, ErrInvalidBoxSize) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrInvalidBoxSize)
+	}
+}
@@ -260,6 +260,16 @@ func (e *Item) addPadding(start, exclusiveEnd time.Time, purgeOnPartial bool) er
 	return nil
 }
 
+// SetCandle 设置第 index 根K线，index 等于K线数量时追加到末尾。
+// 多个指标共用同一个 Item 进行增量计算时，同一根K线只会追加一次
+func (e *Item) SetCandle(index int, candle *Candle) {
+	if index < len(e.Candles) {
+		e.Candles[index] = candle
+		return
+	}
+	e.Candles = append(e.Candles, candle)
+}
+
 func (e *Item) Clear() {
 	clear(e.Candles)
 	e.Candles = nil
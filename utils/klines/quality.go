@@ -0,0 +1,387 @@
+package klines
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrNotEnoughCandles 检查数据质量至少需要两根K线
+	ErrNotEnoughCandles = errors.New("at least two candles are required")
+	// ErrUnknownFillMethod 未知的填充方式
+	ErrUnknownFillMethod = errors.New("unknown fill method")
+)
+
+// FillMethod 缺失K线的填充方式
+type FillMethod uint32
+
+const (
+	// FillForward 使用前一根K线的收盘价填充开高低收，成交量为 0
+	FillForward FillMethod = iota
+	// FillInterpolate 在缺口前后两根K线的收盘价之间线性插值，成交量为 0
+	FillInterpolate
+)
+
+// AnomalyKind 异常K线的类型
+type AnomalyKind uint32
+
+const (
+	// ZeroPrice 开高低收中存在小于等于 0 的价格
+	ZeroPrice AnomalyKind = iota
+	// InvalidOHLC 最高价低于开盘价、收盘价或最低价，或最低价高于开盘价、收盘价
+	InvalidOHLC
+	// PriceOutlier 相对前一根K线的涨跌幅异常
+	PriceOutlier
+	// RangeSpike 最高价与最低价的振幅异常
+	RangeSpike
+	// Unaligned K线时间与第一根K线相差的不是周期的整数倍
+	Unaligned
+	// Duplicate K线时间与前一根相同
+	Duplicate
+	// Unsorted K线时间早于前一根
+	Unsorted
+)
+
+// String implements the stringer interface
+func (k AnomalyKind) String() string {
+	switch k {
+	case ZeroPrice:
+		return "ZERO_PRICE"
+	case InvalidOHLC:
+		return "INVALID_OHLC"
+	case PriceOutlier:
+		return "PRICE_OUTLIER"
+	case RangeSpike:
+		return "RANGE_SPIKE"
+	case Unaligned:
+		return "UNALIGNED"
+	case Duplicate:
+		return "DUPLICATE"
+	case Unsorted:
+		return "UNSORTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Anomaly 异常K线
+type Anomaly struct {
+	Index int
+	Time  time.Time
+	Kind  AnomalyKind
+	// 异常值，PriceOutlier 为对数收益率，RangeSpike 为振幅比例，其余为收盘价
+	Value float64
+}
+
+// Gap 连续缺失的K线
+type Gap struct {
+	// 第一根缺失K线的时间
+	Start time.Time
+	// 缺口之后第一根存在的K线时间
+	End time.Time
+	// 缺失的K线数量
+	Missing int
+}
+
+// QualityOptions 数据质量检查选项
+type QualityOptions struct {
+	// 异常阈值，收益率或振幅与中位数的偏离超过 Threshold 倍 MAD（中位数绝对偏差）时视为异常，为 0 时不检查
+	Threshold float64
+}
+
+// DefaultQualityOptions 默认检查选项
+func DefaultQualityOptions() QualityOptions {
+	return QualityOptions{Threshold: 10}
+}
+
+// QualityReport 数据质量报告
+type QualityReport struct {
+	Interval Interval
+	// 第一根K线的时间
+	Start time.Time
+	// 最后一根K线结束的时间
+	End time.Time
+	// 按周期应有的K线数量
+	Expected int
+	// 实际的K线数量
+	Candles int
+	// 缺失的K线数量
+	Missing int
+	Gaps    []Gap
+	// 按时间排列的异常K线
+	Anomalies []Anomaly
+	// 每个周期是否有数据，可以用 HasDataAtDate 查询
+	Ranges *IntervalRangeHolder
+}
+
+// OK 没有缺失与异常
+func (r *QualityReport) OK() bool {
+	return r.Missing == 0 && len(r.Anomalies) == 0
+}
+
+// AnomaliesOf 返回指定类型的异常
+func (r *QualityReport) AnomaliesOf(kind AnomalyKind) []Anomaly {
+	var result []Anomaly
+	for _, v := range r.Anomalies {
+		if v.Kind == kind {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// CheckQuality 检查K线数据质量，返回缺失的周期与异常K线，不修改数据。
+// 周期从第一根K线的时间开始计算，因此不要求K线与 UTC 对齐。
+func (e *Item) CheckQuality(opts QualityOptions) (*QualityReport, error) {
+	if e == nil {
+		return nil, errNilKline
+	}
+	if e.Interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	if len(e.Candles) < 2 {
+		return nil, ErrNotEnoughCandles
+	}
+
+	var seconds = int64(e.Interval.Duration().Seconds())
+	var first = e.Candles[0].TimeUnix
+	var last = first
+	for _, candle := range e.Candles {
+		if candle.TimeUnix > last {
+			last = candle.TimeUnix
+		}
+	}
+
+	var report = &QualityReport{
+		Interval: e.Interval,
+		Start:    time.Unix(first, 0),
+		End:      time.Unix(last+seconds, 0),
+		Candles:  len(e.Candles),
+		Ranges:   newIntervalRangeHolder(time.Unix(first, 0), time.Unix(last+seconds, 0), e.Interval),
+	}
+	report.Expected = int((last + seconds - first) / seconds)
+	report.Ranges.SetHasData(e.Candles)
+
+	// 缺口
+	var gap *Gap
+	for _, r := range report.Ranges.Ranges {
+		for _, v := range r.Intervals {
+			if v.HasData {
+				if gap != nil {
+					gap.End = v.Start.Time
+					report.Gaps = append(report.Gaps, *gap)
+					gap = nil
+				}
+				continue
+			}
+			report.Missing++
+			if gap == nil {
+				gap = &Gap{Start: v.Start.Time}
+			}
+			gap.Missing++
+		}
+	}
+
+	// 单根K线的异常
+	for i, candle := range e.Candles {
+		var add = func(kind AnomalyKind, value float64) {
+			report.Anomalies = append(report.Anomalies, Anomaly{Index: i, Time: time.Unix(candle.TimeUnix, 0), Kind: kind, Value: value})
+		}
+		if i > 0 {
+			switch prev := e.Candles[i-1].TimeUnix; {
+			case candle.TimeUnix == prev:
+				add(Duplicate, candle.Close)
+			case candle.TimeUnix < prev:
+				add(Unsorted, candle.Close)
+			}
+		}
+		if (candle.TimeUnix-first)%seconds != 0 {
+			add(Unaligned, candle.Close)
+		}
+		if candle.Open <= 0 || candle.High <= 0 || candle.Low <= 0 || candle.Close <= 0 {
+			add(ZeroPrice, candle.Close)
+			continue
+		}
+		if candle.High < math.Max(candle.Open, candle.Close) || candle.Low > math.Min(candle.Open, candle.Close) || candle.High < candle.Low {
+			add(InvalidOHLC, candle.Close)
+		}
+	}
+
+	if opts.Threshold > 0 {
+		report.Anomalies = append(report.Anomalies, e.outliers(opts.Threshold, seconds)...)
+	}
+	sort.SliceStable(report.Anomalies, func(i, j int) bool { return report.Anomalies[i].Index < report.Anomalies[j].Index })
+	return report, nil
+}
+
+// outliers 使用中位数绝对偏差找出涨跌幅与振幅异常的K线，价格不大于 0 的K线不参与计算，
+// 涨跌幅只在相邻两根K线之间计算，跨越缺口的不计算
+func (e *Item) outliers(threshold float64, seconds int64) []Anomaly {
+	var returns, ranges []float64
+	var returnIndex, rangeIndex []int
+	for i, candle := range e.Candles {
+		if candle.Close <= 0 || candle.Low <= 0 {
+			continue
+		}
+		ranges = append(ranges, (candle.High-candle.Low)/candle.Close)
+		rangeIndex = append(rangeIndex, i)
+		if i > 0 && e.Candles[i-1].Close > 0 && candle.TimeUnix-e.Candles[i-1].TimeUnix == seconds {
+			returns = append(returns, math.Log(candle.Close/e.Candles[i-1].Close))
+			returnIndex = append(returnIndex, i)
+		}
+	}
+
+	var result []Anomaly
+	var find = func(values []float64, index []int, kind AnomalyKind) {
+		if len(values) < 3 {
+			return
+		}
+		var median, mad = medianMAD(values)
+		if mad == 0 {
+			return
+		}
+		for x, v := range values {
+			if math.Abs(v-median)/(1.4826*mad) > threshold {
+				var i = index[x]
+				result = append(result, Anomaly{Index: i, Time: time.Unix(e.Candles[i].TimeUnix, 0), Kind: kind, Value: v})
+			}
+		}
+	}
+	find(returns, returnIndex, PriceOutlier)
+	find(ranges, rangeIndex, RangeSpike)
+	return result
+}
+
+// medianMAD 返回中位数与中位数绝对偏差
+func medianMAD(values []float64) (median, mad float64) {
+	var sorted = append([]float64{}, values...)
+	sort.Float64s(sorted)
+	median = percentile50(sorted)
+
+	var deviations = make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	sort.Float64s(deviations)
+	return median, percentile50(deviations)
+}
+
+func percentile50(sorted []float64) float64 {
+	var n = len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// Pad 从第一根K线开始按周期补齐缺失的K线，补齐的K线只有时间，价格与成交量都为 0。
+// K线需要按时间升序排列且与第一根K线对齐。
+func (e *Item) Pad() error {
+	if e == nil {
+		return errNilKline
+	}
+	if len(e.Candles) == 0 {
+		return nil
+	}
+	return e.PadRange(time.Unix(e.Candles[0].TimeUnix, 0), time.Unix(e.Candles[len(e.Candles)-1].TimeUnix, 0).Add(e.Interval.Duration()))
+}
+
+// PadRange 在 [start, exclusiveEnd) 范围内补齐缺失的K线
+func (e *Item) PadRange(start, exclusiveEnd time.Time) error {
+	return e.addPadding(start, exclusiveEnd, false)
+}
+
+// FillGaps 补齐缺失的K线并按 method 填充价格，返回补齐的数量。
+// 填充前会按时间升序排序并去掉重复的K线，补齐后的数据可以直接用于 ConvertToNewInterval。
+func (e *Item) FillGaps(method FillMethod) (int, error) {
+	if e == nil {
+		return 0, errNilKline
+	}
+	if method != FillForward && method != FillInterpolate {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownFillMethod, method)
+	}
+	if len(e.Candles) < 2 {
+		return 0, nil
+	}
+
+	e.SortCandlesByTimestamp(true)
+	e.RemoveDuplicates()
+
+	var exists = make(map[int64]bool, len(e.Candles))
+	for _, candle := range e.Candles {
+		exists[candle.TimeUnix] = true
+	}
+	if err := e.Pad(); err != nil {
+		return 0, err
+	}
+
+	var filled int
+	for i := 0; i < len(e.Candles); i++ {
+		if exists[e.Candles[i].TimeUnix] {
+			continue
+		}
+		// 连续缺口 [i, j)
+		var j = i
+		for j < len(e.Candles) && !exists[e.Candles[j].TimeUnix] {
+			j++
+		}
+		var prev = e.Candles[i-1].Close
+		var next = e.Candles[j].Close
+		for x := i; x < j; x++ {
+			var price = prev
+			if method == FillInterpolate {
+				price = prev + (next-prev)*float64(x-i+1)/float64(j-i+1)
+			}
+			var open = e.Candles[x-1].Close
+			e.Candles[x].Open = open
+			e.Candles[x].Close = price
+			e.Candles[x].High = math.Max(open, price)
+			e.Candles[x].Low = math.Min(open, price)
+			if open != 0 {
+				e.Candles[x].ChangePercent = (price - open) / open
+			}
+			e.Candles[x].IsBullMarket = price > open
+			filled++
+		}
+		i = j
+	}
+	return filled, nil
+}
+
+// newIntervalRangeHolder 从 start 开始按周期划分 [start, end)，与 CalculateCandleDateRanges 不同，start 不会被取整
+func newIntervalRangeHolder(start, end time.Time, interval Interval) *IntervalRangeHolder {
+	var count = int(end.Sub(start) / interval.Duration())
+	var r = IntervalRange{
+		Start:     CreateIntervalTime(start),
+		Intervals: make([]IntervalData, count),
+	}
+	var t = start
+	for i := range r.Intervals {
+		r.Intervals[i].Start = CreateIntervalTime(t)
+		t = t.Add(interval.Duration())
+		r.Intervals[i].End = CreateIntervalTime(t)
+	}
+	r.End = CreateIntervalTime(t)
+	return &IntervalRangeHolder{
+		Start:  r.Start,
+		End:    r.End,
+		Ranges: []IntervalRange{r},
+		Limit:  count,
+	}
+}
+
+// SetHasData 根据K线时间标记每个周期是否有数据
+func (h *IntervalRangeHolder) SetHasData(candles []*Candle) {
+	var times = make(map[int64]bool, len(candles))
+	for _, candle := range candles {
+		times[candle.TimeUnix] = true
+	}
+	for i := range h.Ranges {
+		for j := range h.Ranges[i].Intervals {
+			h.Ranges[i].Intervals[j].HasData = times[h.Ranges[i].Intervals[j].Start.Ticks]
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package klines
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// newQualityTestItem 每小时一根K线，收盘价从 100 开始每根加 1，跳过 skip 中的序号
+func newQualityTestItem(count int, skip ...int) *Item {
+	var skipped = make(map[int]bool)
+	for _, v := range skip {
+		skipped[v] = true
+	}
+	// 2023-11-15 00:00:00 +08:00，不与 UTC 日线对齐
+	var start = time.Unix(1699977600, 0)
+	var item = &Item{Interval: OneHour}
+	for i := 0; i < count; i++ {
+		if skipped[i] {
+			continue
+		}
+		var price = 100 + float64(i)
+		item.Candles = append(item.Candles, &Candle{
+			TimeUnix: start.Add(time.Duration(i) * time.Hour).Unix(),
+			Open:     price - 0.5,
+			High:     price + 0.5,
+			Low:      price - 1,
+			Close:    price,
+			Volume:   10,
+		})
+	}
+	return item
+}
+
+// RUN
+// go test -v ./utils/klines -run TestCheckQuality
+func TestCheckQuality(t *testing.T) {
+	t.Parallel()
+	var item = newQualityTestItem(30, 5, 6, 7, 20)
+	item.Candles[10].Low = 0
+	item.Candles[12].Close = 1000
+	item.Candles[12].High = 1001
+
+	report, err := item.CheckQuality(DefaultQualityOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Expected != 30 || report.Candles != 26 || report.Missing != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.Gaps) != 2 || report.Gaps[0].Missing != 3 || report.Gaps[1].Missing != 1 {
+		t.Fatalf("unexpected gaps %+v", report.Gaps)
+	}
+	if !report.Gaps[0].Start.Equal(time.Unix(item.Candles[5].TimeUnix, 0).Add(-3*time.Hour)) ||
+		!report.Gaps[0].End.Equal(time.Unix(item.Candles[5].TimeUnix, 0)) {
+		t.Fatalf("unexpected gap range %+v", report.Gaps[0])
+	}
+	if report.Ranges.HasDataAtDate(report.Gaps[0].Start) || !report.Ranges.HasDataAtDate(report.Start) {
+		t.Fatal("unexpected HasDataAtDate result")
+	}
+	if v := report.AnomaliesOf(ZeroPrice); len(v) != 1 || v[0].Index != 10 {
+		t.Fatalf("unexpected zero price anomalies %+v", v)
+	}
+	if v := report.AnomaliesOf(PriceOutlier); len(v) != 2 || v[0].Index != 12 || v[1].Index != 13 {
+		t.Fatalf("unexpected price outliers %+v", v)
+	}
+	if v := report.AnomaliesOf(InvalidOHLC); len(v) != 0 {
+		t.Fatalf("unexpected invalid candles %+v", v)
+	}
+
+	if _, err = (&Item{Interval: OneHour}).CheckQuality(DefaultQualityOptions()); !errors.Is(err, ErrNotEnoughCandles) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNotEnoughCandles)
+	}
+}
+
+// RUN
+// go test -v ./utils/klines -run TestFillGaps
+func TestFillGaps(t *testing.T) {
+	t.Parallel()
+	var item = newQualityTestItem(10, 3, 4, 5)
+	filled, err := item.FillGaps(FillForward)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled != 3 || len(item.Candles) != 10 {
+		t.Fatalf("filled %d, %d candles", filled, len(item.Candles))
+	}
+	for i := 3; i <= 5; i++ {
+		var v = item.Candles[i]
+		if v.Open != 102 || v.Close != 102 || v.High != 102 || v.Low != 102 || v.Volume != 0 {
+			t.Fatalf("[%d] unexpected forward filled candle %+v", i, v)
+		}
+	}
+
+	item = newQualityTestItem(10, 3, 4, 5)
+	if _, err = item.FillGaps(FillInterpolate); err != nil {
+		t.Fatal(err)
+	}
+	for i := 3; i <= 5; i++ {
+		if v := item.Candles[i]; math.Abs(v.Close-(100+float64(i))) > 1e-9 || v.Open != item.Candles[i-1].Close || v.Volume != 0 {
+			t.Fatalf("[%d] unexpected interpolated candle %+v", i, v)
+		}
+	}
+
+	report, err := item.CheckQuality(DefaultQualityOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Missing != 0 {
+		t.Fatalf("expected no missing candles, got %d", report.Missing)
+	}
+
+	if _, err = item.FillGaps(FillMethod(99)); !errors.Is(err, ErrUnknownFillMethod) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrUnknownFillMethod)
+	}
+
+	// 填充后可以合成为高周期
+	item = newQualityTestItem(48, 10, 11)
+	item.Candles = item.Candles[8:]
+	if _, err = item.FillGaps(FillForward); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = item.ConvertToNewInterval(FourHour); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,8 @@ package klines
 
 import (
 	"math"
+
+	"github.com/shopspring/decimal"
 )
 
 // OHLC is a connector for technical analysis usage
@@ -39,6 +41,38 @@ func (e *Item) GetOHLC() *OHLC {
 	return ohlc
 }
 
+// DecimalOHLC 使用 decimal 保存的 OHLC，用于需要精确计算的指标
+type DecimalOHLC struct {
+	Open     []decimal.Decimal
+	High     []decimal.Decimal
+	Low      []decimal.Decimal
+	Close    []decimal.Decimal
+	Volume   []decimal.Decimal
+	TimeUnix []int64
+}
+
+// GetDecimalOHLC 返回 decimal 格式的 OHLC，float64 使用能还原该值的最短十进制表示转换，
+// 从字符串读取的价格（例如 "0.1"）可以得到与原始数据一致的值
+func (e *Item) GetDecimalOHLC() *DecimalOHLC {
+	ohlc := &DecimalOHLC{
+		Open:     make([]decimal.Decimal, len(e.Candles)),
+		High:     make([]decimal.Decimal, len(e.Candles)),
+		Low:      make([]decimal.Decimal, len(e.Candles)),
+		Close:    make([]decimal.Decimal, len(e.Candles)),
+		Volume:   make([]decimal.Decimal, len(e.Candles)),
+		TimeUnix: make([]int64, len(e.Candles)),
+	}
+	for x := range e.Candles {
+		ohlc.Open[x] = decimal.NewFromFloat(e.Candles[x].Open)
+		ohlc.High[x] = decimal.NewFromFloat(e.Candles[x].High)
+		ohlc.Low[x] = decimal.NewFromFloat(e.Candles[x].Low)
+		ohlc.Close[x] = decimal.NewFromFloat(e.Candles[x].Close)
+		ohlc.Volume[x] = decimal.NewFromFloat(e.Candles[x].Volume)
+		ohlc.TimeUnix[x] = e.Candles[x].TimeUnix
+	}
+	return ohlc
+}
+
 // ToHeikinAshi 转换成平均K线
 func (e *Item) ToHeikinAshi() *Item {
 	var result = &Item{
@@ -0,0 +1,250 @@
+package loader
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// LoadCSV 从 CSV 读取K线
+//
+//	opts := loader.DefaultOptions()
+//	opts.Columns.Time = "date"
+//	opts.TimeFormat = "2006-01-02"
+//	opts.Location, _ = time.LoadLocation("Asia/Shanghai")
+//	item, err := loader.LoadCSV(file, opts)
+func LoadCSV(r io.Reader, opts Options) (*klines.Item, error) {
+	var reader = csv.NewReader(r)
+	reader.Comma = opts.Comma
+	if reader.Comma == 0 {
+		reader.Comma = ','
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var indexes map[string]int
+	var line int
+	if !opts.NoHeader {
+		header, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrNoData
+			}
+			return nil, err
+		}
+		line++
+		indexes = make(map[string]int, len(header))
+		for i, name := range header {
+			// 去掉可能存在的 UTF-8 BOM
+			name = strings.TrimPrefix(name, "\ufeff")
+			indexes[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+	}
+
+	columns, err := resolveColumns(opts.Columns, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []*klines.Candle
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		candle, err := columns.candle(record, opts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		candles = append(candles, candle)
+	}
+
+	return newItem(candles, opts)
+}
+
+// csvColumns 每个字段所在的列序号，-1 表示不读取
+type csvColumns struct {
+	time, open, high, low, close, volume, amount, count int
+}
+
+func resolveColumns(columns Columns, indexes map[string]int) (*csvColumns, error) {
+	if columns == (Columns{}) {
+		columns = DefaultColumns()
+	}
+	if indexes == nil && columns == DefaultColumns() {
+		// 没有表头时默认按 time,open,high,low,close,volume,amount,count 的顺序
+		columns = Columns{Time: "0", Open: "1", High: "2", Low: "3", Close: "4", Volume: "5", Amount: "6", Count: "7"}
+	}
+
+	var find = func(name string, required bool) (int, error) {
+		if name == "" {
+			if required {
+				return -1, ErrMissingColumn
+			}
+			return -1, nil
+		}
+		if indexes == nil {
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 {
+				return -1, fmt.Errorf("%w: %q is not a column index", ErrMissingColumn, name)
+			}
+			return index, nil
+		}
+		if index, ok := indexes[strings.ToLower(name)]; ok {
+			return index, nil
+		}
+		if required {
+			return -1, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+		}
+		return -1, nil
+	}
+
+	var result = &csvColumns{}
+	var err error
+	var fields = []struct {
+		name     string
+		required bool
+		index    *int
+	}{
+		{columns.Time, true, &result.time},
+		{columns.Open, true, &result.open},
+		{columns.High, true, &result.high},
+		{columns.Low, true, &result.low},
+		{columns.Close, true, &result.close},
+		{columns.Volume, false, &result.volume},
+		{columns.Amount, false, &result.amount},
+		{columns.Count, false, &result.count},
+	}
+	for _, field := range fields {
+		if *field.index, err = find(field.name, field.required); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e *csvColumns) candle(record []string, opts Options) (*klines.Candle, error) {
+	var value = func(index int) (string, bool) {
+		if index < 0 || index >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[index]), true
+	}
+	var float = func(index int, required bool) (float64, error) {
+		raw, ok := value(index)
+		if !ok || raw == "" {
+			if required {
+				return 0, fmt.Errorf("%w: index %d", ErrMissingColumn, index)
+			}
+			return 0, nil
+		}
+		return strconv.ParseFloat(raw, 64)
+	}
+
+	var candle = &klines.Candle{}
+	raw, ok := value(e.time)
+	if !ok {
+		return nil, fmt.Errorf("%w: index %d", ErrMissingColumn, e.time)
+	}
+	var err error
+	if candle.TimeUnix, err = parseTime(raw, opts.TimeFormat, opts.Location); err != nil {
+		return nil, err
+	}
+	if candle.Open, err = float(e.open, true); err != nil {
+		return nil, err
+	}
+	if candle.High, err = float(e.high, true); err != nil {
+		return nil, err
+	}
+	if candle.Low, err = float(e.low, true); err != nil {
+		return nil, err
+	}
+	if candle.Close, err = float(e.close, true); err != nil {
+		return nil, err
+	}
+	if candle.Volume, err = float(e.volume, false); err != nil {
+		return nil, err
+	}
+	if candle.Amount, err = float(e.amount, false); err != nil {
+		return nil, err
+	}
+	count, err := float(e.count, false)
+	if err != nil {
+		return nil, err
+	}
+	candle.Count = int64(count)
+
+	fillCandle(candle)
+	return candle, nil
+}
+
+// 自动识别时间时依次尝试的 layout
+var autoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102",
+}
+
+// parseTime 按格式把时间字符串转换为 Unix 秒
+func parseTime(raw, format string, location *time.Location) (int64, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	switch format {
+	case TimeUnix:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, err
+		}
+		return int64(value), nil
+	case TimeUnixMilli:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, err
+		}
+		return int64(value) / 1000, nil
+	case TimeAuto:
+		// 8 位纯数字视为 20060102 格式的日期
+		if value, err := strconv.ParseFloat(raw, 64); err == nil && len(raw) != 8 {
+			return unixFromNumber(value), nil
+		}
+		for _, layout := range autoLayouts {
+			if t, err := time.ParseInLocation(layout, raw, location); err == nil {
+				return t.Unix(), nil
+			}
+		}
+		return 0, fmt.Errorf("loader: unable to parse time %q", raw)
+	default:
+		t, err := time.ParseInLocation(format, raw, location)
+		if err != nil {
+			return 0, err
+		}
+		return t.Unix(), nil
+	}
+}
+
+// unixFromNumber 数字小于 1e11 按秒，否则按毫秒
+func unixFromNumber(value float64) int64 {
+	if math.Abs(value) < 1e11 {
+		return int64(value)
+	}
+	return int64(value) / 1000
+}
@@ -0,0 +1,172 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/idoall/stockindicator/utils/commonutils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// LoadJSON 从 Candle 组成的 JSON 数组读取K线，字段与 klines.Candle 的 json tag 一致，数组中有 null 时返回 ErrNullCandle
+func LoadJSON(r io.Reader, opts Options) (*klines.Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []*klines.Candle
+	if err = commonutils.JSONDecode(data, &candles); err != nil {
+		return nil, err
+	}
+	for i, candle := range candles {
+		if candle == nil {
+			return nil, fmt.Errorf("candle %d: %w", i, ErrNullCandle)
+		}
+		fillCandle(candle)
+	}
+	return newItem(candles, opts)
+}
+
+// LoadJSONLines 从 JSON Lines 读取K线，每行一个 Candle，忽略空行，null 行返回 ErrNullCandle
+func LoadJSONLines(r io.Reader, opts Options) (*klines.Item, error) {
+	var scanner = bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var candles []*klines.Candle
+	var line int
+	for scanner.Scan() {
+		line++
+		var data = bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var candle *klines.Candle
+		if err := commonutils.JSONDecode(data, &candle); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if candle == nil {
+			return nil, fmt.Errorf("line %d: %w", line, ErrNullCandle)
+		}
+		fillCandle(candle)
+		candles = append(candles, candle)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newItem(candles, opts)
+}
+
+// LoadColumnar 从按列存储的 JSON 对象读取K线，列名由 opts.Columns 指定，匹配时不区分大小写
+//
+//	{"time":[1700000000,1700001800],"open":[1,2],"high":[1,2],"low":[1,2],"close":[1,2]}
+func LoadColumnar(r io.Reader, opts Options) (*klines.Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string][]interface{}
+	if err = commonutils.JSONDecode(data, &raw); err != nil {
+		return nil, err
+	}
+	var table = make(map[string][]interface{}, len(raw))
+	for k, v := range raw {
+		table[strings.ToLower(k)] = v
+	}
+
+	var columns = opts.Columns
+	if columns == (Columns{}) {
+		columns = DefaultColumns()
+	}
+	var column = func(name string, required bool) ([]interface{}, error) {
+		values, ok := table[strings.ToLower(name)]
+		if name == "" || !ok {
+			if required {
+				return nil, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+			}
+			return nil, nil
+		}
+		return values, nil
+	}
+
+	times, err := column(columns.Time, true)
+	if err != nil {
+		return nil, err
+	}
+	var candles = make([]*klines.Candle, len(times))
+	for i, v := range times {
+		candles[i] = &klines.Candle{}
+		switch t := v.(type) {
+		case float64:
+			switch opts.TimeFormat {
+			case TimeUnix:
+				candles[i].TimeUnix = int64(t)
+			case TimeUnixMilli:
+				candles[i].TimeUnix = int64(t) / 1000
+			default:
+				candles[i].TimeUnix = unixFromNumber(t)
+			}
+		case string:
+			if candles[i].TimeUnix, err = parseTime(t, opts.TimeFormat, opts.Location); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("row %d: unsupported time value %v", i, v)
+		}
+	}
+
+	var fields = []struct {
+		name     string
+		required bool
+		set      func(candle *klines.Candle, value float64)
+	}{
+		{columns.Open, true, func(c *klines.Candle, v float64) { c.Open = v }},
+		{columns.High, true, func(c *klines.Candle, v float64) { c.High = v }},
+		{columns.Low, true, func(c *klines.Candle, v float64) { c.Low = v }},
+		{columns.Close, true, func(c *klines.Candle, v float64) { c.Close = v }},
+		{columns.Volume, false, func(c *klines.Candle, v float64) { c.Volume = v }},
+		{columns.Amount, false, func(c *klines.Candle, v float64) { c.Amount = v }},
+		{columns.Count, false, func(c *klines.Candle, v float64) { c.Count = int64(v) }},
+	}
+	for _, field := range fields {
+		values, err := column(field.name, field.required)
+		if err != nil {
+			return nil, err
+		}
+		if values == nil {
+			continue
+		}
+		if len(values) != len(candles) {
+			return nil, fmt.Errorf("loader: column %s has %d values, expected %d", field.name, len(values), len(candles))
+		}
+		for i, v := range values {
+			value, err := columnarFloat(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d column %s: %w", i, field.name, err)
+			}
+			field.set(candles[i], value)
+		}
+	}
+
+	for _, candle := range candles {
+		fillCandle(candle)
+	}
+	return newItem(candles, opts)
+}
+
+// columnarFloat 数值可以是数字或数字字符串
+func columnarFloat(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case float64:
+		return value, nil
+	case nil:
+		return 0, nil
+	default:
+		return commonutils.FloatFromString(value)
+	}
+}
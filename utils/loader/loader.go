@@ -0,0 +1,254 @@
+package loader
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoData 没有读取到K线
+	ErrNoData = errors.New("loader: no candle data")
+	// ErrNotSorted 严格模式下K线未按时间升序排列
+	ErrNotSorted = errors.New("loader: candles are not sorted by time")
+	// ErrDuplicate 严格模式下存在时间重复的K线
+	ErrDuplicate = errors.New("loader: duplicate candle time")
+	// ErrMissingColumn CSV 中找不到需要的列
+	ErrMissingColumn = errors.New("loader: missing column")
+	// ErrUnknownFormat 无法根据文件扩展名判断格式
+	ErrUnknownFormat = errors.New("loader: unknown file format")
+	// ErrNullCandle JSON 中的K线为 null
+	ErrNullCandle = errors.New("loader: candle is null")
+)
+
+// Format 文件格式
+type Format uint32
+
+const (
+	// FormatCSV CSV 文件
+	FormatCSV Format = iota
+	// FormatJSON Candle 组成的 JSON 数组
+	FormatJSON
+	// FormatJSONLines 每行一个 Candle 的 JSON Lines
+	FormatJSONLines
+	// FormatColumnar 按列存储的 JSON 对象，例如 {"time":[...],"open":[...]}
+	FormatColumnar
+)
+
+// 时间格式，TimeFormat 也可以直接使用 Go 的时间 layout，例如 "2006-01-02 15:04:05"
+const (
+	// TimeAuto 自动识别，整数小于 1e11 按秒，否则按毫秒；字符串依次尝试 RFC3339、"2006-01-02 15:04:05"、"2006-01-02"
+	TimeAuto = ""
+	// TimeUnix Unix 秒
+	TimeUnix = "unix"
+	// TimeUnixMilli Unix 毫秒
+	TimeUnixMilli = "unixms"
+)
+
+// Columns 每个字段对应的列名，留空的字段不读取，Time、Open、High、Low、Close 为必填列。
+// CSV 没有表头时，列名为从 0 开始的列序号。
+type Columns struct {
+	Time   string
+	Open   string
+	High   string
+	Low    string
+	Close  string
+	Volume string
+	Amount string
+	Count  string
+}
+
+// DefaultColumns 默认的列名，匹配时不区分大小写
+func DefaultColumns() Columns {
+	return Columns{
+		Time:   "time",
+		Open:   "open",
+		High:   "high",
+		Low:    "low",
+		Close:  "close",
+		Volume: "volume",
+		Amount: "amount",
+		Count:  "count",
+	}
+}
+
+// Options 读取选项
+type Options struct {
+	Exchange string
+	Symbol   string
+	Code     string
+	// K线周期，为 0 时根据相邻K线的最小时间间隔推算
+	Interval klines.Interval
+	// 严格模式，数据未按时间升序排列或存在重复时返回错误，否则按时间稳定排序并去重，
+	// 时间相同的K线保留数据中最后出现的一根
+	Strict bool
+
+	// 以下选项用于 CSV 与按列存储的 JSON
+	Columns Columns
+	// 时间格式，见 TimeAuto、TimeUnix、TimeUnixMilli
+	TimeFormat string
+	// 解析不带时区的时间字符串时使用的时区，默认为 UTC
+	Location *time.Location
+	// CSV 分隔符，默认为 ','
+	Comma rune
+	// CSV 没有表头
+	NoHeader bool
+}
+
+// DefaultOptions 默认选项
+func DefaultOptions() Options {
+	return Options{
+		Columns:  DefaultColumns(),
+		Location: time.UTC,
+		Comma:    ',',
+	}
+}
+
+// Load 按格式从 r 读取K线，gzip 压缩的数据会自动解压
+func Load(r io.Reader, format Format, opts Options) (*klines.Item, error) {
+	reader, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		return LoadCSV(reader, opts)
+	case FormatJSON:
+		return LoadJSON(reader, opts)
+	case FormatJSONLines:
+		return LoadJSONLines(reader, opts)
+	case FormatColumnar:
+		return LoadColumnar(reader, opts)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// LoadFile 根据扩展名读取文件，支持 .csv、.json、.jsonl、.ndjson、.columnar.json 以及对应的 .gz 压缩文件
+func LoadFile(path string, opts Options) (*klines.Item, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	item, err := Load(file, format, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return item, nil
+}
+
+// FormatFromPath 根据文件扩展名判断格式
+func FormatFromPath(path string) (Format, error) {
+	var name = strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".gz")
+	switch {
+	case strings.HasSuffix(name, ".columnar.json"):
+		return FormatColumnar, nil
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV, nil
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+		return FormatJSONLines, nil
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+}
+
+// decompress 根据 gzip 文件头判断是否需要解压
+func decompress(r io.Reader) (io.Reader, error) {
+	var reader = bufio.NewReader(r)
+	header, err := reader.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(header) == 2 && header[0] == 0x1f && header[1] == 0x8b {
+		return gzip.NewReader(reader)
+	}
+	return reader, nil
+}
+
+// newItem 校验并生成 klines.Item
+func newItem(candles []*klines.Candle, opts Options) (*klines.Item, error) {
+	if len(candles) == 0 {
+		return nil, ErrNoData
+	}
+
+	var item = &klines.Item{
+		Exchange: opts.Exchange,
+		Symbol:   opts.Symbol,
+		Code:     opts.Code,
+		Interval: opts.Interval,
+		Candles:  candles,
+	}
+
+	for i := 1; i < len(candles); i++ {
+		if candles[i].TimeUnix > candles[i-1].TimeUnix {
+			continue
+		}
+		if opts.Strict {
+			if candles[i].TimeUnix == candles[i-1].TimeUnix {
+				return nil, fmt.Errorf("%w: %d at index %d", ErrDuplicate, candles[i].TimeUnix, i)
+			}
+			return nil, fmt.Errorf("%w: index %d", ErrNotSorted, i)
+		}
+		item.Candles = sortCandles(candles)
+		break
+	}
+
+	if item.Interval == 0 {
+		item.Interval = inferInterval(item.Candles)
+	}
+	return item, nil
+}
+
+// sortCandles 按时间稳定排序，时间相同时保留最后出现的K线
+func sortCandles(candles []*klines.Candle) []*klines.Candle {
+	sort.SliceStable(candles, func(i, j int) bool { return candles[i].TimeUnix < candles[j].TimeUnix })
+	var target int
+	for i, candle := range candles {
+		if i+1 < len(candles) && candles[i+1].TimeUnix == candle.TimeUnix {
+			continue
+		}
+		candles[target] = candle
+		target++
+	}
+	return candles[:target]
+}
+
+// inferInterval 取相邻K线的最小时间间隔作为周期
+func inferInterval(candles []*klines.Candle) klines.Interval {
+	var min int64
+	for i := 1; i < len(candles); i++ {
+		if diff := candles[i].TimeUnix - candles[i-1].TimeUnix; diff > 0 && (min == 0 || diff < min) {
+			min = diff
+		}
+	}
+	return klines.Interval(time.Duration(min) * time.Second)
+}
+
+// fillCandle 补全涨跌幅与阳线标记
+func fillCandle(candle *klines.Candle) {
+	if candle.Open != 0 && candle.ChangePercent == 0 {
+		candle.ChangePercent = (candle.Close - candle.Open) / candle.Open
+	}
+	if candle.Close > candle.Open {
+		candle.IsBullMarket = true
+	}
+}
@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./utils/loader -run TestLoadCSV
+func TestLoadCSV(t *testing.T) {
+	t.Parallel()
+	var data = "Date;Open;High;Low;Close;Vol\n" +
+		"2024-01-03;11;13;10;12;200\n" +
+		"2024-01-02;10;12;9;11;100\n" +
+		"2024-01-03;11;13;10;12;200\n"
+
+	shanghai := time.FixedZone("CST", 8*3600)
+	var opts = DefaultOptions()
+	opts.Comma = ';'
+	opts.Columns.Time = "date"
+	opts.Columns.Volume = "vol"
+	opts.TimeFormat = "2006-01-02"
+	opts.Location = shanghai
+
+	item, err := LoadCSV(strings.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(item.Candles) != 2 {
+		t.Fatalf("expected 2 candles, got %d", len(item.Candles))
+	}
+	if item.Interval != klines.OneDay {
+		t.Fatalf("expected interval %s, got %s", klines.OneDay, item.Interval)
+	}
+	var first = item.Candles[0]
+	if first.TimeUnix != time.Date(2024, 1, 2, 0, 0, 0, 0, shanghai).Unix() {
+		t.Fatalf("unexpected time %d", first.TimeUnix)
+	}
+	if first.Close != 11 || first.Volume != 100 || !first.IsBullMarket {
+		t.Fatalf("unexpected candle %+v", first)
+	}
+
+	opts.Strict = true
+	if _, err = LoadCSV(strings.NewReader(data), opts); !errors.Is(err, ErrNotSorted) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNotSorted)
+	}
+
+	opts.Columns.Close = "last"
+	if _, err = LoadCSV(strings.NewReader(data), opts); !errors.Is(err, ErrMissingColumn) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrMissingColumn)
+	}
+}
+
+// RUN
+// go test -v ./utils/loader -run TestLoadCSVNoHeader
+func TestLoadCSVNoHeader(t *testing.T) {
+	t.Parallel()
+	var data = "1700000000000,1,2,0.5,1.5,10\n1700001800000,1.5,2,1,1,20\n"
+	var opts = DefaultOptions()
+	opts.NoHeader = true
+	opts.Strict = true
+
+	item, err := LoadCSV(strings.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Candles[1].TimeUnix != 1700001800 || item.Interval != klines.ThirtyMin {
+		t.Fatalf("unexpected item %+v", item.Candles[1])
+	}
+
+	if _, err = LoadCSV(strings.NewReader(data+"1700001800000,1,1,1,1,1\n"), opts); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrDuplicate)
+	}
+}
+
+// RUN
+// go test -v ./utils/loader -run TestLoadCSVDuplicate
+func TestLoadCSVDuplicate(t *testing.T) {
+	t.Parallel()
+	// 时间相同但数据不同的K线，保留最后出现的一根
+	var data = "1700001800000,1,1,1,1,1\n" +
+		"1700003600000,2,2,2,2,2\n" +
+		"1700000000000,3,3,3,3,3\n" +
+		"1700001800000,4,4,4,4,4\n" +
+		"1700001800000,5,5,5,5,5\n"
+	var opts = DefaultOptions()
+	opts.NoHeader = true
+
+	item, err := LoadCSV(strings.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(item.Candles) != 3 {
+		t.Fatalf("expected 3 candles, got %d", len(item.Candles))
+	}
+	for i, v := range []float64{3, 5, 2} {
+		if item.Candles[i].Close != v {
+			t.Fatalf("[%d] received %f expected %f", i, item.Candles[i].Close, v)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils/loader -run TestLoadJSON
+func TestLoadJSON(t *testing.T) {
+	t.Parallel()
+	var array = `[{"TimeUnix":1700000000,"Open":1,"High":2,"Low":1,"Close":2},{"TimeUnix":1700001800,"Open":2,"High":3,"Low":2,"Close":3}]`
+	var lines = "{\"TimeUnix\":1700000000,\"Open\":1,\"High\":2,\"Low\":1,\"Close\":2}\n\n" +
+		"{\"TimeUnix\":1700001800,\"Open\":2,\"High\":3,\"Low\":2,\"Close\":3}\n"
+	var columnar = `{"Time":["2023-11-14T22:13:20Z","2023-11-14T22:43:20Z"],"open":[1,2],"high":[2,3],"low":[1,2],"close":["2","3"]}`
+
+	for format, data := range map[Format]string{
+		FormatJSON:      array,
+		FormatJSONLines: lines,
+		FormatColumnar:  columnar,
+	} {
+		item, err := Load(strings.NewReader(data), format, DefaultOptions())
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if len(item.Candles) != 2 || item.Candles[1].TimeUnix != 1700001800 || item.Candles[1].Close != 3 {
+			t.Fatalf("format %d: unexpected candles %+v", format, item.Candles)
+		}
+	}
+
+	if _, err := LoadJSON(strings.NewReader("[]"), DefaultOptions()); !errors.Is(err, ErrNoData) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNoData)
+	}
+	if _, err := LoadJSON(strings.NewReader(`[{"TimeUnix":1700000000,"Close":1}, null]`), DefaultOptions()); !errors.Is(err, ErrNullCandle) || !strings.Contains(err.Error(), "candle 1") {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNullCandle)
+	}
+	if _, err := LoadJSONLines(strings.NewReader("{\"TimeUnix\":1700000000,\"Close\":1}\nnull\n"), DefaultOptions()); !errors.Is(err, ErrNullCandle) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNullCandle)
+	}
+}
+
+// RUN
+// go test -v ./utils/loader -run TestLoadFile
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	var writer = gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte("time,open,high,low,close\n1700000000,1,2,1,2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var path = filepath.Join(t.TempDir(), "test.csv.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var opts = DefaultOptions()
+	opts.Interval = klines.OneHour
+	item, err := LoadFile(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(item.Candles) != 1 || item.Interval != klines.OneHour {
+		t.Fatalf("unexpected item %+v", item)
+	}
+
+	if _, err = LoadFile("test.parquet", opts); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrUnknownFormat)
+	}
+}
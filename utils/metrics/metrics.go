@@ -0,0 +1,461 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoReturns 收益率序列为空
+	ErrNoReturns = errors.New("metrics: no returns")
+	// ErrNotAligned 与基准没有相同时间的收益率
+	ErrNotAligned = errors.New("metrics: no returns aligned to the benchmark")
+)
+
+// Series 每个周期的简单收益率，Times 为每个收益率所在周期的时间，可以为空。
+// 年化时一年的周期数量由 Interval 计算，Interval 未知时不做年化。
+type Series struct {
+	Returns  []float64
+	Times    []time.Time
+	Interval klines.Interval
+}
+
+// NewSeries 使用收益率序列，例如 0.01 表示上涨 1%
+func NewSeries(returns []float64, interval klines.Interval) *Series {
+	return &Series{Returns: returns, Interval: interval}
+}
+
+// NewEquitySeries 使用权益（净值）序列，第一个值为初始权益，收益率比权益少一个
+func NewEquitySeries(equity []float64, interval klines.Interval) *Series {
+	var returns = make([]float64, max(len(equity)-1, 0))
+	for i := range returns {
+		if equity[i] != 0 {
+			returns[i] = equity[i+1]/equity[i] - 1
+		}
+	}
+	return NewSeries(returns, interval)
+}
+
+// NewItemSeries 使用K线收盘价，收益率的时间为后一根K线的时间
+func NewItemSeries(klineItem *klines.Item) *Series {
+	var closes = make([]float64, len(klineItem.Candles))
+	for i, candle := range klineItem.Candles {
+		closes[i] = candle.Close
+	}
+	var s = NewEquitySeries(closes, klineItem.Interval)
+	s.Times = make([]time.Time, len(s.Returns))
+	for i := range s.Times {
+		s.Times[i] = time.Unix(klineItem.Candles[i+1].TimeUnix, 0)
+	}
+	return s
+}
+
+// PeriodsPerYear 根据K线周期计算一年的周期数量，周期未知时返回 1，即不做年化
+func PeriodsPerYear(interval klines.Interval) float64 {
+	if interval <= 0 {
+		return 1
+	}
+	return float64(klines.OneYear) / float64(interval)
+}
+
+// Periods 一年的周期数量
+func (s *Series) Periods() float64 {
+	return PeriodsPerYear(s.Interval)
+}
+
+// TotalReturn 累计收益率
+func (s *Series) TotalReturn() float64 {
+	var growth = 1.0
+	for _, r := range s.Returns {
+		growth *= 1 + r
+	}
+	return growth - 1
+}
+
+// CAGR 年化复合收益率，权益归零时为 -1
+func (s *Series) CAGR() float64 {
+	if len(s.Returns) == 0 {
+		return 0
+	}
+	var growth = 1 + s.TotalReturn()
+	if growth <= 0 {
+		return -1
+	}
+	return math.Pow(growth, s.Periods()/float64(len(s.Returns))) - 1
+}
+
+// Volatility 年化波动率，收益率的样本标准差乘以一年周期数量的平方根
+func (s *Series) Volatility() float64 {
+	if len(s.Returns) < 2 {
+		return 0
+	}
+	var _, std = meanStd(s.Returns)
+	return std * math.Sqrt(s.Periods())
+}
+
+// Sharpe 年化 Sharpe 比率，riskFree 为年化无风险利率，按周期数量平均分摊到每个周期
+func (s *Series) Sharpe(riskFree float64) float64 {
+	if len(s.Returns) < 2 {
+		return 0
+	}
+	var periods = s.Periods()
+	var mean, std = meanStd(s.Returns)
+	if std == 0 {
+		return 0
+	}
+	return (mean - riskFree/periods) / std * math.Sqrt(periods)
+}
+
+// Sortino 年化 Sortino 比率，下行偏差只计算低于无风险利率的收益率，分母为全部周期数量
+func (s *Series) Sortino(riskFree float64) float64 {
+	if len(s.Returns) < 2 {
+		return 0
+	}
+	var periods = s.Periods()
+	var target = riskFree / periods
+	var mean, _ = meanStd(s.Returns)
+	var downside float64
+	for _, r := range s.Returns {
+		if r < target {
+			downside += (r - target) * (r - target)
+		}
+	}
+	downside = math.Sqrt(downside / float64(len(s.Returns)))
+	if downside == 0 {
+		return 0
+	}
+	return (mean - target) / downside * math.Sqrt(periods)
+}
+
+// Calmar 年化复合收益率 / 最大回撤，没有回撤时为 0
+func (s *Series) Calmar() float64 {
+	var drawdown = s.MaxDrawdown()
+	if drawdown.Depth == 0 {
+		return 0
+	}
+	return s.CAGR() / drawdown.Depth
+}
+
+// Omega 高于门槛的收益合计 / 低于门槛的损失合计，threshold 为年化收益率，按周期数量平均分摊到每个周期。
+// 没有损失但有收益时为 +Inf
+func (s *Series) Omega(threshold float64) float64 {
+	var target = threshold / s.Periods()
+	var gain, loss float64
+	for _, r := range s.Returns {
+		if r > target {
+			gain += r - target
+		} else {
+			loss += target - r
+		}
+	}
+	if loss == 0 {
+		if gain > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return gain / loss
+}
+
+// Drawdown 一次回撤，序号为 Returns 的序号，-1 表示第一个收益率之前的初始权益
+type Drawdown struct {
+	// 回撤比例，0.2 表示从峰值下跌 20%
+	Depth float64
+	// 峰值、谷底与恢复到峰值的序号，没有恢复时 Recovery 为 -1
+	Peak     int
+	Trough   int
+	Recovery int
+	// 从峰值到恢复的周期数量，没有恢复时计算到最后一个周期
+	Duration int
+	// 从谷底到恢复的周期数量，没有恢复时为 -1
+	RecoveryPeriods int
+}
+
+// MaxDrawdown 最大回撤，深度相同时取最早的一次
+func (s *Series) MaxDrawdown() Drawdown {
+	var result = Drawdown{Peak: -1, Trough: -1, Recovery: -1, RecoveryPeriods: -1}
+	var wealth, peakWealth = 1.0, 1.0
+	var peak = -1
+	for i, r := range s.Returns {
+		wealth *= 1 + r
+		if wealth >= peakWealth {
+			if result.Peak == peak && result.Depth > 0 && result.Recovery < 0 {
+				result.Recovery = i
+			}
+			peakWealth, peak = wealth, i
+			continue
+		}
+		if depth := 1 - wealth/peakWealth; depth > result.Depth {
+			result = Drawdown{Depth: depth, Peak: peak, Trough: i, Recovery: -1, RecoveryPeriods: -1}
+		}
+	}
+	if result.Depth == 0 {
+		return Drawdown{Peak: -1, Trough: -1, Recovery: -1, RecoveryPeriods: -1}
+	}
+	if result.Recovery >= 0 {
+		result.Duration = result.Recovery - result.Peak
+		result.RecoveryPeriods = result.Recovery - result.Trough
+	} else {
+		result.Duration = len(s.Returns) - 1 - result.Peak
+	}
+	return result
+}
+
+// VaRMethod VaR 与 CVaR 的计算方式
+type VaRMethod int
+
+const (
+	// Historical 历史模拟，使用收益率的经验分位数
+	Historical VaRMethod = iota
+	// Parametric 参数法，假设收益率服从正态分布
+	Parametric
+	// CornishFisher 使用偏度与超额峰度修正正态分布的分位数
+	CornishFisher
+)
+
+// String Func
+func (m VaRMethod) String() string {
+	switch m {
+	case Historical:
+		return "Historical"
+	case Parametric:
+		return "Parametric"
+	case CornishFisher:
+		return "CornishFisher"
+	}
+	return "Unknown"
+}
+
+// VaR 单个周期的风险价值，confidence 为置信度（例如 0.95），返回正数表示损失比例
+func (s *Series) VaR(confidence float64, method VaRMethod) float64 {
+	if len(s.Returns) < 2 {
+		return 0
+	}
+	var alpha = 1 - confidence
+	if method == Historical {
+		return -quantile(sorted(s.Returns), alpha)
+	}
+	var mean, std = meanStd(s.Returns)
+	return -(mean + std*s.z(alpha, method))
+}
+
+// CVaR 单个周期的条件风险价值（Expected Shortfall），损失超过 VaR 时的平均损失，返回正数表示损失比例
+func (s *Series) CVaR(confidence float64, method VaRMethod) float64 {
+	if len(s.Returns) < 2 {
+		return 0
+	}
+	var alpha = 1 - confidence
+	var mean, std = meanStd(s.Returns)
+	switch method {
+	case Historical:
+		var values = sorted(s.Returns)
+		var cutoff = quantile(values, alpha)
+		var sum float64
+		var count int
+		for _, r := range values {
+			if r > cutoff {
+				break
+			}
+			sum += r
+			count++
+		}
+		return -sum / float64(count)
+	case Parametric:
+		var z = normInv(alpha)
+		return -(mean - std*normPdf(z)/alpha)
+	}
+	// Cornish-Fisher 分位数没有简单的积分形式，在 (0, alpha) 上按中点法数值积分
+	const steps = 1000
+	var sum float64
+	for i := 0; i < steps; i++ {
+		sum += s.z(alpha*(float64(i)+0.5)/steps, CornishFisher)
+	}
+	return -(mean + std*sum/steps)
+}
+
+// z 返回概率 p 对应的标准化分位数
+func (s *Series) z(p float64, method VaRMethod) float64 {
+	var z = normInv(p)
+	if method != CornishFisher {
+		return z
+	}
+	var skew, kurt = s.Skewness(), s.Kurtosis()
+	return z + (z*z-1)*skew/6 + (z*z*z-3*z)*kurt/24 - (2*z*z*z-5*z)*skew*skew/36
+}
+
+// TailRatio 95% 分位数的绝对值 / 5% 分位数的绝对值，右尾越厚值越大
+func (s *Series) TailRatio() float64 {
+	if len(s.Returns) == 0 {
+		return 0
+	}
+	var values = sorted(s.Returns)
+	var left = math.Abs(quantile(values, 0.05))
+	if left == 0 {
+		return 0
+	}
+	return math.Abs(quantile(values, 0.95)) / left
+}
+
+// Skewness 偏度，使用总体矩 m3 / m2^1.5
+func (s *Series) Skewness() float64 {
+	var m2, m3, _ = moments(s.Returns)
+	if m2 == 0 {
+		return 0
+	}
+	return m3 / math.Pow(m2, 1.5)
+}
+
+// Kurtosis 超额峰度，使用总体矩 m4 / m2^2 - 3，正态分布为 0
+func (s *Series) Kurtosis() float64 {
+	var m2, _, m4 = moments(s.Returns)
+	if m2 == 0 {
+		return 0
+	}
+	return m4/(m2*m2) - 3
+}
+
+// Beta 相对基准的 Beta，收益率按时间对齐，没有时间时按最后一个收益率右对齐
+func (s *Series) Beta(benchmark *Series) (float64, error) {
+	var returns, bench, err = s.align(benchmark)
+	if err != nil {
+		return 0, err
+	}
+	return beta(returns, bench), nil
+}
+
+// Alpha 相对基准的年化 Jensen's Alpha，riskFree 为年化无风险利率
+func (s *Series) Alpha(benchmark *Series, riskFree float64) (float64, error) {
+	var returns, bench, err = s.align(benchmark)
+	if err != nil {
+		return 0, err
+	}
+	var periods = s.Periods()
+	var rf = riskFree / periods
+	var b = beta(returns, bench)
+	var mean, _ = meanStd(returns)
+	var benchMean, _ = meanStd(bench)
+	return (mean - rf - b*(benchMean-rf)) * periods, nil
+}
+
+// InformationRatio 超额收益的年化平均值 / 年化跟踪误差
+func (s *Series) InformationRatio(benchmark *Series) (float64, error) {
+	var returns, bench, err = s.align(benchmark)
+	if err != nil {
+		return 0, err
+	}
+	var active = make([]float64, len(returns))
+	for i := range returns {
+		active[i] = returns[i] - bench[i]
+	}
+	return NewSeries(active, s.Interval).Sharpe(0), nil
+}
+
+// align 返回与基准对齐的两组收益率
+func (s *Series) align(benchmark *Series) (returns, bench []float64, err error) {
+	if len(s.Returns) == 0 || benchmark == nil || len(benchmark.Returns) == 0 {
+		return nil, nil, ErrNoReturns
+	}
+	if len(s.Times) != len(s.Returns) || len(benchmark.Times) != len(benchmark.Returns) {
+		var n = min(len(s.Returns), len(benchmark.Returns))
+		return s.Returns[len(s.Returns)-n:], benchmark.Returns[len(benchmark.Returns)-n:], nil
+	}
+	var index = make(map[int64]int, len(benchmark.Times))
+	for i, t := range benchmark.Times {
+		index[t.Unix()] = i
+	}
+	for i, t := range s.Times {
+		if j, ok := index[t.Unix()]; ok {
+			returns = append(returns, s.Returns[i])
+			bench = append(bench, benchmark.Returns[j])
+		}
+	}
+	if len(returns) == 0 {
+		return nil, nil, ErrNotAligned
+	}
+	return returns, bench, nil
+}
+
+func beta(returns, bench []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean, _ = meanStd(returns)
+	var benchMean, _ = meanStd(bench)
+	var cov, variance float64
+	for i := range returns {
+		cov += (returns[i] - mean) * (bench[i] - benchMean)
+		variance += (bench[i] - benchMean) * (bench[i] - benchMean)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return cov / variance
+}
+
+// meanStd 返回平均值与样本标准差
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	std = math.Sqrt(std / float64(len(values)-1))
+	return mean, std
+}
+
+// moments 返回二阶、三阶、四阶中心矩
+func moments(values []float64) (m2, m3, m4 float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+	var mean, _ = meanStd(values)
+	for _, v := range values {
+		var d = v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	var n = float64(len(values))
+	return m2 / n, m3 / n, m4 / n
+}
+
+func sorted(values []float64) []float64 {
+	var result = append([]float64(nil), values...)
+	sort.Float64s(result)
+	return result
+}
+
+// quantile 已排序数据的 p 分位数，相邻两个值之间线性插值
+func quantile(values []float64, p float64) float64 {
+	var pos = p * float64(len(values)-1)
+	var lower = int(math.Floor(pos))
+	if lower >= len(values)-1 {
+		return values[len(values)-1]
+	}
+	if lower < 0 {
+		return values[0]
+	}
+	return values[lower] + (pos-float64(lower))*(values[lower+1]-values[lower])
+}
+
+// normInv 标准正态分布的分位数
+func normInv(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// normPdf 标准正态分布的概率密度
+func normPdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
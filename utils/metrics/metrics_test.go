@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// linearReturns 返回 -0.05 到 0.05，间隔 0.001 的 101 个收益率
+func linearReturns() []float64 {
+	var returns = make([]float64, 101)
+	for i := range returns {
+		returns[i] = float64(i-50) / 1000
+	}
+	return returns
+}
+
+// RUN
+// go test -v ./utils/metrics -run TestReturns
+func TestReturns(t *testing.T) {
+	t.Parallel()
+	var s = NewEquitySeries([]float64{100, 110, 88, 92.4, 110.88}, klines.OneYear)
+	for i, want := range []float64{0.1, -0.2, 0.05, 0.2} {
+		if !almostEqual(s.Returns[i], want) {
+			t.Fatalf("return %d: got %v, want %v", i, s.Returns[i], want)
+		}
+	}
+	if !almostEqual(s.TotalReturn(), 0.1088) {
+		t.Fatalf("total return: got %v", s.TotalReturn())
+	}
+	if want := math.Pow(1.1088, 0.25) - 1; !almostEqual(s.CAGR(), want) {
+		t.Fatalf("cagr: got %v, want %v", s.CAGR(), want)
+	}
+
+	// 日线一年 365 个周期
+	if got := PeriodsPerYear(klines.OneDay); !almostEqual(got, 365) {
+		t.Fatalf("periods: got %v", got)
+	}
+	var daily = NewSeries([]float64{0.01, -0.01, 0.02, 0}, klines.OneDay)
+	var mean, std = 0.005, math.Sqrt((0.005*0.005 + 0.015*0.015 + 0.015*0.015 + 0.005*0.005) / 3)
+	if want := std * math.Sqrt(365); !almostEqual(daily.Volatility(), want) {
+		t.Fatalf("volatility: got %v, want %v", daily.Volatility(), want)
+	}
+	if want := (mean - 0.0365/365) / std * math.Sqrt(365); !almostEqual(daily.Sharpe(0.0365), want) {
+		t.Fatalf("sharpe: got %v, want %v", daily.Sharpe(0.0365), want)
+	}
+	if want := mean / math.Sqrt(0.01*0.01/4) * math.Sqrt(365); !almostEqual(daily.Sortino(0), want) {
+		t.Fatalf("sortino: got %v, want %v", daily.Sortino(0), want)
+	}
+
+	var omega = NewSeries([]float64{0.02, -0.01, 0.03, -0.02}, klines.OneDay)
+	if !almostEqual(omega.Omega(0), 0.05/0.03) {
+		t.Fatalf("omega: got %v", omega.Omega(0))
+	}
+	if got := NewSeries([]float64{0.01, 0.02}, klines.OneDay).Omega(0); !math.IsInf(got, 1) {
+		t.Fatalf("omega without loss: got %v", got)
+	}
+
+	// 收盘价序列
+	var list = utils.GetRandomKlineItem(50, 1)
+	var item = NewItemSeries(list)
+	if len(item.Returns) != 49 || !item.Times[0].Equal(time.Unix(list.Candles[1].TimeUnix, 0)) {
+		t.Fatalf("item series: %d returns, first time %v", len(item.Returns), item.Times[0])
+	}
+	if want := list.Candles[49].Close/list.Candles[0].Close - 1; !almostEqual(item.TotalReturn(), want) {
+		t.Fatalf("item total return: got %v, want %v", item.TotalReturn(), want)
+	}
+}
+
+// RUN
+// go test -v ./utils/metrics -run TestMaxDrawdown
+func TestMaxDrawdown(t *testing.T) {
+	t.Parallel()
+	var s = NewSeries([]float64{0.1, -0.2, 0.05, 0.2, -0.1}, klines.OneYear)
+	var dd = s.MaxDrawdown()
+	var want = Drawdown{Depth: 0.2, Peak: 0, Trough: 1, Recovery: 3, Duration: 3, RecoveryPeriods: 2}
+	if !almostEqual(dd.Depth, want.Depth) || dd.Peak != want.Peak || dd.Trough != want.Trough ||
+		dd.Recovery != want.Recovery || dd.Duration != want.Duration || dd.RecoveryPeriods != want.RecoveryPeriods {
+		t.Fatalf("got %+v, want %+v", dd, want)
+	}
+	if cagr := s.CAGR(); !almostEqual(s.Calmar(), cagr/0.2) {
+		t.Fatalf("calmar: got %v", s.Calmar())
+	}
+
+	// 从初始权益开始下跌且没有恢复
+	dd = NewSeries([]float64{-0.1, 0.05, -0.5, 0.1}, klines.OneYear).MaxDrawdown()
+	want = Drawdown{Depth: 1 - 0.9*1.05*0.5, Peak: -1, Trough: 2, Recovery: -1, Duration: 4, RecoveryPeriods: -1}
+	if !almostEqual(dd.Depth, want.Depth) || dd.Peak != want.Peak || dd.Trough != want.Trough ||
+		dd.Recovery != want.Recovery || dd.Duration != want.Duration || dd.RecoveryPeriods != want.RecoveryPeriods {
+		t.Fatalf("got %+v, want %+v", dd, want)
+	}
+
+	dd = NewSeries([]float64{0.1, 0.2}, klines.OneYear).MaxDrawdown()
+	if dd.Depth != 0 || dd.Peak != -1 || dd.Recovery != -1 {
+		t.Fatalf("no drawdown: got %+v", dd)
+	}
+}
+
+// RUN
+// go test -v ./utils/metrics -run TestVaR
+func TestVaR(t *testing.T) {
+	t.Parallel()
+	var s = NewSeries(linearReturns(), klines.OneDay)
+
+	if got := s.VaR(0.95, Historical); !almostEqual(got, 0.045) {
+		t.Fatalf("historical var: got %v", got)
+	}
+	if got := s.CVaR(0.95, Historical); !almostEqual(got, 0.0475) {
+		t.Fatalf("historical cvar: got %v", got)
+	}
+
+	var _, std = meanStd(s.Returns)
+	if !almostEqual(normInv(0.975), 1.959963984540054) {
+		t.Fatalf("normInv: got %v", normInv(0.975))
+	}
+	if got, want := s.VaR(0.95, Parametric), 1.6448536269514722*std; !almostEqual(got, want) {
+		t.Fatalf("parametric var: got %v, want %v", got, want)
+	}
+	if got, want := s.CVaR(0.95, Parametric), std*normPdf(-1.6448536269514722)/0.05; !almostEqual(got, want) {
+		t.Fatalf("parametric cvar: got %v, want %v", got, want)
+	}
+
+	// 均匀分布没有偏度，超额峰度为 -1.2
+	if math.Abs(s.Skewness()) > 1e-12 || math.Abs(s.Kurtosis()+1.2) > 0.01 {
+		t.Fatalf("skewness %v, kurtosis %v", s.Skewness(), s.Kurtosis())
+	}
+	var cf, cfShortfall = s.VaR(0.95, CornishFisher), s.CVaR(0.95, CornishFisher)
+	if almostEqual(cf, s.VaR(0.95, Parametric)) || cfShortfall < cf {
+		t.Fatalf("cornish-fisher var %v, cvar %v", cf, cfShortfall)
+	}
+
+	// 左尾更长的序列，修正后的损失比正态分布大
+	var skewed = NewSeries(append(linearReturns(), -0.3, -0.25), klines.OneDay)
+	if skewed.Skewness() >= 0 || skewed.VaR(0.99, CornishFisher) <= skewed.VaR(0.99, Parametric) {
+		t.Fatalf("skewed: skewness %v, var %v <= %v", skewed.Skewness(), skewed.VaR(0.99, CornishFisher), skewed.VaR(0.99, Parametric))
+	}
+
+	if got := s.TailRatio(); !almostEqual(got, 1) {
+		t.Fatalf("tail ratio: got %v", got)
+	}
+	var symmetric = NewSeries([]float64{0.01, -0.01, 0.01, -0.01}, klines.OneDay)
+	if !almostEqual(symmetric.Skewness(), 0) || !almostEqual(symmetric.Kurtosis(), -2) {
+		t.Fatalf("symmetric: skewness %v, kurtosis %v", symmetric.Skewness(), symmetric.Kurtosis())
+	}
+}
+
+// RUN
+// go test -v ./utils/metrics -run TestBenchmark
+func TestBenchmark(t *testing.T) {
+	t.Parallel()
+	var bench = []float64{0.01, -0.02, 0.015, 0.005, -0.01, 0.02}
+	var returns = make([]float64, len(bench))
+	for i, v := range bench {
+		returns[i] = 2*v + 0.001
+	}
+	var s, b = NewSeries(returns, klines.OneDay), NewSeries(bench, klines.OneDay)
+
+	if got, err := s.Beta(b); err != nil || !almostEqual(got, 2) {
+		t.Fatalf("beta: got %v, %v", got, err)
+	}
+	if got, err := s.Alpha(b, 0); err != nil || !almostEqual(got, 0.001*365) {
+		t.Fatalf("alpha: got %v, %v", got, err)
+	}
+	var active = make([]float64, len(bench))
+	for i := range bench {
+		active[i] = returns[i] - bench[i]
+	}
+	var mean, std = meanStd(active)
+	if got, err := s.InformationRatio(b); err != nil || !almostEqual(got, mean/std*math.Sqrt(365)) {
+		t.Fatalf("information ratio: got %v, %v", got, err)
+	}
+
+	// 按时间对齐，基准少一根K线
+	var list = utils.GetRandomKlineItem(60, 1)
+	var benchmark = *list
+	benchmark.Candles = list.Candles[1:]
+	var item = NewItemSeries(list)
+	if got, err := item.Beta(NewItemSeries(&benchmark)); err != nil || !almostEqual(got, 1) {
+		t.Fatalf("aligned beta: got %v, %v", got, err)
+	}
+
+	var other = NewSeries([]float64{0.01}, klines.OneDay)
+	other.Times = []time.Time{time.Unix(0, 0)}
+	if _, err := item.Beta(other); !errors.Is(err, ErrNotAligned) {
+		t.Fatalf("got %v, want ErrNotAligned", err)
+	}
+
+	report, err := s.Report(b, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(report.Beta, 2) || report.Periods != len(returns) || !almostEqual(report.Sharpe, s.Sharpe(0)) ||
+		!almostEqual(report.VaR.Historical, s.VaR(0.95, Historical)) || !almostEqual(report.CVaR.CornishFisher, s.CVaR(0.95, CornishFisher)) {
+		t.Fatalf("report: %+v", report)
+	}
+	if _, err := NewSeries(nil, klines.OneDay).Report(nil, DefaultConfig()); !errors.Is(err, ErrNoReturns) {
+		t.Fatalf("got %v, want ErrNoReturns", err)
+	}
+}
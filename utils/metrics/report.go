@@ -0,0 +1,85 @@
+package metrics
+
+// Config 汇总报告的参数
+type Config struct {
+	// 年化无风险利率，用于 Sharpe、Sortino 与 Alpha
+	RiskFreeRate float64
+	// Omega 的年化收益率门槛
+	Threshold float64
+	// VaR 与 CVaR 的置信度
+	Confidence float64
+}
+
+// DefaultConfig 默认参数：无风险利率与 Omega 门槛为 0，置信度 95%
+func DefaultConfig() Config {
+	return Config{Confidence: 0.95}
+}
+
+// VaRData 三种方式计算的 VaR 或 CVaR
+type VaRData struct {
+	Historical    float64
+	Parametric    float64
+	CornishFisher float64
+}
+
+// Report 绩效与风险指标汇总
+type Report struct {
+	Periods     int
+	TotalReturn float64
+	CAGR        float64
+	Volatility  float64
+	Sharpe      float64
+	Sortino     float64
+	Calmar      float64
+	Omega       float64
+	MaxDrawdown Drawdown
+	VaR         VaRData
+	CVaR        VaRData
+	TailRatio   float64
+	Skewness    float64
+	Kurtosis    float64
+	// 有基准时计算
+	Alpha            float64
+	Beta             float64
+	InformationRatio float64
+}
+
+// Report 计算全部指标，benchmark 为 nil 时不计算 Alpha、Beta 与 InformationRatio
+func (s *Series) Report(benchmark *Series, config Config) (*Report, error) {
+	if len(s.Returns) == 0 {
+		return nil, ErrNoReturns
+	}
+	var report = &Report{
+		Periods:     len(s.Returns),
+		TotalReturn: s.TotalReturn(),
+		CAGR:        s.CAGR(),
+		Volatility:  s.Volatility(),
+		Sharpe:      s.Sharpe(config.RiskFreeRate),
+		Sortino:     s.Sortino(config.RiskFreeRate),
+		Calmar:      s.Calmar(),
+		Omega:       s.Omega(config.Threshold),
+		MaxDrawdown: s.MaxDrawdown(),
+		TailRatio:   s.TailRatio(),
+		Skewness:    s.Skewness(),
+		Kurtosis:    s.Kurtosis(),
+	}
+	for _, v := range []struct {
+		data *VaRData
+		fn   func(float64, VaRMethod) float64
+	}{{&report.VaR, s.VaR}, {&report.CVaR, s.CVaR}} {
+		v.data.Historical = v.fn(config.Confidence, Historical)
+		v.data.Parametric = v.fn(config.Confidence, Parametric)
+		v.data.CornishFisher = v.fn(config.Confidence, CornishFisher)
+	}
+
+	if benchmark == nil {
+		return report, nil
+	}
+	var err error
+	if report.Alpha, err = s.Alpha(benchmark, config.RiskFreeRate); err != nil {
+		return nil, err
+	}
+	report.Beta, _ = s.Beta(benchmark)
+	report.InformationRatio, _ = s.InformationRatio(benchmark)
+	return report, nil
+}
@@ -0,0 +1,182 @@
+package mtf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoCandles 没有K线数据
+	ErrNoCandles = errors.New("mtf: no candle data")
+	// ErrNotAligned 没有与高周期对齐的K线
+	ErrNotAligned = errors.New("mtf: no candle aligned to the higher interval")
+)
+
+// Timeframe 多周期分析，把基础周期的K线合成为高周期K线，并记录每根基础K线可以看到的高周期K线
+//
+// 高周期K线 j 在 T[j] + 高周期 时才完成，基础K线 i 在 t[i] + 基础周期 时完成，
+// 只有 T[j] + 高周期 <= t[i] + 基础周期 时基础K线 i 才能看到高周期K线 j，因此不会用到未来数据。
+type Timeframe struct {
+	// 基础周期K线
+	Base *klines.Item
+	// 合成后的高周期K线
+	Higher *klines.Item
+	// 每根基础K线可以看到的最后一根已完成的高周期K线序号，没有时为 -1
+	index []int
+}
+
+// NewTimeframe 使用 Item.ConvertToNewInterval 把 base 合成为 interval 周期，
+// 合成前会跳过开头未与高周期对齐的K线，最后一根未完成的高周期K线不会生成。
+// base 需要是连续的K线，存在缺失时 ConvertToNewInterval 会返回错误。
+func NewTimeframe(base *klines.Item, interval klines.Interval) (*Timeframe, error) {
+	if base == nil || len(base.Candles) == 0 {
+		return nil, ErrNoCandles
+	}
+
+	var seconds = int64(interval.Duration().Seconds())
+	if seconds <= 0 {
+		return nil, klines.ErrInvalidInterval
+	}
+	var start = -1
+	for i, candle := range base.Candles {
+		if candle.TimeUnix%seconds == 0 {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotAligned, interval)
+	}
+
+	var aligned = &klines.Item{
+		Exchange: base.Exchange,
+		Symbol:   base.Symbol,
+		Code:     base.Code,
+		Interval: base.Interval,
+		Candles:  base.Candles[start:],
+	}
+	higher, err := aligned.ConvertToNewInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	higher.Symbol = base.Symbol
+	higher.Code = base.Code
+
+	// 只保留完整的高周期K线
+	var baseSeconds = int64(base.Interval.Duration().Seconds())
+	var last = base.Candles[len(base.Candles)-1].TimeUnix + baseSeconds
+	for len(higher.Candles) > 0 && higher.Candles[len(higher.Candles)-1].TimeUnix+seconds > last {
+		higher.Candles = higher.Candles[:len(higher.Candles)-1]
+	}
+
+	var e = &Timeframe{
+		Base:   base,
+		Higher: higher,
+		index:  make([]int, len(base.Candles)),
+	}
+	var j = -1
+	for i, candle := range base.Candles {
+		for j+1 < len(higher.Candles) && higher.Candles[j+1].TimeUnix+seconds <= candle.TimeUnix+baseSeconds {
+			j++
+		}
+		e.index[i] = j
+	}
+	return e, nil
+}
+
+// Index 返回基础K线 i 可以看到的最后一根已完成的高周期K线序号，没有时为 -1
+func (e *Timeframe) Index(i int) int {
+	if i < 0 || i >= len(e.index) {
+		return -1
+	}
+	return e.index[i]
+}
+
+// Project 把与高周期K线一一对应的值投影到基础周期，没有已完成的高周期K线时使用 zero。
+// values 比高周期K线少时按最后一根右对齐。
+func Project[T any](tf *Timeframe, values []T, zero T) []T {
+	var offset = len(tf.Higher.Candles) - len(values)
+	var result = make([]T, len(tf.index))
+	for i, j := range tf.index {
+		result[i] = zero
+		if x := j - offset; j >= 0 && x >= 0 && x < len(values) {
+			result[i] = values[x]
+		}
+	}
+	return result
+}
+
+// ProjectFloat 投影浮点数，没有已完成的高周期K线时为 NaN
+func (e *Timeframe) ProjectFloat(values []float64) []float64 {
+	return Project(e, values, math.NaN())
+}
+
+// ProjectSides 投影策略信号，没有已完成的高周期K线时为 Hold。
+// 高周期信号在下一根高周期K线完成之前会在每根基础K线上重复出现。
+func (e *Timeframe) ProjectSides(sides utils.SideData) utils.SideData {
+	return utils.SideData{
+		Name: fmt.Sprintf("%s@%s", sides.Name, e.Higher.Interval),
+		Data: e.projectSides(sides.Data),
+	}
+}
+
+// projectSides 策略不计算第一根K线，信号为零值 Buy，投影前按 Hold 处理
+func (e *Timeframe) projectSides(sides []utils.Side) []utils.Side {
+	if len(sides) > 0 && sides[0] != utils.Hold {
+		sides = append([]utils.Side{utils.Hold}, sides[1:]...)
+	}
+	return Project(e, sides, utils.Hold)
+}
+
+// Security 在高周期上计算 fn 并投影回基础周期，类似 TradingView 的 request.security
+//
+//	var closes, err = mtf.Security(list, klines.OneHour, func(higher *klines.Item) []float64 {
+//		var values []float64
+//		for _, v := range trend.NewEma(higher, 20).GetData() {
+//			values = append(values, v.Value)
+//		}
+//		return values
+//	})
+func Security(base *klines.Item, interval klines.Interval, fn func(higher *klines.Item) []float64) ([]float64, error) {
+	tf, err := NewTimeframe(base, interval)
+	if err != nil {
+		return nil, err
+	}
+	return tf.ProjectFloat(fn(tf.Higher)), nil
+}
+
+// Strategy 在高周期上运行的策略，信号投影回基础周期，可以与基础周期的策略组合后回测
+type Strategy struct {
+	Name     string
+	tf       *Timeframe
+	strategy utils.IStrategy
+}
+
+// NewStrategy 使用高周期K线创建策略，策略在调用 AnalysisSide 时才计算
+//
+//	// 1h SuperTrend 与 15m Rsi 同时给出信号
+//	tf, _ := mtf.NewTimeframe(list, klines.OneHour)
+//	superTrend := mtf.NewStrategy(tf, func(higher *klines.Item) utils.IStrategy {
+//		return trend.NewDefaultSuperTrend(higher)
+//	})
+//	combined := utils.NewAllAgree(superTrend, trend.NewDefaultRsi(list))
+func NewStrategy(tf *Timeframe, build func(higher *klines.Item) utils.IStrategy) *Strategy {
+	var strategy = build(tf.Higher)
+	return &Strategy{
+		Name:     fmt.Sprintf("%s@%s", utils.StrategyName(strategy), tf.Higher.Interval),
+		tf:       tf,
+		strategy: strategy,
+	}
+}
+
+// AnalysisSide Func
+func (e *Strategy) AnalysisSide() utils.SideData {
+	return utils.SideData{
+		Name: e.Name,
+		Data: e.tf.projectSides(e.strategy.AnalysisSide().Data),
+	}
+}
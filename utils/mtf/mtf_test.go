@@ -0,0 +1,115 @@
+package mtf
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// RUN
+// go test -v ./utils/mtf -run TestTimeframe
+func TestTimeframe(t *testing.T) {
+	t.Parallel()
+	// 第一根K线不与 2 小时对齐
+	var list = utils.GetRandomKlineItem(203, 1)
+	list.Candles = list.Candles[1:]
+
+	tf, err := NewTimeframe(list, klines.TwoHour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var higherSeconds = int64(klines.TwoHour.Duration().Seconds())
+	var baseSeconds = int64(list.Interval.Duration().Seconds())
+	for _, candle := range tf.Higher.Candles {
+		if candle.TimeUnix%higherSeconds != 0 {
+			t.Fatalf("higher candle %d not aligned", candle.TimeUnix)
+		}
+	}
+
+	for i, candle := range list.Candles {
+		var j = tf.Index(i)
+		if j >= 0 && tf.Higher.Candles[j].TimeUnix+higherSeconds > candle.TimeUnix+baseSeconds {
+			t.Fatalf("base %d sees unfinished higher candle %d", i, j)
+		}
+		if j+1 < len(tf.Higher.Candles) && tf.Higher.Candles[j+1].TimeUnix+higherSeconds <= candle.TimeUnix+baseSeconds {
+			t.Fatalf("base %d misses finished higher candle %d", i, j+1)
+		}
+		// 高周期K线完成的那根基础K线，收盘价一致
+		if j >= 0 && tf.Higher.Candles[j].TimeUnix+higherSeconds == candle.TimeUnix+baseSeconds && tf.Higher.Candles[j].Close != candle.Close {
+			t.Fatalf("higher close %f differs from base close %f", tf.Higher.Candles[j].Close, candle.Close)
+		}
+	}
+
+	var closes = make([]float64, len(tf.Higher.Candles))
+	for i, v := range tf.Higher.Candles {
+		closes[i] = v.Close
+	}
+	var projected = tf.ProjectFloat(closes)
+	if len(projected) != len(list.Candles) || !math.IsNaN(projected[0]) {
+		t.Fatalf("expected NaN before the first finished higher candle, got %f", projected[0])
+	}
+	if projected[len(projected)-1] != closes[len(closes)-1] {
+		t.Fatalf("unexpected last value %f", projected[len(projected)-1])
+	}
+
+	if _, err = NewTimeframe(&klines.Item{}, klines.TwoHour); !errors.Is(err, ErrNoCandles) {
+		t.Fatalf("received '%v' expected '%v'", err, ErrNoCandles)
+	}
+}
+
+// RUN
+// go test -v ./utils/mtf -run TestStrategy
+func TestStrategy(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(16, 2)
+	tf, err := NewTimeframe(list, klines.TwoHour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Higher.Candles) != 4 {
+		t.Fatalf("expected 4 higher candles, got %d", len(tf.Higher.Candles))
+	}
+
+	var strategy = NewStrategy(tf, func(higher *klines.Item) utils.IStrategy {
+		return utils.GetSidesStrategy("test", utils.Buy, utils.Buy, utils.Sell, utils.Hold)
+	})
+	if strategy.Name != "test@"+klines.TwoHour.String() {
+		t.Fatalf("unexpected name %s", strategy.Name)
+	}
+	var sides = strategy.AnalysisSide()
+	if sides.Name != strategy.Name {
+		t.Fatalf("unexpected name %s", sides.Name)
+	}
+	// 每根 2 小时K线包含 4 根 30 分钟K线，完成后从第 4 根开始可见，第一根高周期K线没有计算，为 Hold
+	var expected = []utils.Side{
+		utils.Hold, utils.Hold, utils.Hold, utils.Hold,
+		utils.Hold, utils.Hold, utils.Hold, utils.Buy,
+		utils.Buy, utils.Buy, utils.Buy, utils.Sell,
+		utils.Sell, utils.Sell, utils.Sell, utils.Hold,
+	}
+	for i, v := range expected {
+		if sides.Data[i] != v {
+			t.Fatalf("[%d] received %s expected %s", i, sides.Data[i], v)
+		}
+	}
+
+	// 真实指标的第一根K线为零值 Buy，不能投影到基础周期
+	list = utils.GetRandomKlineItem(400, 3)
+	if tf, err = NewTimeframe(list, klines.FourHour); err != nil {
+		t.Fatal(err)
+	}
+	var macd = NewStrategy(tf, func(higher *klines.Item) utils.IStrategy {
+		return trend.NewDefaultMacd(higher)
+	}).AnalysisSide()
+	var projected = tf.ProjectSides(trend.NewDefaultMacd(tf.Higher).AnalysisSide())
+	for i := range list.Candles {
+		if tf.Index(i) <= 0 && (macd.Data[i] != utils.Hold || projected.Data[i] != utils.Hold) {
+			t.Fatalf("[%d] received %s and %s before the first computed higher candle", i, macd.Data[i], projected.Data[i])
+		}
+	}
+}
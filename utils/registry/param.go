@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/idoall/stockindicator/utils/types"
+)
+
+// ParamType 参数类型
+type ParamType uint32
+
+const (
+	// Int 整数，例如周期
+	Int ParamType = iota
+	// Float 浮点数，例如倍数
+	Float
+	// Bool 布尔值
+	Bool
+	// MAType 均线类型，取值见 types.ParseMATypes
+	MAType
+)
+
+// String implements the stringer interface
+func (t ParamType) String() string {
+	switch t {
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case MAType:
+		return "maType"
+	default:
+		return "unknown"
+	}
+}
+
+// Param 参数定义
+type Param struct {
+	Name string
+	Type ParamType
+	// 默认值
+	Default interface{}
+	// 取值范围，仅用于 Int 与 Float，Min 与 Max 相等时不限制
+	Min float64
+	Max float64
+}
+
+// IntParam 整数参数，取值不小于 min
+func IntParam(name string, def, min int) Param {
+	return Param{Name: name, Type: Int, Default: def, Min: float64(min), Max: math.Inf(1)}
+}
+
+// IntRangeParam 整数参数，取值在 [min, max] 之间，用于枚举类型的参数
+func IntRangeParam(name string, def, min, max int) Param {
+	return Param{Name: name, Type: Int, Default: def, Min: float64(min), Max: float64(max)}
+}
+
+// FloatParam 浮点数参数，取值在 [min, max] 之间
+func FloatParam(name string, def, min, max float64) Param {
+	return Param{Name: name, Type: Float, Default: def, Min: min, Max: max}
+}
+
+// BoolParam 布尔参数
+func BoolParam(name string, def bool) Param {
+	return Param{Name: name, Type: Bool, Default: def}
+}
+
+// MATypeParam 均线类型参数
+func MATypeParam(name string, def types.MATypes) Param {
+	return Param{Name: name, Type: MAType, Default: def}
+}
+
+// normalize 把输入转换为参数类型并校验取值范围
+func (p Param) normalize(raw interface{}) (interface{}, error) {
+	switch p.Type {
+	case Int:
+		value, err := toFloat(raw)
+		if err != nil || value != math.Trunc(value) {
+			return nil, fmt.Errorf("%w: %s expects int, got %v", ErrInvalidParam, p.Name, raw)
+		}
+		if err = p.check(value); err != nil {
+			return nil, err
+		}
+		return int(value), nil
+	case Float:
+		value, err := toFloat(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects float, got %v", ErrInvalidParam, p.Name, raw)
+		}
+		if err = p.check(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case Bool:
+		switch value := raw.(type) {
+		case bool:
+			return value, nil
+		case string:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s expects bool, got %q", ErrInvalidParam, p.Name, value)
+			}
+			return b, nil
+		default:
+			number, err := toFloat(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s expects bool, got %v", ErrInvalidParam, p.Name, raw)
+			}
+			return number != 0, nil
+		}
+	case MAType:
+		switch value := raw.(type) {
+		case types.MATypes:
+			return value, nil
+		case string:
+			maType, err := types.ParseMATypes(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidParam, p.Name, err)
+			}
+			return maType, nil
+		default:
+			return nil, fmt.Errorf("%w: %s expects ma type, got %v", ErrInvalidParam, p.Name, raw)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s has unknown type", ErrInvalidParam, p.Name)
+	}
+}
+
+func (p Param) check(value float64) error {
+	if p.Min == p.Max {
+		return nil
+	}
+	if value < p.Min || value > p.Max {
+		return fmt.Errorf("%w: %s must be in [%g, %g], got %g", ErrInvalidParam, p.Name, p.Min, p.Max, value)
+	}
+	return nil
+}
+
+func toFloat(raw interface{}) (float64, error) {
+	switch value := raw.(type) {
+	case int:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case uint:
+		return float64(value), nil
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case json.Number:
+		return value.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	default:
+		return 0, fmt.Errorf("unsupported type %T", raw)
+	}
+}
+
+// Values 校验后的参数，键为参数名
+type Values map[string]interface{}
+
+// Int 返回整数参数
+func (v Values) Int(name string) int {
+	value, _ := v[name].(int)
+	return value
+}
+
+// Float 返回浮点数参数
+func (v Values) Float(name string) float64 {
+	value, _ := v[name].(float64)
+	return value
+}
+
+// Bool 返回布尔参数
+func (v Values) Bool(name string) bool {
+	value, _ := v[name].(bool)
+	return value
+}
+
+// MAType 返回均线类型参数
+func (v Values) MAType(name string) types.MATypes {
+	value, _ := v[name].(types.MATypes)
+	return value
+}
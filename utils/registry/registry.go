@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrUnknownIndicator 指标未注册
+	ErrUnknownIndicator = errors.New("registry: unknown indicator")
+	// ErrInvalidParam 参数名称、类型或取值范围不正确
+	ErrInvalidParam = errors.New("registry: invalid parameter")
+	// ErrInvalidSpec 指标描述字符串格式不正确
+	ErrInvalidSpec = errors.New("registry: invalid spec")
+	// ErrNotStrategy 指标没有实现 utils.IStrategy
+	ErrNotStrategy = errors.New("registry: indicator is not a strategy")
+)
+
+// Category 指标分类
+type Category string
+
+const (
+	// Channel 通道类
+	Channel Category = "channel"
+	// Trend 趋势类
+	Trend Category = "trend"
+	// Volume 交易量类
+	Volume Category = "volume"
+	// Oscillator 震荡类
+	Oscillator Category = "oscillator"
+	// Pattern K线形态
+	Pattern Category = "pattern"
+	// Levels 支撑阻力与价格水平
+	Levels Category = "levels"
+)
+
+// Factory 根据已校验并补全默认值的参数创建指标
+type Factory func(item *klines.Item, params Values) interface{}
+
+// Definition 指标定义
+type Definition struct {
+	// 名称，查找时不区分大小写
+	Name string
+	// 别名，例如 Rsi 的 rsi、MoneyFlowIndex 的 mfi
+	Aliases     []string
+	Category    Category
+	Description string
+	// 参数，顺序与 NewXxx 的参数一致
+	Params []Param
+	New    Factory
+	// 是否实现了 utils.IStrategy，注册时自动判断
+	Strategy bool
+}
+
+var (
+	mu          sync.RWMutex
+	definitions = map[string]*Definition{}
+)
+
+// Register 注册指标，通常在各指标包的 init 中调用，名称或别名重复时 panic
+func Register(def Definition) {
+	if def.Name == "" || def.New == nil {
+		panic("registry: Register requires Name and New")
+	}
+	for _, p := range def.Params {
+		if _, err := p.normalize(p.Default); err != nil {
+			panic(fmt.Sprintf("registry: %s default %v", def.Name, err))
+		}
+	}
+	_, def.Strategy = def.New(&klines.Item{}, def.defaults()).(utils.IStrategy)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range append([]string{def.Name}, def.Aliases...) {
+		var key = strings.ToLower(name)
+		if _, ok := definitions[key]; ok {
+			panic("registry: Register called twice for " + name)
+		}
+		definitions[key] = &def
+	}
+}
+
+// Get 按名称或别名查找指标定义，不区分大小写
+func Get(name string) (Definition, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	def, ok := definitions[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Definition{}, false
+	}
+	return *def, true
+}
+
+// List 返回全部指标定义，按分类与名称排序
+func List() []Definition {
+	mu.RLock()
+	defer mu.RUnlock()
+	var list []Definition
+	for key, def := range definitions {
+		if key == strings.ToLower(def.Name) {
+			list = append(list, *def)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Category != list[j].Category {
+			return list[i].Category < list[j].Category
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Build 根据名称与参数创建指标，缺少的参数使用默认值
+//
+//	registry.Build(list, "Boll", map[string]any{"periodN": 20, "periodK": 2})
+func Build(item *klines.Item, name string, params map[string]interface{}) (interface{}, error) {
+	def, ok := Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownIndicator, name)
+	}
+	values, err := def.Values(params)
+	if err != nil {
+		return nil, err
+	}
+	return def.New(item, values), nil
+}
+
+// BuildSpec 根据描述字符串创建指标，格式见 ParseSpec
+//
+//	registry.BuildSpec(list, "Boll(20,2)")
+func BuildSpec(item *klines.Item, spec string) (interface{}, error) {
+	name, params, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return Build(item, name, params)
+}
+
+// BuildStrategy 根据描述字符串创建策略
+func BuildStrategy(item *klines.Item, spec string) (utils.IStrategy, error) {
+	indicator, err := BuildSpec(item, spec)
+	if err != nil {
+		return nil, err
+	}
+	strategy, ok := indicator.(utils.IStrategy)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotStrategy, spec)
+	}
+	return strategy, nil
+}
+
+// Values 按参数定义把输入转换为对应类型，补全默认值并校验取值范围。
+// 位置参数使用 "0"、"1" 这样的序号作为键，参数名不区分大小写。
+func (def Definition) Values(params map[string]interface{}) (Values, error) {
+	var values = def.defaults()
+	var seen = make(map[int]bool, len(params))
+	for key, raw := range params {
+		var index = def.paramIndex(key)
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %s has no parameter %q", ErrInvalidParam, def.Name, key)
+		}
+		if seen[index] {
+			return nil, fmt.Errorf("%w: %s parameter %s set twice", ErrInvalidParam, def.Name, def.Params[index].Name)
+		}
+		seen[index] = true
+		var p = def.Params[index]
+		value, err := p.normalize(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", def.Name, err)
+		}
+		values[p.Name] = value
+	}
+	return values, nil
+}
+
+// defaults 全部参数的默认值
+func (def Definition) defaults() Values {
+	var values = make(Values, len(def.Params))
+	for _, p := range def.Params {
+		values[p.Name], _ = p.normalize(p.Default)
+	}
+	return values
+}
+
+// paramIndex 按名称或位置序号查找参数
+func (def Definition) paramIndex(key string) int {
+	for i, p := range def.Params {
+		if strings.EqualFold(p.Name, key) || fmt.Sprint(i) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// String 返回带默认参数的描述字符串，例如 Boll(periodN=20, periodK=2, maType=SMA)
+func (def Definition) String() string {
+	var params = make([]string, len(def.Params))
+	for i, p := range def.Params {
+		params[i] = fmt.Sprintf("%s=%v", p.Name, p.Default)
+	}
+	return fmt.Sprintf("%s(%s)", def.Name, strings.Join(params, ", "))
+}
@@ -0,0 +1,138 @@
+package registry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/idoall/stockindicator/channel"
+	_ "github.com/idoall/stockindicator/levels"
+	_ "github.com/idoall/stockindicator/oscillator"
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/registry"
+	"github.com/idoall/stockindicator/utils/types"
+	_ "github.com/idoall/stockindicator/volume"
+)
+
+// RUN
+// go test -v ./utils/registry -run TestParseSpec
+func TestParseSpec(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		spec     string
+		name     string
+		params   map[string]interface{}
+		hasError bool
+	}{
+		{"Boll", "Boll", nil, false},
+		{"Boll()", "Boll", map[string]interface{}{}, false},
+		{"Boll(20,2)", "Boll", map[string]interface{}{"0": "20", "1": "2"}, false},
+		{" Boll(20, maType=EMA) ", "Boll", map[string]interface{}{"0": "20", "maType": "EMA"}, false},
+		{"Boll(maType=EMA,20)", "", nil, true},
+		{"Boll(20,,2)", "", nil, true},
+		{"Boll(20", "", nil, true},
+		{"(20)", "", nil, true},
+	}
+	for _, test := range tests {
+		name, params, err := registry.ParseSpec(test.spec)
+		if test.hasError {
+			if !errors.Is(err, registry.ErrInvalidSpec) {
+				t.Fatalf("%s: received '%v' expected '%v'", test.spec, err, registry.ErrInvalidSpec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.spec, err)
+		}
+		if name != test.name || !reflect.DeepEqual(params, test.params) {
+			t.Fatalf("%s: received %s %v expected %s %v", test.spec, name, params, test.name, test.params)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils/registry -run TestBuild
+func TestBuild(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(100, 1)
+
+	indicator, err := registry.BuildSpec(list, "boll(10, maType=EMA)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var boll = indicator.(*channel.Boll)
+	if boll.PeriodN != 10 || boll.PeriodK != 2 || boll.MATypes != types.EMA {
+		t.Fatalf("unexpected boll %+v", boll)
+	}
+
+	indicator, err = registry.Build(list, "Macd", map[string]interface{}{"short": 6, "long": 13.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indicator.(*trend.Macd).Name != trend.NewMacd(list, 6, 9, 13).Name {
+		t.Fatalf("unexpected macd %s", indicator.(*trend.Macd).Name)
+	}
+
+	strategy, err := registry.BuildStrategy(list, "rsi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(strategy.AnalysisSide(), trend.NewDefaultRsi(list).AnalysisSide()) {
+		t.Fatal("registry rsi differs from NewDefaultRsi")
+	}
+
+	var errorTests = []struct {
+		spec string
+		err  error
+	}{
+		{"Unknown", registry.ErrUnknownIndicator},
+		{"Rsi(0)", registry.ErrInvalidParam},
+		{"Rsi(1.5)", registry.ErrInvalidParam},
+		{"Rsi(14,2)", registry.ErrInvalidParam},
+		{"Rsi(14,period=2)", registry.ErrInvalidParam},
+		{"Boll(maType=XYZ)", registry.ErrInvalidParam},
+		{"PivotPoints(type=6)", registry.ErrInvalidParam},
+		{"PivotPoints(timeframe=7)", registry.ErrInvalidParam},
+		{"PivotPoints(signal=5)", registry.ErrInvalidParam},
+		{"PivotPoints(level=5)", registry.ErrInvalidParam},
+		{"Vwap(session=7)", registry.ErrInvalidParam},
+		{"Vwap(band=9)", registry.ErrInvalidParam},
+		{"Fibonacci(method=2)", registry.ErrInvalidParam},
+		{"Fibonacci(atrMultiple=0)", registry.ErrInvalidParam},
+		{"Sma", registry.ErrNotStrategy},
+	}
+	for _, test := range errorTests {
+		if _, err = registry.BuildStrategy(list, test.spec); !errors.Is(err, test.err) {
+			t.Fatalf("%s: received '%v' expected '%v'", test.spec, err, test.err)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils/registry -run TestList
+func TestList(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(300, 2)
+	for _, def := range registry.List() {
+		if def.Category == "" || def.Description == "" {
+			t.Fatalf("%s has no category or description", def.Name)
+		}
+		// 使用默认参数创建的指标都可以正常计算
+		indicator, err := registry.Build(list, def.Name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", def.Name, err)
+		}
+		if _, ok := indicator.(utils.IStrategy); ok != def.Strategy {
+			t.Fatalf("%s: unexpected strategy flag", def.Name)
+		}
+	}
+
+	def, ok := registry.Get("MFI")
+	if !ok || def.Name != "MoneyFlowIndex" {
+		t.Fatalf("alias lookup failed: %+v", def)
+	}
+	if def.String() != "MoneyFlowIndex(period=14)" {
+		t.Fatalf("unexpected definition string %s", def.String())
+	}
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSpec 解析指标描述字符串，返回名称与参数，位置参数的键为序号
+//
+//	"Boll"                      默认参数
+//	"Boll(20,2)"                位置参数
+//	"Boll(periodN=20, maType=EMA)" 命名参数，可以与位置参数混用
+func ParseSpec(spec string) (name string, params map[string]interface{}, err error) {
+	spec = strings.TrimSpace(spec)
+	var open = strings.IndexByte(spec, '(')
+	if open < 0 {
+		if spec == "" || strings.ContainsAny(spec, ")=,") {
+			return "", nil, fmt.Errorf("%w: %q", ErrInvalidSpec, spec)
+		}
+		return spec, nil, nil
+	}
+	if !strings.HasSuffix(spec, ")") || strings.Count(spec, "(") != 1 || strings.Count(spec, ")") != 1 {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidSpec, spec)
+	}
+
+	name = strings.TrimSpace(spec[:open])
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: %q has no name", ErrInvalidSpec, spec)
+	}
+
+	params = map[string]interface{}{}
+	var body = strings.TrimSpace(spec[open+1 : len(spec)-1])
+	if body == "" {
+		return name, params, nil
+	}
+	var named = false
+	for i, arg := range strings.Split(body, ",") {
+		arg = strings.TrimSpace(arg)
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			named = true
+			params[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			continue
+		}
+		if named {
+			return "", nil, fmt.Errorf("%w: positional parameter after named parameter in %q", ErrInvalidSpec, spec)
+		}
+		if arg == "" {
+			return "", nil, fmt.Errorf("%w: empty parameter in %q", ErrInvalidSpec, spec)
+		}
+		params[strconv.Itoa(i)] = arg
+	}
+	return name, params, nil
+}
@@ -0,0 +1,447 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// prices 价格序列
+var prices = map[string]func(c *klines.Candle) float64{
+	"open":   func(c *klines.Candle) float64 { return c.Open },
+	"high":   func(c *klines.Candle) float64 { return c.High },
+	"low":    func(c *klines.Candle) float64 { return c.Low },
+	"close":  func(c *klines.Candle) float64 { return c.Close },
+	"volume": func(c *klines.Candle) float64 { return c.Volume },
+	"hl2":    func(c *klines.Candle) float64 { return (c.High + c.Low) / 2 },
+	"hlc3":   func(c *klines.Candle) float64 { return (c.High + c.Low + c.Close) / 3 },
+	"ohlc4":  func(c *klines.Candle) float64 { return (c.Open + c.High + c.Low + c.Close) / 4 },
+}
+
+// builtin 内置函数
+type builtin struct {
+	// 参数数量对应的参数类型，s 为数值序列，p 为正整数周期
+	signatures map[int]string
+	usage      string
+	// 参数省略时使用的价格
+	source string
+	fn     func(args [][]float64, period int) []float64
+}
+
+var movingAverage = func(fn func(int, []float64) []float64) func([][]float64, int) []float64 {
+	return func(args [][]float64, period int) []float64 { return fn(period, args[0]) }
+}
+
+var sourcePeriod = map[int]string{1: "p", 2: "sp"}
+
+var builtins = map[string]builtin{
+	"sma":     {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "close", fn: movingAverage(ta.Sma)},
+	"ema":     {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "close", fn: movingAverage(ta.Ema)},
+	"wma":     {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "close", fn: movingAverage(ta.Wma)},
+	"rma":     {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "close", fn: movingAverage(ta.Rma)},
+	"highest": {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "high", fn: movingAverage(ta.Max)},
+	"lowest":  {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "low", fn: movingAverage(ta.Min)},
+	"stdev": {signatures: sourcePeriod, usage: "(period) or (source, period)", source: "close", fn: func(args [][]float64, period int) []float64 {
+		return ta.StdDev(args[0], period, 1)
+	}},
+	"change": {signatures: map[int]string{1: "s", 2: "sp"}, usage: "(source) or (source, bars)", fn: func(args [][]float64, period int) []float64 {
+		var result = make([]float64, len(args[0]))
+		for i := range result {
+			result[i] = math.NaN()
+			if i >= max(period, 1) {
+				result[i] = args[0][i] - args[0][i-max(period, 1)]
+			}
+		}
+		return result
+	}},
+	"abs": {signatures: map[int]string{1: "s"}, usage: "(x)", fn: func(args [][]float64, _ int) []float64 {
+		return apply(args, func(v []float64) float64 { return math.Abs(v[0]) })
+	}},
+	"min": {signatures: map[int]string{2: "ss"}, usage: "(a, b)", fn: func(args [][]float64, _ int) []float64 {
+		return apply(args, func(v []float64) float64 { return math.Min(v[0], v[1]) })
+	}},
+	"max": {signatures: map[int]string{2: "ss"}, usage: "(a, b)", fn: func(args [][]float64, _ int) []float64 {
+		return apply(args, func(v []float64) float64 { return math.Max(v[0], v[1]) })
+	}},
+}
+
+// apply 逐根K线计算
+func apply(args [][]float64, fn func(v []float64) float64) []float64 {
+	var result = make([]float64, len(args[0]))
+	var v = make([]float64, len(args))
+	for i := range result {
+		for j := range args {
+			v[j] = args[j][i]
+		}
+		result[i] = fn(v)
+	}
+	return result
+}
+
+// value 表达式在每根K线上的值，数值与条件只有一个有效
+type value struct {
+	num  []float64
+	cond []bool
+}
+
+type evaluator struct {
+	expr string
+	item *klines.Item
+	// 相同的函数与指标调用只计算一次
+	cache map[string][]float64
+	// 正在计算的节点位置，用于 panic 时报告
+	pos int
+}
+
+// fail 计算错误
+func (e *evaluator) fail(pos int, err error, format string, args ...interface{}) *Error {
+	return &Error{Expr: e.expr, Pos: pos, Msg: fmt.Sprintf(format, args...), Kind: ErrEvaluate, Err: err}
+}
+
+func (e *evaluator) eval(n node) (value, error) {
+	e.pos = n.position()
+	var length = len(e.item.Candles)
+	switch n := n.(type) {
+	case *numberNode:
+		var result = make([]float64, length)
+		for i := range result {
+			result[i] = n.value
+		}
+		return value{num: result}, nil
+	case *boolNode:
+		var result = make([]bool, length)
+		for i := range result {
+			result[i] = n.value
+		}
+		return value{cond: result}, nil
+	case *identNode:
+		var price = prices[n.name]
+		var result = make([]float64, length)
+		for i, candle := range e.item.Candles {
+			result[i] = price(candle)
+		}
+		return value{num: result}, nil
+	case *indexNode:
+		return e.index(n)
+	case *unaryNode:
+		x, err := e.eval(n.x)
+		if err != nil {
+			return value{}, err
+		}
+		if n.op == "not" {
+			var result = make([]bool, length)
+			for i, v := range x.cond {
+				result[i] = !v
+			}
+			return value{cond: result}, nil
+		}
+		var result = make([]float64, length)
+		for i, v := range x.num {
+			result[i] = -v
+		}
+		return value{num: result}, nil
+	case *windowNode:
+		return e.window(n)
+	case *binaryNode:
+		return e.binary(n)
+	case *callNode:
+		if cached, ok := e.cache[n.text]; ok {
+			return value{num: cached}, nil
+		}
+		var result, err = e.call(n)
+		if err != nil {
+			return value{}, err
+		}
+		e.cache[n.text] = result
+		return value{num: result}, nil
+	}
+	return value{}, e.fail(n.position(), nil, "unsupported expression")
+}
+
+// index 回看 offset 根K线，之前没有数据时数值为 NaN、条件为 false
+func (e *evaluator) index(n *indexNode) (value, error) {
+	x, err := e.eval(n.x)
+	if err != nil {
+		return value{}, err
+	}
+	var length = len(e.item.Candles)
+	if x.cond != nil {
+		var result = make([]bool, length)
+		for i := n.offset; i < length; i++ {
+			result[i] = x.cond[i-n.offset]
+		}
+		return value{cond: result}, nil
+	}
+	var result = make([]float64, length)
+	for i := range result {
+		result[i] = math.NaN()
+		if i >= n.offset {
+			result[i] = x.num[i-n.offset]
+		}
+	}
+	return value{num: result}, nil
+}
+
+// window within：最近 bars 根K线内出现过；for：最近 bars 根K线都满足，K线数量不足时为 false
+func (e *evaluator) window(n *windowNode) (value, error) {
+	x, err := e.eval(n.x)
+	if err != nil {
+		return value{}, err
+	}
+	var result = make([]bool, len(x.cond))
+	// count 为最近 bars 根K线内满足条件的数量
+	var count int
+	for i, v := range x.cond {
+		if v {
+			count++
+		}
+		if i >= n.bars && x.cond[i-n.bars] {
+			count--
+		}
+		if n.op == "within" {
+			result[i] = count > 0
+		} else {
+			result[i] = i >= n.bars-1 && count == n.bars
+		}
+	}
+	return value{cond: result}, nil
+}
+
+func (e *evaluator) binary(n *binaryNode) (value, error) {
+	x, err := e.eval(n.x)
+	if err != nil {
+		return value{}, err
+	}
+	y, err := e.eval(n.y)
+	if err != nil {
+		return value{}, err
+	}
+	var length = len(e.item.Candles)
+
+	switch n.op {
+	case "and", "or":
+		var result = make([]bool, length)
+		for i := range result {
+			if n.op == "and" {
+				result[i] = x.cond[i] && y.cond[i]
+			} else {
+				result[i] = x.cond[i] || y.cond[i]
+			}
+		}
+		return value{cond: result}, nil
+	case "+", "-", "*", "/", "%":
+		var result = make([]float64, length)
+		for i := range result {
+			var a, b = x.num[i], y.num[i]
+			switch n.op {
+			case "+":
+				result[i] = a + b
+			case "-":
+				result[i] = a - b
+			case "*":
+				result[i] = a * b
+			case "/":
+				result[i] = a / b
+			case "%":
+				result[i] = math.Mod(a, b)
+			}
+		}
+		return value{num: result}, nil
+	}
+
+	// 比较与交叉，NaN 参与的比较为 false
+	var result = make([]bool, length)
+	for i := range result {
+		var a, b = x.num[i], y.num[i]
+		switch n.op {
+		case "<":
+			result[i] = a < b
+		case "<=":
+			result[i] = a <= b
+		case ">":
+			result[i] = a > b
+		case ">=":
+			result[i] = a >= b
+		case "==":
+			result[i] = a == b
+		case "!=":
+			result[i] = a != b && !math.IsNaN(a) && !math.IsNaN(b)
+		default:
+			if i < 1 {
+				continue
+			}
+			var pa, pb = x.num[i-1], y.num[i-1]
+			var above = a > b && pa <= pb
+			var below = a < b && pa >= pb
+			switch n.op {
+			case "crosses_above":
+				result[i] = above
+			case "crosses_below":
+				result[i] = below
+			default:
+				result[i] = above || below
+			}
+		}
+	}
+	return value{cond: result}, nil
+}
+
+// call 计算内置函数或已注册的指标
+func (e *evaluator) call(n *callNode) ([]float64, error) {
+	if b, ok := builtins[n.name]; ok {
+		var signature = b.signatures[len(n.args)]
+		var args [][]float64
+		var period int
+		for i, arg := range n.args {
+			if signature[i] == 'p' {
+				period = int(arg.(*numberNode).value)
+				continue
+			}
+			x, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, x.num)
+		}
+		if len(args) == 0 {
+			x, _ := e.eval(&identNode{pos: n.pos, name: b.source})
+			args = append(args, x.num)
+		}
+		e.pos = n.pos
+		// 周期内数据不足的K线没有值
+		var result = b.fn(args, period)
+		for i := 0; i < min(period-1, len(result)); i++ {
+			result[i] = math.NaN()
+		}
+		return result, nil
+	}
+
+	var params = make(map[string]interface{}, len(n.args)+len(n.named))
+	for i, arg := range n.args {
+		params[strconv.Itoa(i)], _ = constant(arg)
+	}
+	for _, arg := range n.named {
+		params[arg.name] = arg.value
+	}
+	indicator, err := registry.Build(e.item, n.name, params)
+	if err != nil {
+		return nil, e.fail(n.pos, err, "%v", err)
+	}
+	e.pos = n.pos
+	def, _ := registry.Get(n.name)
+	values, _ := def.Values(params)
+	return e.field(n, indicator, warmup(def, values))
+}
+
+// warmup 指标数据不足的K线数量，取周期类参数中的最大值，没有周期参数时为 0
+func warmup(def registry.Definition, values registry.Values) int {
+	var result int
+	for _, p := range def.Params {
+		if p.Type != registry.Int || !math.IsInf(p.Max, 1) {
+			continue
+		}
+		var name = strings.ToLower(p.Name)
+		if strings.Contains(name, "period") || strings.Contains(name, "length") || strings.HasPrefix(name, "smooth") ||
+			name == "short" || name == "long" || name == "signal" {
+			result = max(result, values.Int(p.Name))
+		}
+	}
+	return result
+}
+
+// field 通过反射读取指标 GetData() 中的一个字段，按时间与K线对齐，没有 Time 字段时按最后一根右对齐，
+// 开头 warmup 根K线没有值
+func (e *evaluator) field(n *callNode, indicator interface{}, warmup int) ([]float64, error) {
+	var method = reflect.ValueOf(indicator).MethodByName("GetData")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 || method.Type().Out(0).Kind() != reflect.Slice {
+		return nil, e.fail(n.pos, nil, "%s has no GetData", n.name)
+	}
+	var elem = method.Type().Out(0).Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, e.fail(n.pos, nil, "%s data is not a struct", n.name)
+	}
+
+	// 没有指定字段时使用 Value，没有 Value 时使用第一个数值字段
+	var index, first, timeIndex = -1, -1, -1
+	var names []string
+	for i := 0; i < elem.NumField(); i++ {
+		var f = elem.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Name == "Time" && f.Type == reflect.TypeOf(time.Time{}) {
+			timeIndex = i
+			continue
+		}
+		if f.Type.Kind() != reflect.Float64 && f.Type.Kind() != reflect.Int {
+			continue
+		}
+		names = append(names, f.Name)
+		if first < 0 {
+			first = i
+		}
+		if n.field != "" && strings.EqualFold(f.Name, n.field) || n.field == "" && f.Name == "Value" {
+			index = i
+		}
+	}
+	if index < 0 && n.field == "" {
+		index = first
+	}
+	if index < 0 {
+		return nil, e.fail(n.pos, nil, "%s has no field %q, available: %s", n.name, n.field, strings.Join(names, ", "))
+	}
+
+	var data = method.Call(nil)[0]
+	var length = len(e.item.Candles)
+	var result = make([]float64, length)
+	for i := range result {
+		result[i] = math.NaN()
+	}
+	var positions map[int64]int
+	if timeIndex >= 0 {
+		positions = make(map[int64]int, length)
+		for i, candle := range e.item.Candles {
+			positions[candle.TimeUnix] = i
+		}
+	}
+	var offset = length - data.Len()
+	for x := 0; x < data.Len(); x++ {
+		var v = data.Index(x)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		var i = x + offset
+		if positions != nil {
+			var ok bool
+			if i, ok = positions[v.Field(timeIndex).Interface().(time.Time).Unix()]; !ok {
+				continue
+			}
+		}
+		if i < 0 || i >= length {
+			continue
+		}
+		if f := v.Field(index); f.Kind() == reflect.Int {
+			result[i] = float64(f.Int())
+		} else {
+			result[i] = f.Float()
+		}
+	}
+	// 指标在数据不足时通常填 0，按周期把开头的K线视为没有值，之后的 0 是真实的数值
+	for i := 0; i < min(warmup, length); i++ {
+		result[i] = math.NaN()
+	}
+	return result, nil
+}
@@ -0,0 +1,471 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value float64
+}
+
+// lex 把表达式拆分为词法单元，标识符统一转换为小写，位置为字节偏移
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	// scan 从 i 开始跳过满足 fn 的字符，返回结束位置
+	var scan = func(i int, fn func(c rune) bool) int {
+		for i < len(expr) {
+			var c, size = utf8.DecodeRuneInString(expr[i:])
+			if !fn(c) {
+				break
+			}
+			i += size
+		}
+		return i
+	}
+	var isDigit = func(c rune) bool { return c >= '0' && c <= '9' }
+	var isIdent = func(c rune) bool { return unicode.IsLetter(c) || isDigit(c) || c == '_' }
+
+	for i := 0; i < len(expr); {
+		var c, size = utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case isDigit(c) || c == '.' && i+1 < len(expr) && isDigit(rune(expr[i+1])):
+			var start = i
+			i = scan(i, func(c rune) bool { return isDigit(c) || c == '.' })
+			value, err := strconv.ParseFloat(expr[start:i], 64)
+			if err != nil {
+				return nil, newError(expr, start, "invalid number %q", expr[start:i])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[start:i], pos: start, value: value})
+		case unicode.IsLetter(c) || c == '_':
+			var start = i
+			i = scan(i, isIdent)
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(expr[start:i]), pos: start})
+		default:
+			var op string
+			for _, v := range []string{"<=", ">=", "==", "!=", "<", ">", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", ".", "="} {
+				if strings.HasPrefix(expr[i:], v) {
+					op = v
+					break
+				}
+			}
+			if op == "" {
+				return nil, newError(expr, i, "unexpected character %q", c)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// kind 表达式的类型
+type kind int
+
+const (
+	numberKind kind = iota
+	boolKind
+)
+
+func (k kind) String() string {
+	if k == boolKind {
+		return "condition"
+	}
+	return "number"
+}
+
+// node 语法树节点
+type node interface {
+	position() int
+}
+
+type numberNode struct {
+	pos   int
+	value float64
+}
+
+type boolNode struct {
+	pos   int
+	value bool
+}
+
+// identNode 价格序列，例如 close、hl2
+type identNode struct {
+	pos  int
+	name string
+}
+
+// callNode 函数或指标调用，field 为 .Field 选择的指标数据字段
+type callNode struct {
+	pos   int
+	name  string
+	args  []node
+	named []namedArg
+	field string
+	// 原始文本，用于缓存相同的调用
+	text string
+}
+
+// namedArg 指标的命名参数，例如 maType=EMA
+type namedArg struct {
+	pos   int
+	name  string
+	value string
+}
+
+type unaryNode struct {
+	pos int
+	op  string
+	x   node
+}
+
+type binaryNode struct {
+	pos  int
+	op   string
+	x, y node
+}
+
+// indexNode 回看 offset 根K线，例如 close[1]
+type indexNode struct {
+	pos    int
+	x      node
+	offset int
+}
+
+// windowNode within、for N bars
+type windowNode struct {
+	pos  int
+	op   string
+	x    node
+	bars int
+}
+
+func (n *numberNode) position() int { return n.pos }
+func (n *boolNode) position() int   { return n.pos }
+func (n *identNode) position() int  { return n.pos }
+func (n *callNode) position() int   { return n.pos }
+func (n *unaryNode) position() int  { return n.pos }
+func (n *binaryNode) position() int { return n.pos }
+func (n *indexNode) position() int  { return n.pos }
+func (n *windowNode) position() int { return n.pos }
+
+// parser 递归下降语法分析，优先级从低到高：
+//
+//	or
+//	and
+//	not
+//	within、for N bars（作用于前面的比较）
+//	< <= > >= == != crosses_above crosses_below crosses
+//	+ -
+//	* / %
+//	一元 -
+//	[N] 回看、.Field 字段
+type parser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	var t = p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is 当前词法单元是否为给定的运算符或关键字
+func (p *parser) is(texts ...string) bool {
+	var t = p.peek()
+	if t.kind != tokenOp && t.kind != tokenIdent {
+		return false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(text string) (token, error) {
+	if !p.is(text) {
+		return token{}, p.unexpected(fmt.Sprintf("%q", text))
+	}
+	return p.next(), nil
+}
+
+// unexpected 当前词法单元不是 want
+func (p *parser) unexpected(want string) error {
+	var t = p.peek()
+	if t.kind == tokenEOF {
+		return newError(p.expr, t.pos, "expected %s, got end of expression", want)
+	}
+	return newError(p.expr, t.pos, "expected %s, got %q", want, t.text)
+}
+
+func (p *parser) parseOr() (node, error) {
+	var x, err = p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("or") {
+		var op = p.next()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{pos: op.pos, op: op.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	var x, err = p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("and") {
+		var op = p.next()
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{pos: op.pos, op: op.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.is("not") {
+		var op = p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: op.pos, op: op.text, x: x}, nil
+	}
+	return p.parseWindow()
+}
+
+func (p *parser) parseWindow() (node, error) {
+	var x, err = p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("within", "for") {
+		var op = p.next()
+		var t = p.peek()
+		if t.kind != tokenNumber {
+			return nil, p.unexpected("number of bars")
+		}
+		p.next()
+		if t.value < 1 || t.value != float64(int(t.value)) {
+			return nil, newError(p.expr, t.pos, "number of bars must be a positive integer, got %s", t.text)
+		}
+		if p.is("bars", "bar") {
+			p.next()
+		}
+		x = &windowNode{pos: op.pos, op: op.text, x: x, bars: int(t.value)}
+	}
+	return x, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	var x, err = p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if p.is("<", "<=", ">", ">=", "==", "!=", "crosses_above", "crosses_below", "crosses") {
+		var op = p.next()
+		y, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{pos: op.pos, op: op.text, x: x, y: y}
+		if p.is("<", "<=", ">", ">=", "==", "!=", "crosses_above", "crosses_below", "crosses") {
+			return nil, newError(p.expr, p.peek().pos, "comparisons cannot be chained, use \"and\"")
+		}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	var x, err = p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+", "-") {
+		var op = p.next()
+		y, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{pos: op.pos, op: op.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	var x, err = p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*", "/", "%") {
+		var op = p.next()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{pos: op.pos, op: op.text, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.is("-") {
+		var op = p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: op.pos, op: op.text, x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	var x, err = p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.is("["):
+			var open = p.next()
+			var t = p.peek()
+			if t.kind != tokenNumber {
+				return nil, p.unexpected("number of bars to look back")
+			}
+			p.next()
+			if t.value != float64(int(t.value)) {
+				return nil, newError(p.expr, t.pos, "lookback must be a non-negative integer, got %s", t.text)
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{pos: open.pos, x: x, offset: int(t.value)}
+		case p.is("."):
+			var dot = p.next()
+			var call, ok = x.(*callNode)
+			if !ok || call.field != "" {
+				return nil, newError(p.expr, dot.pos, "field selection is only allowed after an indicator call")
+			}
+			var t = p.peek()
+			if t.kind != tokenIdent {
+				return nil, p.unexpected("field name")
+			}
+			p.next()
+			call.field = t.text
+			call.text += "." + t.text
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	var t = p.peek()
+	switch {
+	case t.kind == tokenNumber:
+		p.next()
+		return &numberNode{pos: t.pos, value: t.value}, nil
+	case p.is("("):
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case t.kind == tokenIdent:
+		if keywords[t.text] {
+			return nil, newError(p.expr, t.pos, "unexpected keyword %q", t.text)
+		}
+		p.next()
+		if t.text == "true" || t.text == "false" {
+			return &boolNode{pos: t.pos, value: t.text == "true"}, nil
+		}
+		if !p.is("(") {
+			return &identNode{pos: t.pos, name: t.text}, nil
+		}
+		return p.parseCall(t)
+	}
+	return nil, p.unexpected("number, price, function or \"(\"")
+}
+
+// parseCall 解析函数调用的参数，命名参数的值为数字或标识符
+func (p *parser) parseCall(name token) (node, error) {
+	var call = &callNode{pos: name.pos, name: name.text}
+	p.next()
+	for !p.is(")") {
+		if len(call.args)+len(call.named) > 0 {
+			if !p.is(",") {
+				return nil, p.unexpected("\",\" or \")\"")
+			}
+			p.next()
+		}
+		var t = p.peek()
+		if t.kind == tokenIdent && p.tokens[p.pos+1].text == "=" && p.tokens[p.pos+1].kind == tokenOp {
+			p.next()
+			p.next()
+			var value = p.next()
+			if value.kind != tokenNumber && value.kind != tokenIdent {
+				return nil, newError(p.expr, value.pos, "expected value of parameter %q", t.text)
+			}
+			call.named = append(call.named, namedArg{pos: t.pos, name: t.text, value: value.text})
+			continue
+		}
+		if len(call.named) > 0 {
+			return nil, newError(p.expr, t.pos, "positional argument after named argument")
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	var close = p.next()
+	call.text = strings.ToLower(strings.Join(strings.Fields(p.expr[name.pos:close.pos+1]), ""))
+	return call, nil
+}
+
+// keywords 不能作为价格或函数名称的关键字
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "within": true, "for": true, "bars": true, "bar": true,
+	"crosses_above": true, "crosses_below": true, "crosses": true,
+}
@@ -0,0 +1,319 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+var (
+	// ErrSyntax 表达式语法或类型错误
+	ErrSyntax = errors.New("rules: syntax error")
+	// ErrEvaluate 计算表达式时出错，例如指标参数不正确或K线数量不足
+	ErrEvaluate = errors.New("rules: evaluation error")
+)
+
+// Error 表达式错误，Pos 为出错位置在表达式中的字节偏移，Error() 中显示为字符序号
+type Error struct {
+	Expr string
+	Pos  int
+	Msg  string
+	// ErrSyntax 或 ErrEvaluate
+	Kind error
+	// 计算时底层的错误，例如 registry.ErrInvalidParam
+	Err error
+}
+
+func newError(expr string, pos int, format string, args ...interface{}) *Error {
+	return &Error{Expr: expr, Pos: pos, Msg: fmt.Sprintf(format, args...), Kind: ErrSyntax}
+}
+
+// Error 返回出错信息与位置，位置为从 1 开始的字符序号，例如
+//
+//	rules: unknown function "emaa" at position 21: close crosses_above >>>emaa(20)
+func (e *Error) Error() string {
+	var pos = min(max(e.Pos, 0), len(e.Expr))
+	return fmt.Sprintf("rules: %s at position %d: %s>>>%s", e.Msg, utf8.RuneCountInString(e.Expr[:pos])+1, e.Expr[:pos], e.Expr[pos:])
+}
+
+// Context 返回表达式与指向出错位置的 ^，用于多行显示
+//
+//	close crosses_above emaa(20)
+//	                    ^
+func (e *Error) Context() string {
+	var pos = min(max(e.Pos, 0), len(e.Expr))
+	return e.Expr + "\n" + strings.Repeat(" ", utf8.RuneCountInString(e.Expr[:pos])) + "^"
+}
+
+// Unwrap 返回错误类型与底层错误，可以使用 errors.Is(err, rules.ErrSyntax) 判断
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// Rule 一个已解析的条件表达式
+//
+// 表达式由价格（open、high、low、close、volume、hl2、hlc3、ohlc4）、数字、函数与已注册的指标组成：
+//
+//	close crosses_above ema(20) and rsi(14) < 30 within 3 bars
+//	macd(12,9,26).DIF > macd(12,9,26).DEA for 2 bars
+//	(close - close[5]) / close[5] * 100 > 3 or not (volume > sma(volume, 20) * 2)
+//
+// 比较运算 < <= > >= == != 与 crosses_above、crosses_below、crosses 返回条件，
+// within N bars 表示前面的条件在最近 N 根K线（包含当前K线）内出现过，for N bars 表示最近 N 根K线都满足，
+// 两者只作用于紧挨着的比较，作用于整个组合条件时需要加括号。[N] 表示 N 根K线之前的值。
+//
+// 内置函数：sma、ema、wma、rma(source, period)，highest、lowest(source, period)，stdev(source, period)，
+// change(source, bars)，abs(x)，min(a, b)，max(a, b)，source 省略时使用收盘价（highest 使用最高价，lowest 使用最低价）。
+// 其他函数按名称在 utils/registry 中查找，需要先导入指标所在的包，参数与 registry.ParseSpec 相同，
+// 使用 .Field 选择 GetData() 中的字段，省略时使用 Value 字段，没有 Value 字段时使用第一个数值字段。
+// 函数在周期内数据不足、指标开头不足最大周期参数的K线没有值，参与的比较为 false。
+type Rule struct {
+	Expr string
+	root node
+}
+
+// Parse 解析条件表达式，表达式的结果必须是条件
+func Parse(expr string) (*Rule, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	var p = &parser{expr: expr, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected("operator or end of expression")
+	}
+	k, err := check(expr, root)
+	if err != nil {
+		return nil, err
+	}
+	if k != boolKind {
+		return nil, newError(expr, root.position(), "expression is a number, expected a condition such as \"> 0\"")
+	}
+	return &Rule{Expr: expr, root: root}, nil
+}
+
+// MustParse 与 Parse 相同，出错时 panic，用于固定的表达式
+func MustParse(expr string) *Rule {
+	rule, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// String Func
+func (r *Rule) String() string {
+	return r.Expr
+}
+
+// Evaluate 计算每根K线是否满足条件，回看或指标数据不足的K线为 false
+func (r *Rule) Evaluate(klineItem *klines.Item) (result []bool, err error) {
+	var e = &evaluator{expr: r.Expr, item: klineItem, cache: map[string][]float64{}}
+	// 指标在K线数量少于周期时可能越界
+	defer func() {
+		if v := recover(); v != nil {
+			result, err = nil, &Error{Expr: r.Expr, Pos: e.pos, Msg: fmt.Sprint(v), Kind: ErrEvaluate}
+		}
+	}()
+	value, err := e.eval(r.root)
+	if err != nil {
+		return nil, err
+	}
+	return value.cond, nil
+}
+
+// Triggered 最后一根K线是否满足条件，用于提醒
+func (r *Rule) Triggered(klineItem *klines.Item) (bool, error) {
+	result, err := r.Evaluate(klineItem)
+	if err != nil || len(result) == 0 {
+		return false, err
+	}
+	return result[len(result)-1], nil
+}
+
+// SideData 满足条件的K线为 side，其他K线为 Hold
+func (r *Rule) SideData(klineItem *klines.Item, side utils.Side) (utils.SideData, error) {
+	result, err := r.Evaluate(klineItem)
+	if err != nil {
+		return utils.SideData{}, err
+	}
+	var sides = make([]utils.Side, len(result))
+	for i, v := range result {
+		sides[i] = utils.Hold
+		if v {
+			sides[i] = side
+		}
+	}
+	return utils.SideData{Name: r.Expr, Data: sides}, nil
+}
+
+// Strategy 使用买入与卖出两个条件的策略，实现了 utils.IStrategy，可以直接用于组合策略与回测
+type Strategy struct {
+	Name string
+	Buy  *Rule
+	Sell *Rule
+	data []utils.Side
+}
+
+// NewStrategy new Func
+// 同一根K线同时满足买入与卖出条件时不操作，sell 为空时只有买入信号。
+// 表达式在创建时计算，错误在这里返回。
+//
+//	strategy, err := rules.NewStrategy(list, "close crosses_above ema(20) and rsi(14) < 30 within 3 bars", "close crosses_below ema(20)")
+func NewStrategy(klineItem *klines.Item, buy, sell string) (*Strategy, error) {
+	var e = &Strategy{Name: fmt.Sprintf("Rules(%s; %s)", buy, sell)}
+	var err error
+	if e.Buy, err = Parse(buy); err != nil {
+		return nil, err
+	}
+	var sells = make([]bool, len(klineItem.Candles))
+	if strings.TrimSpace(sell) != "" {
+		if e.Sell, err = Parse(sell); err != nil {
+			return nil, err
+		}
+		if sells, err = e.Sell.Evaluate(klineItem); err != nil {
+			return nil, err
+		}
+	}
+	buys, err := e.Buy.Evaluate(klineItem)
+	if err != nil {
+		return nil, err
+	}
+
+	e.data = make([]utils.Side, len(buys))
+	for i := range buys {
+		e.data[i] = utils.Hold
+		if buys[i] && !sells[i] {
+			e.data[i] = utils.Buy
+		} else if sells[i] && !buys[i] {
+			e.data[i] = utils.Sell
+		}
+	}
+	return e, nil
+}
+
+// AnalysisSide Func
+func (e *Strategy) AnalysisSide() utils.SideData {
+	return utils.SideData{
+		Name: e.Name,
+		Data: e.data,
+	}
+}
+
+// check 检查函数名称、参数数量与运算的类型，返回表达式的类型
+func check(expr string, n node) (kind, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return numberKind, nil
+	case *boolNode:
+		return boolKind, nil
+	case *identNode:
+		if _, ok := prices[n.name]; !ok {
+			return 0, newError(expr, n.pos, "unknown price %q, expected one of open, high, low, close, volume, hl2, hlc3, ohlc4", n.name)
+		}
+		return numberKind, nil
+	case *indexNode:
+		return check(expr, n.x)
+	case *unaryNode:
+		var want = numberKind
+		if n.op == "not" {
+			want = boolKind
+		}
+		return want, checkOperand(expr, n.x, want, n.op)
+	case *windowNode:
+		return boolKind, checkOperand(expr, n.x, boolKind, n.op)
+	case *binaryNode:
+		var want, result = numberKind, boolKind
+		switch n.op {
+		case "and", "or":
+			want = boolKind
+		case "+", "-", "*", "/", "%":
+			result = numberKind
+		}
+		if err := checkOperand(expr, n.x, want, n.op); err != nil {
+			return 0, err
+		}
+		return result, checkOperand(expr, n.y, want, n.op)
+	case *callNode:
+		return numberKind, checkCall(expr, n)
+	}
+	return 0, newError(expr, n.position(), "unsupported expression")
+}
+
+func checkOperand(expr string, n node, want kind, op string) error {
+	k, err := check(expr, n)
+	if err != nil {
+		return err
+	}
+	if k != want {
+		return newError(expr, n.position(), "%q expects a %s, got a %s", op, want, k)
+	}
+	return nil
+}
+
+// checkCall 检查内置函数的参数，或者指标是否已注册
+func checkCall(expr string, n *callNode) error {
+	if b, ok := builtins[n.name]; ok {
+		if len(n.named) > 0 {
+			return newError(expr, n.named[0].pos, "%s does not take named arguments", n.name)
+		}
+		if n.field != "" {
+			return newError(expr, n.pos, "%s has no fields", n.name)
+		}
+		var signature, ok = b.signatures[len(n.args)]
+		if !ok {
+			return newError(expr, n.pos, "%s expects %s", n.name, b.usage)
+		}
+		for i, arg := range n.args {
+			if signature[i] == 'p' {
+				if v, ok := arg.(*numberNode); !ok || v.value < 1 || v.value != float64(int(v.value)) {
+					return newError(expr, arg.position(), "%s expects a positive integer period", n.name)
+				}
+				continue
+			}
+			if err := checkOperand(expr, arg, numberKind, n.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	def, ok := registry.Get(n.name)
+	if !ok {
+		return newError(expr, n.pos, "unknown function %q", n.name)
+	}
+	if len(n.args)+len(n.named) > len(def.Params) {
+		return newError(expr, n.pos, "%s takes at most %d parameters", def.Name, len(def.Params))
+	}
+	for _, arg := range n.args {
+		if _, ok := constant(arg); !ok {
+			return newError(expr, arg.position(), "%s parameters must be numbers", def.Name)
+		}
+	}
+	return nil
+}
+
+// constant 数字或负数
+func constant(n node) (float64, bool) {
+	switch n := n.(type) {
+	case *numberNode:
+		return n.value, true
+	case *unaryNode:
+		if v, ok := constant(n.x); ok && n.op == "-" {
+			return -v, true
+		}
+	}
+	return 0, false
+}
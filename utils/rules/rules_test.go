@@ -0,0 +1,249 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	// 注册 rsi、boll 等指标
+	_ "github.com/idoall/stockindicator/channel"
+	"github.com/idoall/stockindicator/trend"
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+	_ "github.com/idoall/stockindicator/volume"
+)
+
+// panicIndicator K线数量少于周期时越界
+type panicIndicator struct {
+	item   *klines.Item
+	period int
+}
+
+func (e panicIndicator) GetData() []trend.RsiData {
+	return []trend.RsiData{{Value: e.item.Candles[e.period].Close}}
+}
+
+func init() {
+	registry.Register(registry.Definition{
+		Name: "rulesPanic", Category: registry.Trend,
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return panicIndicator{item, p.Int("period")}
+		},
+	})
+}
+
+func evaluate(t *testing.T, item *klines.Item, expr string) []bool {
+	t.Helper()
+	rule, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	result, err := rule.Evaluate(item)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+	return result
+}
+
+// RUN
+// go test -v ./utils/rules -run TestEvaluate
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 1, 10, 11, 12, 11, 13)
+	const f, T = false, true
+	for _, tc := range []struct {
+		expr string
+		want []bool
+	}{
+		{"close > close[1]", []bool{f, T, T, f, T}},
+		{"(close - close[1]) * 2 >= 2", []bool{f, T, T, f, T}},
+		{"-close < -10.5", []bool{f, T, T, T, T}},
+		{"close % 2 == 1 and high - low == 2", []bool{f, T, f, T, T}},
+		{"close crosses_above 11.5", []bool{f, f, T, f, T}},
+		{"close crosses_below 11.5", []bool{f, f, f, T, f}},
+		{"close crosses 11.5", []bool{f, f, T, T, T}},
+		{"close > close[1] within 2 bars", []bool{f, T, T, T, T}},
+		{"close > close[1] for 2 bars", []bool{f, f, T, f, f}},
+		{"(close > close[1])[1]", []bool{f, f, T, T, f}},
+		// and 的优先级高于 or
+		{"close > 12 or close < 11 and false", []bool{f, f, f, f, T}},
+		{"not (close > 12 or close < 11)", []bool{f, T, T, T, f}},
+		{"CLOSE == hlc3 and hl2 == ohlc4 and volume == 100 and open == close", []bool{T, T, T, T, T}},
+		// 函数在周期内数据不足时没有值
+		{"close >= sma(2)", []bool{f, T, T, f, T}},
+		{"sma(close, 2) == (close + close[1]) / 2", []bool{f, T, T, T, T}},
+		{"highest(3) == high", []bool{f, f, T, f, T}},
+		{"lowest(low, 2) == low[1]", []bool{f, T, T, f, T}},
+		{"change(close) == close - close[1] and change(close, 2) >= 1", []bool{f, f, T, f, T}},
+		{"max(close, 11) == 11 and abs(close - 11) <= min(1, 2)", []bool{T, T, f, T, f}},
+	} {
+		if got := evaluate(t, list, tc.expr); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils/rules -run TestIndicator
+func TestIndicator(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(100, 1)
+	var rsi = trend.NewRsi(list, 14).GetData()
+
+	// 默认使用 Value 字段，开头数据不足的K线为 false
+	var got = evaluate(t, list, "rsi(14) > 50")
+	for i, v := range rsi {
+		if want := v.Value != 0 && v.Value > 50; got[i] != want {
+			t.Fatalf("bar %d: got %v, rsi %v", i, got[i], v.Value)
+		}
+	}
+	got = evaluate(t, list, "rsi(period=14) <= 50")
+	for i, v := range rsi {
+		if want := v.Value != 0 && v.Value <= 50; got[i] != want {
+			t.Fatalf("named bar %d: got %v, rsi %v", i, got[i], v.Value)
+		}
+	}
+
+	got = evaluate(t, list, "Boll(20,2).Upper > boll(20, 2).lower and boll(20,2).middle == sma(20)")
+	for i := 30; i < len(got); i++ {
+		if !got[i] {
+			t.Fatalf("boll bar %d: got false", i)
+		}
+	}
+
+	// 周期之后等于 0 的数值是真实的值，没有周期参数的指标从第一根K线开始有值
+	var flat = make([]float64, 40)
+	for i := range flat {
+		flat[i] = 10
+	}
+	var item = utils.GetCloseKlineItem(klines.OneDay, 1, flat...)
+	for i, v := range evaluate(t, item, "obv() >= 0") {
+		if !v {
+			t.Fatalf("obv bar %d: got false", i)
+		}
+	}
+	for i, v := range evaluate(t, item, "macd(12, 9, 26).macd == 0") {
+		if want := i >= 26; v != want {
+			t.Fatalf("macd bar %d: got %v", i, v)
+		}
+	}
+
+	rule, err := Parse("close crosses_above ema(20) and rsi(14) < 30 within 3 bars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cross, oversold = evaluate(t, list, "close crosses_above ema(20)"), evaluate(t, list, "rsi(14) < 30 within 3 bars")
+	result, err := rule.Evaluate(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range result {
+		if result[i] != (cross[i] && oversold[i]) {
+			t.Fatalf("bar %d: got %v", i, result[i])
+		}
+	}
+}
+
+// RUN
+// go test -v ./utils/rules -run TestErrors
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"close >", 7, "got end of expression"},
+		{"close > emaa(20)", 8, `unknown function "emaa"`},
+		{"close + 1", 6, "expected a condition"},
+		{"close > 1 and 2", 14, `"and" expects a condition, got a number`},
+		{"close @ 1", 6, "unexpected character"},
+		{"close > sma(close)", 12, "positive integer period"},
+		{"close > sma(20, 1, 2)", 8, "sma expects (period) or (source, period)"},
+		{"close > close[1.5]", 14, "non-negative integer"},
+		{"close > 1 within 0 bars", 17, "positive integer"},
+		{"close.upper > 1", 5, "only allowed after an indicator call"},
+		{"close > 1 > 2", 10, "cannot be chained"},
+		{"(close > 1", 10, `expected ")"`},
+		{"foo > 1", 0, `unknown price "foo"`},
+		{"close > 1 close", 10, "expected operator or end of expression"},
+		{"rsi(close) > 1", 4, "parameters must be numbers"},
+	} {
+		_, err := Parse(tc.expr)
+		var e *Error
+		if !errors.As(err, &e) || !errors.Is(err, ErrSyntax) {
+			t.Fatalf("%s: got %v", tc.expr, err)
+		}
+		if e.Pos != tc.pos || !strings.Contains(e.Msg, tc.msg) {
+			t.Errorf("%s: got %q at %d, want %q at %d", tc.expr, e.Msg, e.Pos, tc.msg, tc.pos)
+		}
+	}
+
+	_, err := Parse("close crosses_above emaa(20)")
+	if want := `rules: unknown function "emaa" at position 21: close crosses_above >>>emaa(20)`; err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %s", err, want)
+	}
+	var e *Error
+	errors.As(err, &e)
+	if want := "close crosses_above emaa(20)\n                    ^"; e.Context() != want {
+		t.Fatalf("got\n%s\nwant\n%s", e.Context(), want)
+	}
+
+	// 中文输入法的全角括号，位置按字符计算
+	_, err = Parse("rsi（14） < 30")
+	if want := `rules: unexpected character '（' at position 4: rsi>>>（14） < 30`; err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %s", err, want)
+	}
+	_, err = Parse("价格 > 1 and close >")
+	if want := `rules: expected number, price, function or "(", got end of expression at position 19: 价格 > 1 and close >>>>`; err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %s", err, want)
+	}
+
+	// 参数取值范围在计算时由 registry 检查
+	_, err = MustParse("close > 1 and rsi(period=0) < 30").Evaluate(utils.GetCloseKlineItem(klines.OneDay, 1, 1, 2, 3))
+	if !errors.As(err, &e) || !errors.Is(err, ErrEvaluate) || !errors.Is(err, registry.ErrInvalidParam) || e.Pos != 14 {
+		t.Fatalf("got %v", err)
+	}
+}
+
+// RUN
+// go test -v ./utils/rules -run TestStrategy
+func TestStrategy(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetCloseKlineItem(klines.OneDay, 1, 10, 11, 12, 11, 13)
+	strategy, err := NewStrategy(list, "close crosses_above 11.5", "close crosses_below 11.5 or close > 12.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ utils.IStrategy = strategy
+	var want = []utils.Side{utils.Hold, utils.Hold, utils.Buy, utils.Sell, utils.Hold}
+	if got := strategy.AnalysisSide().Data; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	var rule = MustParse("close > close[1]")
+	sides, err := rule.SideData(list, utils.Buy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []utils.Side{utils.Hold, utils.Buy, utils.Buy, utils.Hold, utils.Buy}; !reflect.DeepEqual(sides.Data, want) {
+		t.Fatalf("side data: got %v", sides.Data)
+	}
+	if triggered, err := rule.Triggered(list); err != nil || !triggered {
+		t.Fatalf("triggered: got %v, %v", triggered, err)
+	}
+
+	if _, err := NewStrategy(list, "close >", ""); !errors.Is(err, ErrSyntax) {
+		t.Fatalf("got %v, want ErrSyntax", err)
+	}
+	// K线数量少于周期时指标的 panic 转换为错误
+	if _, err := MustParse("close > 0 and rulesPanic(14) > 0").Evaluate(utils.GetCloseKlineItem(klines.OneDay, 1, 1, 2, 3)); !errors.Is(err, ErrEvaluate) {
+		t.Fatalf("got %v, want ErrEvaluate", err)
+	}
+	if result, err := MustParse("close > 0").Evaluate(&klines.Item{}); err != nil || len(result) != 0 {
+		t.Fatalf("empty item: %v, %v", result, err)
+	}
+}
@@ -0,0 +1,163 @@
+package ta
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// 使用 decimal 精确计算的基础函数，与同名的 float64 版本计算方式一致。
+// 加、减、乘没有误差，除法保留 decimal.DivisionPrecision 位小数（默认 16 位），
+// 适合累计类（Obv、A/D、VPT）以及递推类（Ema）指标与交易所对账。
+
+// DecimalFromFloats 把 float64 转换为 decimal，使用能还原该 float64 的最短十进制表示，0.1 会转换为精确的 0.1
+func DecimalFromFloats(values []float64) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	for i, v := range values {
+		result[i] = decimal.NewFromFloat(v)
+	}
+	return result
+}
+
+// DecimalToFloats 把 decimal 转换为 float64
+func DecimalToFloats(values []decimal.Decimal) []float64 {
+	result := make([]float64, len(values))
+	for i, v := range values {
+		result[i], _ = v.Float64()
+	}
+	return result
+}
+
+// DecimalAdd 将 values1 与 values2 数组元素相加.
+func DecimalAdd(values1, values2 []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i].Add(values2[i])
+	}
+	return result
+}
+
+// DecimalSubtract 将 values1 与 values2 数组元素相减.
+func DecimalSubtract(values1, values2 []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i].Sub(values2[i])
+	}
+	return result
+}
+
+// DecimalMultiply 将 values1 与 values2 数组元素相乘.
+func DecimalMultiply(values1, values2 []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i].Mul(values2[i])
+	}
+	return result
+}
+
+// DecimalMultiplyBy 对 values 数组元素遍历乘以 multiplier.
+func DecimalMultiplyBy(values []decimal.Decimal, multiplier decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	for i, value := range values {
+		result[i] = value.Mul(multiplier)
+	}
+	return result
+}
+
+// DecimalDivide 将 values1 与 values2 数组元素相除，除数为 0 时结果为 0，与 Divide 一致.
+func DecimalDivide(values1, values2 []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values1))
+	for i := 0; i < len(result); i++ {
+		if values2[i].IsZero() {
+			continue
+		}
+		result[i] = values1[i].Div(values2[i])
+	}
+	return result
+}
+
+// DecimalShiftRightAndFillBy 将 values 数组的值按 period 右移，填充 fill
+func DecimalShiftRightAndFillBy(period int, fill decimal.Decimal, values []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	for i := 0; i < len(result); i++ {
+		if i < period {
+			result[i] = fill
+		} else {
+			result[i] = values[i-period]
+		}
+	}
+	return result
+}
+
+// DecimalDiff 按 before 右移后，再进行减法运算.
+func DecimalDiff(values []decimal.Decimal, before int) []decimal.Decimal {
+	return DecimalSubtract(values, DecimalShiftRightAndFillBy(before, decimal.Zero, values))
+}
+
+// DecimalSum 遍历数组 values，从左向右在 period 周期内总和，period 不小于数组长度时为累计总和.
+func DecimalSum(period int, values []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	sum := decimal.Zero
+
+	for i := 0; i < len(values); i++ {
+		sum = sum.Add(values[i])
+		if i >= period {
+			sum = sum.Sub(values[i-period])
+		}
+		result[i] = sum
+	}
+	return result
+}
+
+// DecimalSma 简单移动平均，前 period 个值使用已有数据的平均值，与 Sma 一致
+func DecimalSma(period int, values []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	sum := decimal.Zero
+
+	for i, value := range values {
+		count := i + 1
+		sum = sum.Add(value)
+		if i >= period {
+			sum = sum.Sub(values[i-period])
+			count = period
+		}
+		result[i] = sum.Div(decimal.NewFromInt(int64(count)))
+	}
+	return result
+}
+
+// DecimalEma 指数移动平均，E[i] = (2 * v[i] + (period - 1) * E[i-1]) / (period + 1)，与 Ema 一致
+func DecimalEma(period int, values []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	var two = decimal.NewFromInt(2)
+	var weight = decimal.NewFromInt(int64(period - 1))
+	var divisor = decimal.NewFromInt(int64(period + 1))
+
+	for i, value := range values {
+		if i > 0 {
+			result[i] = two.Mul(value).Add(weight.Mul(result[i-1])).Div(divisor)
+		} else {
+			result[i] = value
+		}
+	}
+	return result
+}
+
+// DecimalRma 滚动移动平均，前 period 个值为 Sma，之后 R[i] = (R[i-1] * (period - 1) + v[i]) / period，与 Rma 一致
+func DecimalRma(period int, values []decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	sum := decimal.Zero
+
+	for i, value := range values {
+		if i < 1 {
+			continue
+		}
+		count := i + 1
+		if i < period {
+			sum = sum.Add(value)
+		} else {
+			sum = result[i-1].Mul(decimal.NewFromInt(int64(period - 1))).Add(value)
+			count = period
+		}
+		result[i] = sum.Div(decimal.NewFromInt(int64(count)))
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package ta_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/shopspring/decimal"
+)
+
+// go test -v ./utils/ta -run ^TestDecimal$
+func TestDecimal(t *testing.T) {
+	var values = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24,
+		22.29, 22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83,
+	}
+	var decimals = ta.DecimalFromFloats(values)
+
+	var tests = []struct {
+		name     string
+		expected []float64
+		received []decimal.Decimal
+	}{
+		{"Sma", ta.Sma(5, values), ta.DecimalSma(5, decimals)},
+		{"Ema", ta.Ema(5, values), ta.DecimalEma(5, decimals)},
+		{"Rma", ta.Rma(5, values), ta.DecimalRma(5, decimals)},
+		{"Sum", ta.Sum(5, values), ta.DecimalSum(5, decimals)},
+		{"Diff", ta.Diff(values, 2), ta.DecimalDiff(decimals, 2)},
+		{"Divide", ta.Divide(values, ta.ShiftRight(1, values)), ta.DecimalDivide(decimals, ta.DecimalShiftRightAndFillBy(1, decimal.Zero, decimals))},
+	}
+	for _, test := range tests {
+		var received = ta.DecimalToFloats(test.received)
+		for i := range test.expected {
+			if math.Abs(received[i]-test.expected[i]) > 1e-9 {
+				t.Fatalf("%s[%d] received %f expected %f", test.name, i, received[i], test.expected[i])
+			}
+		}
+	}
+
+	// 0.1 累加 10 次，float64 存在误差，decimal 精确等于 1
+	var tenths = make([]float64, 10)
+	for i := range tenths {
+		tenths[i] = 0.1
+	}
+	if sum := ta.Sum(10, tenths)[9]; sum == 1 {
+		t.Fatalf("expected float64 drift, got %v", sum)
+	}
+	if sum := ta.DecimalSum(10, ta.DecimalFromFloats(tenths))[9]; !sum.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("received %s expected 1", sum)
+	}
+}
@@ -0,0 +1,410 @@
+package ta
+
+import (
+	"math"
+
+	"github.com/idoall/stockindicator/container/bst"
+	"github.com/idoall/stockindicator/utils/commonutils"
+)
+
+// 泛型版本的基础函数，可以直接计算 float32 或整数序列，不需要先转换为 []float64。
+// 同名的 float64 函数是这些函数的简单包装，计算结果完全一致。
+//
+// 加、减、乘与求和使用 T 计算，整数序列的求和没有误差；
+// 均线、除法、方差、最大最小值等使用 float64 计算后再转换为 T，整数序列的结果会被截断。
+
+// SmaOf 简单移动平均，前 period 个值使用已有数据的平均值
+func SmaOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+	sum := float64(0)
+
+	for i, value := range values {
+		count := i + 1
+		sum += float64(value)
+
+		if i >= period {
+			sum -= float64(values[i-period])
+			count = period
+		}
+
+		val := sum / float64(count)
+		if math.IsNaN(val) || math.IsInf(val, -1) {
+			result[i] = 0
+		} else {
+			result[i] = T(val)
+		}
+	}
+	return result
+}
+
+// EmaOf 指数移动平均，E[i] = (2 * v[i] + (period - 1) * E[i-1]) / (period + 1)
+func EmaOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+	var prev float64
+
+	for i, value := range values {
+		if i > 0 {
+			prev = (2*float64(value) + float64(period-1)*prev) / float64(period+1)
+		} else {
+			prev = float64(value)
+		}
+		result[i] = T(prev)
+	}
+	return result
+}
+
+// DemaOf 双指数移动平均，2 * Ema - Ema(Ema)
+func DemaOf[T commonutils.Number](period int, values []T) []T {
+	ema1 := EmaOf(period, values)
+	ema2 := EmaOf(period, ema1)
+
+	result := make([]T, len(values))
+	for i := range result {
+		result[i] = ema1[i]*2 - ema2[i]
+	}
+	return result
+}
+
+// RmaOf 滚动移动平均，前 period 个值为 Sma，之后 R[i] = (R[i-1] * (period - 1) + v[i]) / period
+func RmaOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+	sum := float64(0)
+	var prev float64
+
+	for i, value := range values {
+		if i < 1 {
+			continue
+		}
+		count := i + 1
+		if i < period {
+			sum += float64(value)
+		} else {
+			sum = (prev * float64(period-1)) + float64(value)
+			count = period
+		}
+
+		prev = sum / float64(count)
+		result[i] = T(prev)
+	}
+	return result
+}
+
+// WmaOf 加权移动平均
+func WmaOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+
+	lookbackTotal := period - 1
+	startIdx := lookbackTotal
+
+	if period == 1 {
+		copy(result, values)
+		return result
+	}
+	divider := (period * (period + 1)) >> 1
+	outIdx := period - 1
+	trailingIdx := startIdx - lookbackTotal
+	periodSum, periodSub := 0.0, 0.0
+	inIdx := trailingIdx
+	i := 1
+	for inIdx < startIdx {
+		tempReal := float64(values[inIdx])
+		periodSub += tempReal
+		periodSum += tempReal * float64(i)
+		inIdx++
+		i++
+	}
+	trailingValue := 0.0
+	for inIdx < len(values) {
+		tempReal := float64(values[inIdx])
+		periodSub += tempReal
+		periodSub -= trailingValue
+		periodSum += tempReal * float64(period)
+		trailingValue = float64(values[trailingIdx])
+		result[outIdx] = T(periodSum / float64(divider))
+		periodSum -= periodSub
+		inIdx++
+		trailingIdx++
+		outIdx++
+	}
+	return result
+}
+
+// SumOf 遍历数组 values，从左向右在 period 周期内总和
+func SumOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+	var sum T
+
+	for i := 0; i < len(values); i++ {
+		sum += values[i]
+
+		if i >= period {
+			sum -= values[i-period]
+		}
+
+		result[i] = sum
+	}
+	return result
+}
+
+// CumOf 返回 values 所有元素的总和
+func CumOf[T commonutils.Number](values []T) T {
+	var val T
+	for _, v := range values {
+		val += v
+	}
+	return val
+}
+
+// MeanOf 返回 values 的平均值
+func MeanOf[T commonutils.Number](values []T) float64 {
+	var total float64
+	for _, v := range values {
+		total += float64(v)
+	}
+	return total / float64(len(values))
+}
+
+// MaxOf 遍历数组 values，在 period 周期内用最大值替换索引位置的值
+func MaxOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+
+	buffer := make([]float64, period)
+	tree := bst.New()
+
+	for i := 0; i < len(values); i++ {
+		var value = float64(values[i])
+		tree.Insert(value)
+
+		if i >= period {
+			tree.Remove(buffer[i%period])
+		}
+
+		buffer[i%period] = value
+		result[i] = T(tree.Max().(float64))
+	}
+	return result
+}
+
+// MinOf 遍历数组 values，在 period 周期内用最小值替换索引位置的值
+func MinOf[T commonutils.Number](period int, values []T) []T {
+	result := make([]T, len(values))
+
+	buffer := make([]float64, period)
+	tree := bst.New()
+
+	for i := 0; i < len(values); i++ {
+		var value = float64(values[i])
+		tree.Insert(value)
+
+		if i >= period {
+			tree.Remove(buffer[i%period])
+		}
+
+		buffer[i%period] = value
+		result[i] = T(tree.Min().(float64))
+	}
+	return result
+}
+
+// HighestOf 返回最后 length 个值中的最高值
+func HighestOf[T commonutils.Number](values []T, length int) T {
+	var result = values[len(values)-1]
+	for i := len(values) - 1; i >= len(values)-length && i >= 0; i-- {
+		if values[i] > result {
+			result = values[i]
+		}
+	}
+	return result
+}
+
+// LowestOf 返回最后 length 个值中的最低值
+func LowestOf[T commonutils.Number](values []T, length int) T {
+	var result = values[len(values)-1]
+	for i := len(values) - 1; i >= len(values)-length && i >= 0; i-- {
+		if values[i] < result {
+			result = values[i]
+		}
+	}
+	return result
+}
+
+// AbsOf 将 values 数组的所有值转换为绝对值
+func AbsOf[T commonutils.Number](values []T) []T {
+	result := make([]T, len(values))
+	for i, v := range values {
+		if v < 0 {
+			v = -v
+		}
+		result[i] = v
+	}
+	return result
+}
+
+// AddOf 将 values1 与 values2 数组元素相加
+func AddOf[T commonutils.Number](values1, values2 []T) []T {
+	result := make([]T, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i] + values2[i]
+	}
+	return result
+}
+
+// SubtractOf 将 values1 与 values2 数组元素相减
+func SubtractOf[T commonutils.Number](values1, values2 []T) []T {
+	result := make([]T, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i] - values2[i]
+	}
+	return result
+}
+
+// MultiplyOf 将 values1 与 values2 数组元素相乘
+func MultiplyOf[T commonutils.Number](values1, values2 []T) []T {
+	result := make([]T, len(values1))
+	for i := 0; i < len(result); i++ {
+		result[i] = values1[i] * values2[i]
+	}
+	return result
+}
+
+// MultiplyByOf 对 values 数组元素遍历乘以 multiplier
+func MultiplyByOf[T commonutils.Number](values []T, multiplier float64) []T {
+	result := make([]T, len(values))
+	for i, value := range values {
+		result[i] = T(float64(value) * multiplier)
+	}
+	return result
+}
+
+// DivideOf 将 values1 与 values2 数组元素相除，结果为 NaN 或 Inf 时为 0
+func DivideOf[T commonutils.Number](values1, values2 []T) []T {
+	result := make([]T, len(values1))
+	for i := 0; i < len(result); i++ {
+		val := float64(values1[i]) / float64(values2[i])
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			val = 0
+		}
+		result[i] = T(val)
+	}
+	return result
+}
+
+// ShiftRightAndFillByOf 将 values 数组的值按 period 右移，填充 fill
+func ShiftRightAndFillByOf[T commonutils.Number](period int, fill T, values []T) []T {
+	result := make([]T, len(values))
+	for i := 0; i < len(result); i++ {
+		if i < period {
+			result[i] = fill
+		} else {
+			result[i] = values[i-period]
+		}
+	}
+	return result
+}
+
+// DiffOf 按 before 右移后，再进行减法运算
+func DiffOf[T commonutils.Number](values []T, before int) []T {
+	return SubtractOf(values, ShiftRightAndFillByOf(before, 0, values))
+}
+
+// TrueRangeOf 真实波动幅度 max(high - low, abs(high - close[1]), abs(low - close[1]))，第一个值为 0
+func TrueRangeOf[T commonutils.Number](high, low, closing []T) []T {
+	result := make([]T, len(closing))
+	for i := 1; i < len(closing); i++ {
+		result[i] = T(trueRange(float64(high[i]), float64(low[i]), float64(closing[i-1])))
+	}
+	return result
+}
+
+func trueRange(high, low, prevClosing float64) float64 {
+	greatest := high - low
+	if val := math.Abs(prevClosing - high); val > greatest {
+		greatest = val
+	}
+	if val := math.Abs(prevClosing - low); val > greatest {
+		greatest = val
+	}
+	return greatest
+}
+
+// AtrOf 平均真实波动幅度，真实波动幅度的 Rma
+func AtrOf[T commonutils.Number](high, low, closing []T, period int) []T {
+	result := make([]T, len(closing))
+
+	if period < 1 {
+		return result
+	}
+	if period <= 1 {
+		return TrueRangeOf(high, low, closing)
+	}
+
+	tr := make([]float64, len(closing))
+	for i := 1; i < len(closing); i++ {
+		tr[i] = trueRange(float64(high[i]), float64(low[i]), float64(closing[i-1]))
+	}
+
+	periodF := float64(period)
+	prevATR := RmaOf(period, tr)[period]
+	result[period] = T(prevATR)
+
+	for i := period + 1; i < len(closing); i++ {
+		prevATR *= periodF - 1.0
+		prevATR += tr[i]
+		prevATR /= periodF
+		result[i] = T(prevATR)
+	}
+	return result
+}
+
+// VarianceOf 返回 period 周期内的方差
+func VarianceOf[T commonutils.Number](values []T, period int) []T {
+	result := make([]T, len(values))
+
+	nbInitialElementNeeded := period - 1
+	startIdx := nbInitialElementNeeded
+	periodTotal1 := 0.0
+	periodTotal2 := 0.0
+	trailingIdx := startIdx - nbInitialElementNeeded
+	i := trailingIdx
+	if period > 1 {
+		for i < startIdx {
+			tempReal := float64(values[i])
+			periodTotal1 += tempReal
+			tempReal *= tempReal
+			periodTotal2 += tempReal
+			i++
+		}
+	}
+	outIdx := startIdx
+	for ok := true; ok; {
+		tempReal := float64(values[i])
+		periodTotal1 += tempReal
+		tempReal *= tempReal
+		periodTotal2 += tempReal
+		meanValue1 := periodTotal1 / float64(period)
+		meanValue2 := periodTotal2 / float64(period)
+		tempReal = float64(values[trailingIdx])
+		periodTotal1 -= tempReal
+		tempReal *= tempReal
+		periodTotal2 -= tempReal
+		result[outIdx] = T(meanValue2 - meanValue1*meanValue1)
+		i++
+		trailingIdx++
+		outIdx++
+		ok = i < len(values)
+	}
+	return result
+}
+
+// StdDevOf 返回 period 周期内的标准差乘以 nbDev
+func StdDevOf[T commonutils.Number](values []T, period int, nbDev float64) []T {
+	variance := VarianceOf(values, period)
+
+	result := make([]T, len(values))
+	for i, v := range variance {
+		if tempReal := float64(v); !(tempReal < 0.00000000000001) {
+			result[i] = T(math.Sqrt(tempReal) * nbDev)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package ta_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// go test -v ./utils/ta -run ^TestGeneric$
+func TestGeneric(t *testing.T) {
+	var r = rand.New(rand.NewSource(1))
+	var n = 500
+	var high, low, closing = make([]float64, n), make([]float64, n), make([]float64, n)
+	var high32, low32, closing32 = make([]float32, n), make([]float32, n), make([]float32, n)
+	for i := range closing {
+		closing32[i] = float32(100 + r.Float64()*10)
+		high32[i] = closing32[i] + float32(r.Float64())
+		low32[i] = closing32[i] - float32(r.Float64())
+		high[i], low[i], closing[i] = float64(high32[i]), float64(low32[i]), float64(closing32[i])
+	}
+
+	var tests = []struct {
+		name     string
+		expected []float64
+		received []float32
+	}{
+		{"Sma", ta.Sma(14, closing), ta.SmaOf(14, closing32)},
+		{"Ema", ta.Ema(14, closing), ta.EmaOf(14, closing32)},
+		{"Dema", ta.Dema(14, closing), ta.DemaOf(14, closing32)},
+		{"Rma", ta.Rma(14, closing), ta.RmaOf(14, closing32)},
+		{"Wma", ta.Wma(14, closing), ta.WmaOf(14, closing32)},
+		{"Sum", ta.Sum(14, closing), ta.SumOf(14, closing32)},
+		{"Max", ta.Max(14, high), ta.MaxOf(14, high32)},
+		{"Min", ta.Min(14, low), ta.MinOf(14, low32)},
+		{"Diff", ta.Diff(closing, 1), ta.DiffOf(closing32, 1)},
+		{"Divide", ta.Divide(high, low), ta.DivideOf(high32, low32)},
+		{"Atr", ta.Atr(high, low, closing, 14), ta.AtrOf(high32, low32, closing32, 14)},
+		{"StdDev", ta.StdDev(closing, 14, 2), ta.StdDevOf(closing32, 14, 2)},
+	}
+	for _, test := range tests {
+		for i := range test.expected {
+			// float32 约有 7 位有效数字
+			if math.Abs(float64(test.received[i])-test.expected[i]) > 1e-4*math.Max(1, math.Abs(test.expected[i])) {
+				t.Fatalf("%s[%d] received %f expected %f", test.name, i, test.received[i], test.expected[i])
+			}
+		}
+	}
+
+	// 整数成交量的求和没有误差
+	var volumes = []int64{1 << 53, 1, 1, 1}
+	if sum := ta.SumOf(4, volumes)[3]; sum != 1<<53+3 {
+		t.Fatalf("received %d expected %d", sum, int64(1<<53+3))
+	}
+	if max := ta.MaxOf(2, volumes); max[0] != 1<<53 || max[3] != 1 {
+		t.Fatalf("unexpected max %v", max)
+	}
+	if sma := ta.SmaOf(2, []int64{1, 2, 4, 6}); sma[1] != 1 || sma[3] != 5 {
+		t.Fatalf("unexpected sma %v", sma)
+	}
+}
@@ -0,0 +1,297 @@
+package ta
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/idoall/stockindicator/utils/types"
+)
+
+// MovingAverage 按 maType 计算移动平均线，未知类型按 SMA 计算
+//
+//	MAMA 使用默认的 fastLimit 0.5、slowLimit 0.05，忽略 period
+//	T3 使用默认的 vFactor 0.7
+func MovingAverage(maType types.MATypes, period int, values []float64) []float64 {
+	switch maType {
+	case types.EMA:
+		return Ema(period, values)
+	case types.WMA:
+		return Wma(period, values)
+	case types.DEMA:
+		return Dema(period, values)
+	case types.TEMA:
+		return Tema(period, values)
+	case types.TRIMA:
+		return Trima(period, values)
+	case types.KAMA:
+		return Kama(period, values)
+	case types.MAMA:
+		mama, _ := Mama(values, 0.5, 0.05)
+		return mama
+	case types.T3MA:
+		return T3(period, values, 0.7)
+	default:
+		return Sma(period, values)
+	}
+}
+
+// ErrMAStreaming 均线类型不支持增量计算
+var ErrMAStreaming = errors.New("ta: moving average type does not support streaming")
+
+// CanStreamMA 均线类型是否可以使用 MAState 增量计算，TRIMA 与 MAMA 依赖中间序列的历史，不支持
+func CanStreamMA(maType types.MATypes) bool {
+	return maType != types.TRIMA && maType != types.MAMA
+}
+
+// MAState 移动平均线的增量计算状态，与 MovingAverage 的计算方式一致。
+// MAState 是值类型，复制后即可保存某一根K线之前的状态，用于重新计算最后一根K线
+type MAState struct {
+	count int
+	sum   float64
+	ema   [6]float64
+	kama  float64
+}
+
+// Next 追加一个值，返回新的状态与均线的值，每次更新的耗时与数据数量无关。
+// window 为截止到当前的最近数据，最后一个为当前值，SMA、WMA 与 KAMA 需要最近 period+1 个值，
+// 其他类型只使用当前值。maType 不支持增量计算时 panic，见 CanStreamMA
+func (s MAState) Next(maType types.MATypes, period int, window []float64) (MAState, float64) {
+	if !CanStreamMA(maType) {
+		panic(fmt.Errorf("%w: %s", ErrMAStreaming, maType))
+	}
+	var value = window[len(window)-1]
+	var index = s.count
+	s.count++
+
+	switch maType {
+	case types.EMA, types.DEMA, types.TEMA, types.T3MA:
+		// 与 Ema 一致，每一层 EMA 的输入为上一层的输出
+		var input = value
+		for k := range s.ema {
+			if index > 0 {
+				s.ema[k] = (2*input + float64(period-1)*s.ema[k]) / float64(period+1)
+			} else {
+				s.ema[k] = input
+			}
+			input = s.ema[k]
+		}
+		switch maType {
+		case types.DEMA:
+			return s, s.ema[0]*2 - s.ema[1]
+		case types.TEMA:
+			return s, 3*s.ema[0] - 3*s.ema[1] + s.ema[2]
+		case types.T3MA:
+			a := 0.7
+			c1 := -a * a * a
+			c2 := 3*a*a + 3*a*a*a
+			c3 := -6*a*a - 3*a - 3*a*a*a
+			c4 := 1 + 3*a + a*a*a + 3*a*a
+			return s, c1*s.ema[5] + c2*s.ema[4] + c3*s.ema[3] + c4*s.ema[2]
+		}
+		return s, s.ema[0]
+	case types.WMA:
+		if period <= 1 {
+			return s, value
+		}
+		if index < period-1 {
+			return s, 0
+		}
+		var sum float64
+		for k, v := range window[len(window)-period:] {
+			sum += v * float64(k+1)
+		}
+		return s, sum / float64((period*(period+1))>>1)
+	case types.KAMA:
+		if period < 1 || index < period {
+			s.kama = value
+			return s, s.kama
+		}
+		const fast = 2.0 / (2 + 1)
+		const slow = 2.0 / (30 + 1)
+		var last = len(window) - 1
+		change := math.Abs(value - window[last-period])
+		volatility := 0.0
+		for j := last - period + 1; j <= last; j++ {
+			volatility += math.Abs(window[j] - window[j-1])
+		}
+		er := 0.0
+		if volatility != 0 {
+			er = change / volatility
+		}
+		sc := math.Pow(er*(fast-slow)+slow, 2)
+		s.kama = s.kama + sc*(value-s.kama)
+		return s, s.kama
+	default:
+		// 与 Sma 一致
+		var count = index + 1
+		s.sum += value
+		if index >= period {
+			s.sum -= window[len(window)-1-period]
+			count = period
+		}
+		var result = s.sum / float64(count)
+		if math.IsNaN(result) || math.IsInf(result, -1) {
+			result = 0
+		}
+		return s, result
+	}
+}
+
+// Tema - Triple Exponential Moving Average 三重指数移动平均线
+//
+//	TEMA = 3*EMA1 - 3*EMA2 + EMA3
+func Tema(period int, values []float64) []float64 {
+	ema1 := Ema(period, values)
+	ema2 := Ema(period, ema1)
+	ema3 := Ema(period, ema2)
+
+	result := make([]float64, len(values))
+	for i := range result {
+		result[i] = 3*ema1[i] - 3*ema2[i] + ema3[i]
+	}
+	return result
+}
+
+// Trima - Triangular Moving Average 三角移动平均线，对 SMA 再做一次 SMA
+//
+//	周期为奇数时两次都使用 (period+1)/2，偶数时使用 period/2 与 period/2+1
+func Trima(period int, values []float64) []float64 {
+	if period%2 == 1 {
+		n := (period + 1) / 2
+		return Sma(n, Sma(n, values))
+	}
+	n := period / 2
+	return Sma(n+1, Sma(n, values))
+}
+
+// Kama - Kaufman Adaptive Moving Average 考夫曼自适应移动平均线
+//
+//	ER = |Close - Close[period]| / Sum(|Close - Close[1]|, period)
+//	SC = (ER * (2/(2+1) - 2/(30+1)) + 2/(30+1))²
+//	KAMA = KAMA[1] + SC * (Close - KAMA[1])
+//
+// 前 period 个值等于原值
+func Kama(period int, values []float64) []float64 {
+	result := make([]float64, len(values))
+	copy(result, values)
+
+	if period < 1 || len(values) <= period {
+		return result
+	}
+
+	const fast = 2.0 / (2 + 1)
+	const slow = 2.0 / (30 + 1)
+
+	for i := period; i < len(values); i++ {
+		change := math.Abs(values[i] - values[i-period])
+		volatility := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			volatility += math.Abs(values[j] - values[j-1])
+		}
+
+		er := 0.0
+		if volatility != 0 {
+			er = change / volatility
+		}
+		sc := math.Pow(er*(fast-slow)+slow, 2)
+		result[i] = result[i-1] + sc*(values[i]-result[i-1])
+	}
+	return result
+}
+
+// Mama - MESA Adaptive Moving Average 自适应移动平均线，由 John Ehlers 提出，返回 mama 与 fama
+//
+//	fastLimit 默认为 0.5
+//	slowLimit 默认为 0.05
+func Mama(values []float64, fastLimit, slowLimit float64) (mama, fama []float64) {
+	n := len(values)
+	mama = make([]float64, n)
+	fama = make([]float64, n)
+
+	smooth := make([]float64, n)
+	detrender := make([]float64, n)
+	q1 := make([]float64, n)
+	i1 := make([]float64, n)
+	i2 := make([]float64, n)
+	q2 := make([]float64, n)
+	re := make([]float64, n)
+	im := make([]float64, n)
+	period := make([]float64, n)
+	phase := make([]float64, n)
+
+	// hilbert Hilbert 变换
+	hilbert := func(s []float64, i int, p float64) float64 {
+		return (0.0962*s[i] + 0.5769*s[i-2] - 0.5769*s[i-4] - 0.0962*s[i-6]) * (0.075*p + 0.54)
+	}
+
+	for i := 0; i < n; i++ {
+		if i < 6 {
+			mama[i] = values[i]
+			fama[i] = values[i]
+			continue
+		}
+
+		smooth[i] = (4*values[i] + 3*values[i-1] + 2*values[i-2] + values[i-3]) / 10
+		detrender[i] = hilbert(smooth, i, period[i-1])
+
+		q1[i] = hilbert(detrender, i, period[i-1])
+		i1[i] = detrender[i-3]
+
+		jI := hilbert(i1, i, period[i-1])
+		jQ := hilbert(q1, i, period[i-1])
+
+		i2[i] = 0.2*(i1[i]-jQ) + 0.8*i2[i-1]
+		q2[i] = 0.2*(q1[i]+jI) + 0.8*q2[i-1]
+
+		re[i] = 0.2*(i2[i]*i2[i-1]+q2[i]*q2[i-1]) + 0.8*re[i-1]
+		im[i] = 0.2*(i2[i]*q2[i-1]-q2[i]*i2[i-1]) + 0.8*im[i-1]
+
+		p := period[i-1]
+		if im[i] != 0 && re[i] != 0 {
+			p = 360 / (math.Atan(im[i]/re[i]) * 180 / math.Pi)
+		}
+		if p > 1.5*period[i-1] {
+			p = 1.5 * period[i-1]
+		}
+		if p < 0.67*period[i-1] {
+			p = 0.67 * period[i-1]
+		}
+		p = math.Min(math.Max(p, 6), 50)
+		period[i] = 0.2*p + 0.8*period[i-1]
+
+		if i1[i] != 0 {
+			phase[i] = math.Atan(q1[i]/i1[i]) * 180 / math.Pi
+		}
+		deltaPhase := math.Max(phase[i-1]-phase[i], 1)
+
+		alpha := math.Max(fastLimit/deltaPhase, slowLimit)
+		mama[i] = alpha*values[i] + (1-alpha)*mama[i-1]
+		fama[i] = 0.5*alpha*mama[i] + (1-0.5*alpha)*fama[i-1]
+	}
+	return mama, fama
+}
+
+// T3 - Tillson T3 Moving Average，六次 EMA 的加权组合
+//
+//	vFactor 默认为 0.7
+func T3(period int, values []float64, vFactor float64) []float64 {
+	e1 := Ema(period, values)
+	e2 := Ema(period, e1)
+	e3 := Ema(period, e2)
+	e4 := Ema(period, e3)
+	e5 := Ema(period, e4)
+	e6 := Ema(period, e5)
+
+	a := vFactor
+	c1 := -a * a * a
+	c2 := 3*a*a + 3*a*a*a
+	c3 := -6*a*a - 3*a - 3*a*a*a
+	c4 := 1 + 3*a + a*a*a + 3*a*a
+
+	result := make([]float64, len(values))
+	for i := range result {
+		result[i] = c1*e6[i] + c2*e5[i] + c3*e4[i] + c4*e3[i]
+	}
+	return result
+}
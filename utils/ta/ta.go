@@ -162,31 +162,7 @@ func LinearRegSlope(inReal []float64, inTimePeriod int) []float64 {
 
 // TRange - True Range
 func TRange(inHigh []float64, inLow []float64, inClose []float64) []float64 {
-
-	outReal := make([]float64, len(inClose))
-
-	startIdx := 1
-	outIdx := startIdx
-	today := startIdx
-	for today < len(inClose) {
-		tempLT := inLow[today]
-		tempHT := inHigh[today]
-		tempCY := inClose[today-1]
-		greatest := tempHT - tempLT
-		val2 := math.Abs(tempCY - tempHT)
-		if val2 > greatest {
-			greatest = val2
-		}
-		val3 := math.Abs(tempCY - tempLT)
-		if val3 > greatest {
-			greatest = val3
-		}
-		outReal[outIdx] = greatest
-		outIdx++
-		today++
-	}
-
-	return outReal
+	return TrueRangeOf(inHigh, inLow, inClose)
 }
 
 func Stochastic(closing, highs, lows []float64, period int) (k, d []float64) {
@@ -242,28 +218,7 @@ func MA(source []float64, length int) []float64 {
 
 // 简单移动均线简写为SMA，有时候也直接记为MA。 移动平均线，SMA(N)它将指定周期内的收盘价格之和除以周期N得到的一个指标
 func Sma(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-	sum := float64(0)
-
-	for i, value := range values {
-		count := i + 1
-		sum += value
-
-		if i >= period {
-			sum -= values[i-period]
-			count = period
-		}
-
-		val := sum / float64(count)
-		if math.IsNaN(val) || math.IsInf(val, -1) {
-			result[i] = 0
-		} else {
-			result[i] = val
-		}
-	}
-
-	values = nil
-	return result
+	return SmaOf(period, values)
 }
 
 func SmaT[T commonutils.Number](period int, c <-chan T) <-chan T {
@@ -289,94 +244,20 @@ func SmaT[T commonutils.Number](period int, c <-chan T) <-chan T {
 //
 // Returns r.
 func Rma(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-	sum := float64(0)
-
-	for i, value := range values {
-		if i < 1 {
-			continue
-		}
-		count := i + 1
-		if i < period {
-			sum += value
-		} else {
-			sum = (result[i-1] * float64(period-1)) + value
-			count = period
-		}
-
-		result[i] = sum / float64(count)
-	}
-
-	return result
+	return RmaOf(period, values)
 }
 
 func Dema(period int, values []float64) []float64 {
-
-	var close = values
-	var ema1 = Ema(period, close)
-	var ema2 = Ema(period, ema1)
-
-	demas := Subtract(MultiplyBy(ema1, 2), ema2)
-	return demas
+	return DemaOf(period, values)
 }
 
 func Ema(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-
-	// k := float64(2) / float64(1+period)
-
-	for i, value := range values {
-		if i > 0 {
-			// result[i] = (value * k) + (result[i-1] * float64(1-k))
-			// result[i] = k*(values[i]-result[i-1]) + result[i-1]
-			result[i] = (2*value + float64(period-1)*(result[i-1])) / float64(period+1)
-		} else {
-			result[i] = value
-		}
-	}
-	values = nil
-	return result
+	return EmaOf(period, values)
 }
 
 // Wma - Weighted Moving Average
 func Wma(period int, values []float64) []float64 {
-
-	result := make([]float64, len(values))
-
-	lookbackTotal := period - 1
-	startIdx := lookbackTotal
-
-	if period == 1 {
-		copy(result, values)
-		return result
-	}
-	divider := (period * (period + 1)) >> 1
-	outIdx := period - 1
-	trailingIdx := startIdx - lookbackTotal
-	periodSum, periodSub := 0.0, 0.0
-	inIdx := trailingIdx
-	i := 1
-	for inIdx < startIdx {
-		tempReal := values[inIdx]
-		periodSub += tempReal
-		periodSum += tempReal * float64(i)
-		inIdx++
-		i++
-	}
-	trailingValue := 0.0
-	for inIdx < len(values) {
-		tempReal := values[inIdx]
-		periodSub += tempReal
-		periodSub -= trailingValue
-		periodSum += tempReal * float64(period)
-		trailingValue = values[trailingIdx]
-		result[outIdx] = periodSum / float64(divider)
-		periodSum -= periodSub
-		inIdx++
-		trailingIdx++
-		outIdx++
-	}
-	return result
+	return WmaOf(period, values)
 }
 
 // 计算平均偏差
@@ -438,36 +319,7 @@ func AiCoinCCI(close []float64, period int, factor float64) []float64 {
 //
 //	真实波动幅度是max(high - low, abs(high - close[1]), abs(low - close[1]))。
 func Atr(inHigh []float64, inLow []float64, inClose []float64, inTimePeriod int) []float64 {
-
-	outReal := make([]float64, len(inClose))
-
-	inTimePeriodF := float64(inTimePeriod)
-
-	if inTimePeriod < 1 {
-		return outReal
-	}
-
-	if inTimePeriod <= 1 {
-		return TRange(inHigh, inLow, inClose)
-	}
-
-	outIdx := inTimePeriod
-	today := inTimePeriod + 1
-
-	tr := TRange(inHigh, inLow, inClose)
-	prevATRTemp := Rma(inTimePeriod, tr)
-	prevATR := prevATRTemp[inTimePeriod]
-	outReal[inTimePeriod] = prevATR
-
-	for outIdx = inTimePeriod + 1; outIdx < len(inClose); outIdx++ {
-		prevATR *= inTimePeriodF - 1.0
-		prevATR += tr[today]
-		prevATR /= inTimePeriodF
-		outReal[outIdx] = prevATR
-		today++
-	}
-
-	return outReal
+	return AtrOf(inHigh, inLow, inClose, inTimePeriod)
 }
 
 // Natr - Normalized Average True Range
@@ -516,34 +368,17 @@ func Natr(inHigh []float64, inLow []float64, inClose []float64, inTimePeriod int
 
 // Cum 计算souce的累计总和,返回`source`所有元素的总和
 func Cum(source []float64) float64 {
-	var val float64
-	for _, v := range source {
-		val += v
-	}
-	return val
+	return CumOf(source)
 }
 
 // Multiply 对 values 数组元素遍历乘以 multiplier 进行乘法运算.
 func MultiplyBy(values []float64, multiplier float64) []float64 {
-	result := make([]float64, len(values))
-
-	for i, value := range values {
-		result[i] = value * multiplier
-	}
-
-	return result
+	return MultiplyByOf(values, multiplier)
 }
 
 // Multiply 将 values1 与 values2 数组元素进行乘法运算.
 func Multiply(values1, values2 []float64) []float64 {
-
-	result := make([]float64, len(values1))
-
-	for i := 0; i < len(result); i++ {
-		result[i] = values1[i] * values2[i]
-	}
-
-	return result
+	return MultiplyOf(values1, values2)
 }
 
 // Divide 对 values 数组元素遍历进行 per 百分比运算，并返回结果.
@@ -553,29 +388,12 @@ func DivideBy(values []float64, per float64) []float64 {
 
 // Divide 将 values1 数组与 values2 数组，遍历每一对进行除法运算.
 func Divide(values1, values2 []float64) []float64 {
-
-	result := make([]float64, len(values1))
-
-	for i := 0; i < len(result); i++ {
-		val := values1[i] / values2[i]
-		if math.IsNaN(val) || math.IsInf(val, 0) {
-			val = 0
-		}
-		result[i] = val
-	}
-
-	return result
+	return DivideOf(values1, values2)
 }
 
 // Add 将 values1 与遍历 values2 数组元素相加.
 func Add(values1, values2 []float64) []float64 {
-
-	result := make([]float64, len(values1))
-	for i := 0; i < len(result); i++ {
-		result[i] = values1[i] + values2[i]
-	}
-
-	return result
+	return AddOf(values1, values2)
 }
 
 // AddBy 将 values 数组遍历加上 addition.
@@ -591,15 +409,12 @@ func AddBy(values []float64, addition float64) []float64 {
 
 // Subtract 遍历 values1 数组与 value2 数组进行减法运算.
 func Subtract(values1, values2 []float64) []float64 {
-	// 将 value2 进行负值运算
-	subtract := MultiplyBy(values2, float64(-1))
-	return Add(values1, subtract)
+	return SubtractOf(values1, values2)
 }
 
 // Diff 按 before 右移后，再进行减法运算.
 func Diff(values []float64, before int) []float64 {
-
-	return Subtract(values, ShiftRight(before, values))
+	return DiffOf(values, before)
 }
 
 // PercentDiff 将 values 数组 从 before 开始，计算当前值-上一项值/上一项值的百分比
@@ -615,17 +430,7 @@ func PercentDiff(values []float64, before int) []float64 {
 
 // ShiftRightAndFillBy 将 values 数组的值 按 period 右移，填充 fill
 func ShiftRightAndFillBy(period int, fill float64, values []float64) []float64 {
-	result := make([]float64, len(values))
-
-	for i := 0; i < len(result); i++ {
-		if i < period {
-			result[i] = fill
-		} else {
-			result[i] = values[i-period]
-		}
-	}
-
-	return result
+	return ShiftRightAndFillByOf(period, fill, values)
 }
 
 // ShiftRight 将 values 数组的值按 period 进行右移.
@@ -725,44 +530,12 @@ func KeepNegatives(values []float64) []float64 {
 
 // Max 遍历数组 values，从右向左在 period 周期内，用最大值替换索引位置的值.
 func Max(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-
-	buffer := make([]float64, period)
-	bst := bst.New()
-
-	for i := 0; i < len(values); i++ {
-		bst.Insert(values[i])
-
-		if i >= period {
-			bst.Remove(buffer[i%period])
-		}
-
-		buffer[i%period] = values[i]
-		result[i] = bst.Max().(float64)
-	}
-
-	return result
+	return MaxOf(period, values)
 }
 
 // Min 遍历数组 values，从右向左在 period 周期内，用最小值替换索引位置的值.
 func Min(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-
-	buffer := make([]float64, period)
-	bst := bst.New()
-
-	for i := 0; i < len(values); i++ {
-		bst.Insert(values[i])
-
-		if i >= period {
-			bst.Remove(buffer[i%period])
-		}
-
-		buffer[i%period] = values[i]
-		result[i] = bst.Min().(float64)
-	}
-
-	return result
+	return MinOf(period, values)
 }
 
 // Highest 返回给定数目的最高值。
@@ -771,17 +544,7 @@ func Min(period int, values []float64) []float64 {
 //		values 要计算的数组
 //		lenght 计算的长度
 func Highest(values []float64, lenght int) float64 {
-	var result = values[len(values)-1]
-	for i := len(values) - 1; i >= len(values)-lenght; i-- {
-		if i < 0 {
-			break
-		}
-		if values[i] > result {
-			result = values[i]
-		}
-	}
-
-	return result
+	return HighestOf(values, lenght)
 }
 
 // Lowest 返回给定数目的最低值。
@@ -790,16 +553,7 @@ func Highest(values []float64, lenght int) float64 {
 //		values 要计算的数组
 //		lenght 计算的长度
 func Lowest(values []float64, lenght int) float64 {
-	var result = values[len(values)-1]
-	for i := len(values) - 1; i >= len(values)-lenght; i-- {
-		if i < 0 {
-			break
-		}
-		if values[i] < result {
-			result = values[i]
-		}
-	}
-	return result
+	return LowestOf(values, lenght)
 }
 
 // PivotHigh 此函数返回枢轴高点的价格。 如果没有枢轴高点，则返回“0”。
@@ -922,20 +676,7 @@ func PivotMin(values []float64, index, left, right int) (val float64, position i
 
 // Sum 遍历数组 values，从左向右在 period 周期内总和，替换索引位置的值.
 func Sum(period int, values []float64) []float64 {
-	result := make([]float64, len(values))
-	sum := 0.0
-
-	for i := 0; i < len(values); i++ {
-		sum += values[i]
-
-		if i >= period {
-			sum -= values[i-period]
-		}
-
-		result[i] = sum
-	}
-
-	return result
+	return SumOf(period, values)
 }
 
 // Sqrt 依次计算 values 数组的平方根.
@@ -951,90 +692,22 @@ func Sqrt(values []float64) []float64 {
 
 // Abs 将 values 数组的所有值转换为绝对值.
 func Abs(values []float64) []float64 {
-	result := make([]float64, len(values))
-
-	for i := 0; i < len(values); i++ {
-		result[i] = math.Abs(values[i])
-	}
-
-	return result
+	return AbsOf(values)
 }
 
 // Mean 返回float64值数组的平均值
 func Mean(values []float64) float64 {
-	var total float64 = 0
-	for x := range values {
-		total += values[x]
-	}
-	return total / float64(len(values))
+	return MeanOf(values)
 }
 
 // trueRange 返回高低闭合的真实范围
 func TrueRange(inHigh, inLow, inClose []float64) []float64 {
-	outReal := make([]float64, len(inClose))
-
-	startIdx := 1
-	outIdx := startIdx
-	today := startIdx
-	for today < len(inClose) {
-		tempLT := inLow[today]
-		tempHT := inHigh[today]
-		tempCY := inClose[today-1]
-		greatest := tempHT - tempLT
-		val2 := math.Abs(tempCY - tempHT)
-		if val2 > greatest {
-			greatest = val2
-		}
-		val3 := math.Abs(tempCY - tempLT)
-		if val3 > greatest {
-			greatest = val3
-		}
-		outReal[outIdx] = greatest
-		outIdx++
-		today++
-	}
-
-	return outReal
+	return TrueRangeOf(inHigh, inLow, inClose)
 }
 
 // Variance 返回给定时间段的方差
 func Variance(inReal []float64, inTimePeriod int) []float64 {
-	outReal := make([]float64, len(inReal))
-
-	nbInitialElementNeeded := inTimePeriod - 1
-	startIdx := nbInitialElementNeeded
-	periodTotal1 := 0.0
-	periodTotal2 := 0.0
-	trailingIdx := startIdx - nbInitialElementNeeded
-	i := trailingIdx
-	if inTimePeriod > 1 {
-		for i < startIdx {
-			tempReal := inReal[i]
-			periodTotal1 += tempReal
-			tempReal *= tempReal
-			periodTotal2 += tempReal
-			i++
-		}
-	}
-	outIdx := startIdx
-	for ok := true; ok; {
-		tempReal := inReal[i]
-		periodTotal1 += tempReal
-		tempReal *= tempReal
-		periodTotal2 += tempReal
-		meanValue1 := periodTotal1 / float64(inTimePeriod)
-		meanValue2 := periodTotal2 / float64(inTimePeriod)
-		tempReal = inReal[trailingIdx]
-		periodTotal1 -= tempReal
-		tempReal *= tempReal
-		periodTotal2 -= tempReal
-		outReal[outIdx] = meanValue2 - meanValue1*meanValue1
-		i++
-		trailingIdx++
-		outIdx++
-		ok = i < len(inReal)
-	}
-	return outReal
+	return VarianceOf(inReal, inTimePeriod)
 }
 
 // Dev 计算平均偏差序列（Mean Deviation）
@@ -1068,28 +741,7 @@ func Dev(source []float64, length int) []float64 {
 
 // stdDev - 标准差
 func StdDev(inReal []float64, inTimePeriod int, inNbDev float64) []float64 {
-	outReal := Variance(inReal, inTimePeriod)
-
-	if inNbDev != 1.0 {
-		for i := 0; i < len(inReal); i++ {
-			tempReal := outReal[i]
-			if !(tempReal < 0.00000000000001) {
-				outReal[i] = math.Sqrt(tempReal) * inNbDev
-			} else {
-				outReal[i] = 0.0
-			}
-		}
-	} else {
-		for i := 0; i < len(inReal); i++ {
-			tempReal := outReal[i]
-			if !(tempReal < 0.00000000000001) {
-				outReal[i] = math.Sqrt(tempReal)
-			} else {
-				outReal[i] = 0.0
-			}
-		}
-	}
-	return outReal
+	return StdDevOf(inReal, inTimePeriod, inNbDev)
 }
 
 // Nzs 以系列中的指定数替换NaN值。
@@ -7,6 +7,7 @@ import (
 
 	"github.com/idoall/stockindicator/helpertools"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/idoall/stockindicator/utils/types"
 )
 
 // go test -v ./utils/ta -run ^TestSmaT$
@@ -89,3 +90,74 @@ func almostEqual(a, b float64) bool {
 	const epsilon = 1e-9
 	return math.Abs(a-b) < epsilon
 }
+
+// go test -v ./utils/ta -run ^TestMovingAverage$
+func TestMovingAverage(t *testing.T) {
+	constant := make([]float64, 60)
+	for i := range constant {
+		constant[i] = 10
+	}
+
+	// 常数序列的任意均线都应该等于该常数
+	for _, maType := range []types.MATypes{
+		types.SMA, types.EMA, types.WMA, types.DEMA, types.TEMA,
+		types.TRIMA, types.KAMA, types.MAMA, types.T3MA,
+	} {
+		result := ta.MovingAverage(maType, 5, constant)
+		for i := 10; i < len(result); i++ {
+			if !almostEqual(result[i], 10) {
+				t.Fatalf("%s [%d] expected 10 got %f", maType, i, result[i])
+			}
+		}
+	}
+
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if result, expected := ta.MovingAverage(types.TRIMA, 5, values), ta.Sma(3, ta.Sma(3, values)); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("result %v expected %v", result, expected)
+	}
+	if result, expected := ta.MovingAverage(types.TRIMA, 4, values), ta.Sma(3, ta.Sma(2, values)); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("result %v expected %v", result, expected)
+	}
+
+	// 线性序列中 TEMA 的滞后小于 EMA
+	ema := ta.Ema(5, values)
+	tema := ta.Tema(5, values)
+	if last := len(values) - 1; math.Abs(tema[last]-values[last]) >= math.Abs(ema[last]-values[last]) {
+		t.Fatalf("expected tema %f closer to %f than ema %f", tema[last], values[last], ema[last])
+	}
+
+	// 效率比为 1 时 KAMA 使用最快的平滑系数
+	kama := ta.Kama(3, values)
+	if expected := 3 + math.Pow(2.0/3, 2)*(4-3); !almostEqual(kama[3], expected) {
+		t.Fatalf("expected kama %f got %f", expected, kama[3])
+	}
+}
+
+// go test -v ./utils/ta -run ^TestMAState$
+func TestMAState(t *testing.T) {
+	values := make([]float64, 80)
+	for i := range values {
+		values[i] = 10 + math.Sin(float64(i)/3)*5 + float64(i%7)
+	}
+
+	for _, maType := range []types.MATypes{
+		types.SMA, types.EMA, types.WMA, types.DEMA, types.TEMA, types.KAMA, types.T3MA,
+	} {
+		const period = 6
+		expected := ta.MovingAverage(maType, period, values)
+		var state ta.MAState
+		for i := range values {
+			var value float64
+			state, value = state.Next(maType, period, values[max(i-period, 0):i+1])
+			if !almostEqual(value, expected[i]) {
+				t.Fatalf("%s [%d] expected %f got %f", maType, i, expected[i], value)
+			}
+		}
+	}
+
+	for _, maType := range []types.MATypes{types.TRIMA, types.MAMA} {
+		if ta.CanStreamMA(maType) {
+			t.Fatalf("%s should not support streaming", maType)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 type MATypes uint32
 
 const (
@@ -14,3 +19,55 @@ const (
 	MAMA
 	T3MA
 )
+
+// String implements the stringer interface
+func (e MATypes) String() string {
+	switch e {
+	case SMA:
+		return "SMA"
+	case EMA:
+		return "EMA"
+	case WMA:
+		return "WMA"
+	case DEMA:
+		return "DEMA"
+	case TEMA:
+		return "TEMA"
+	case TRIMA:
+		return "TRIMA"
+	case KAMA:
+		return "KAMA"
+	case MAMA:
+		return "MAMA"
+	case T3MA:
+		return "T3"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseMATypes 将字符串转换为 MATypes，不区分大小写，"T3" 与 "T3MA" 均可
+func ParseMATypes(s string) (MATypes, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "SMA", "MA":
+		return SMA, nil
+	case "EMA":
+		return EMA, nil
+	case "WMA":
+		return WMA, nil
+	case "DEMA":
+		return DEMA, nil
+	case "TEMA":
+		return TEMA, nil
+	case "TRIMA":
+		return TRIMA, nil
+	case "KAMA":
+		return KAMA, nil
+	case "MAMA":
+		return MAMA, nil
+	case "T3", "T3MA":
+		return T3MA, nil
+	default:
+		return UnknownMATypes, fmt.Errorf("unknown moving average type %q", s)
+	}
+}
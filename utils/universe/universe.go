@@ -0,0 +1,263 @@
+package universe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+var (
+	// ErrNoSymbols 没有品种
+	ErrNoSymbols = errors.New("universe: no symbols")
+	// ErrNoStrategies 没有策略
+	ErrNoStrategies = errors.New("universe: no strategies")
+	// ErrNoCandles 品种没有K线数据
+	ErrNoCandles = errors.New("universe: no candle data")
+	// ErrPanic 策略计算时发生 panic，例如K线数量少于指标周期
+	ErrPanic = errors.New("universe: strategy panicked")
+)
+
+// Factory 为一个品种创建策略，多个 goroutine 会同时调用，不能修改 item
+type Factory func(item *klines.Item) utils.IStrategy
+
+// Progress 每完成一个品种报告一次进度
+type Progress struct {
+	Symbol string
+	// 已完成与全部品种数量
+	Done  int
+	Total int
+	// 该品种是否有策略失败
+	Failed bool
+	// 从开始运行到现在的时间
+	Elapsed time.Duration
+}
+
+// Config 运行配置
+type Config struct {
+	// 同时运行的 goroutine 数量，为 0 时使用 CPU 数量
+	Workers int
+	// 进度回调，在调用 Run 的 goroutine 中按完成顺序调用，不需要加锁
+	Progress func(progress Progress)
+}
+
+// DefaultConfig 默认配置：使用 CPU 数量的 goroutine，不报告进度
+func DefaultConfig() Config {
+	return Config{}
+}
+
+// Result 一个品种的运行结果
+type Result struct {
+	Symbol string
+	// 与策略列表一一对应，失败的策略为空
+	Sides []utils.SideData
+	// 失败的策略的错误，全部成功时为 nil
+	Err error
+	// 运行该品种全部策略的时间
+	Duration time.Duration
+}
+
+// Last 第 strategy 个策略最后一根K线的信号，没有信号时为 Hold
+func (r Result) Last(strategy int) utils.Side {
+	if strategy < 0 || strategy >= len(r.Sides) || len(r.Sides[strategy].Data) == 0 {
+		return utils.Hold
+	}
+	var data = r.Sides[strategy].Data
+	return data[len(data)-1]
+}
+
+// Report 全部品种的运行结果，按品种名称排序，取消时只包含已完成的品种
+type Report struct {
+	Results []Result
+	// 有策略失败的品种数量
+	Failed  int
+	Elapsed time.Duration
+}
+
+// Result 返回一个品种的结果
+func (r *Report) Result(symbol string) (Result, bool) {
+	var i = sort.Search(len(r.Results), func(i int) bool { return r.Results[i].Symbol >= symbol })
+	if i < len(r.Results) && r.Results[i].Symbol == symbol {
+		return r.Results[i], true
+	}
+	return Result{}, false
+}
+
+// Errors 返回有策略失败的品种及其错误
+func (r *Report) Errors() map[string]error {
+	var errs = make(map[string]error)
+	for _, v := range r.Results {
+		if v.Err != nil {
+			errs[v.Symbol] = v.Err
+		}
+	}
+	return errs
+}
+
+// Latest 返回第 strategy 个策略最后一根K线的信号为 side 的品种，按名称排序
+func (r *Report) Latest(strategy int, side utils.Side) []string {
+	var symbols []string
+	for _, v := range r.Results {
+		if v.Err == nil && v.Last(strategy) == side {
+			symbols = append(symbols, v.Symbol)
+		}
+	}
+	return symbols
+}
+
+// Runner 在多个品种上同时运行同一组策略
+type Runner struct {
+	Factories []Factory
+	Config    Config
+	items     map[string]*klines.Item
+}
+
+// NewRunner new Func
+//
+//	var runner = universe.NewRunner(items, []universe.Factory{
+//		func(item *klines.Item) utils.IStrategy { return trend.NewDefaultMacd(item) },
+//		func(item *klines.Item) utils.IStrategy { return trend.NewDefaultRsi(item) },
+//	}, universe.DefaultConfig())
+//	report, err := runner.Run(ctx)
+func NewRunner(items map[string]*klines.Item, factories []Factory, config Config) *Runner {
+	return &Runner{
+		Factories: factories,
+		Config:    config,
+		items:     items,
+	}
+}
+
+// NewDefaultRunner new Func
+func NewDefaultRunner(items map[string]*klines.Item, factories ...Factory) *Runner {
+	return NewRunner(items, factories, DefaultConfig())
+}
+
+// Run 使用固定数量的 goroutine 运行全部品种。
+// ctx 取消后不再开始新的品种，正在运行的策略完成后返回已完成品种的结果与 ctx.Err()。
+// 单个品种的错误与 panic 记录在 Result.Err 中，不影响其他品种；取消时正在运行的品种跳过的策略也记录为 ctx.Err()。
+func (e *Runner) Run(ctx context.Context) (*Report, error) {
+	if len(e.items) == 0 {
+		return nil, ErrNoSymbols
+	}
+	if len(e.Factories) == 0 {
+		return nil, ErrNoStrategies
+	}
+
+	var symbols = make([]string, 0, len(e.items))
+	for symbol := range e.items {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var workers = e.Config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(symbols))
+
+	var start = time.Now()
+	var queue = make(chan string)
+	var results = make(chan Result)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range queue {
+				results <- e.run(ctx, symbol)
+			}
+		}()
+	}
+
+	// 分发品种，ctx 取消时停止分发，全部 worker 退出后关闭 results
+	go func() {
+		defer func() {
+			close(queue)
+			wg.Wait()
+			close(results)
+		}()
+		for _, symbol := range symbols {
+			select {
+			case queue <- symbol:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var report = &Report{}
+	for result := range results {
+		report.Results = append(report.Results, result)
+		if result.Err != nil {
+			report.Failed++
+		}
+		if e.Config.Progress != nil {
+			e.Config.Progress(Progress{
+				Symbol:  result.Symbol,
+				Done:    len(report.Results),
+				Total:   len(symbols),
+				Failed:  result.Err != nil,
+				Elapsed: time.Since(start),
+			})
+		}
+	}
+	sort.Slice(report.Results, func(i, j int) bool { return report.Results[i].Symbol < report.Results[j].Symbol })
+	report.Elapsed = time.Since(start)
+
+	// 全部品种完成之后才取消时不返回错误
+	if err := ctx.Err(); err != nil {
+		if len(report.Results) < len(symbols) {
+			return report, err
+		}
+		for _, v := range report.Results {
+			if errors.Is(v.Err, err) {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// run 运行一个品种的全部策略，ctx 取消后跳过剩余的策略
+func (e *Runner) run(ctx context.Context, symbol string) Result {
+	var start = time.Now()
+	var result = Result{Symbol: symbol, Sides: make([]utils.SideData, len(e.Factories))}
+	var item = e.items[symbol]
+	if item == nil || len(item.Candles) == 0 {
+		result.Err = ErrNoCandles
+		return result
+	}
+
+	var errs []error
+	for i, factory := range e.Factories {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		sides, err := analyse(factory, item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("strategy %d: %w", i, err))
+			continue
+		}
+		result.Sides[i] = sides
+	}
+	result.Err = errors.Join(errs...)
+	result.Duration = time.Since(start)
+	return result
+}
+
+// analyse 创建策略并计算信号，panic 转换为 ErrPanic
+func analyse(factory Factory, item *klines.Item) (sides utils.SideData, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+	return factory(item).AnalysisSide(), nil
+}
@@ -0,0 +1,168 @@
+package universe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// lastStrategy 最后一根K线收盘价高于第一根时买入，否则卖出
+type lastStrategy struct {
+	item *klines.Item
+}
+
+func (e lastStrategy) AnalysisSide() utils.SideData {
+	var sides = make([]utils.Side, len(e.item.Candles))
+	for i := range sides {
+		sides[i] = utils.Hold
+	}
+	if e.item.Candles[len(sides)-1].Close > e.item.Candles[0].Close {
+		sides[len(sides)-1] = utils.Buy
+	} else {
+		sides[len(sides)-1] = utils.Sell
+	}
+	return utils.SideData{Name: "last", Data: sides}
+}
+
+// periodStrategy 需要至少 20 根K线，否则和周期较长的指标一样越界 panic
+type periodStrategy struct {
+	item *klines.Item
+}
+
+func (e periodStrategy) AnalysisSide() utils.SideData {
+	var sides = make([]utils.Side, len(e.item.Candles))
+	sides[19] = utils.Hold
+	return utils.SideData{Name: "period", Data: sides}
+}
+
+var testFactories = []Factory{
+	func(item *klines.Item) utils.IStrategy { return lastStrategy{item} },
+	func(item *klines.Item) utils.IStrategy { return periodStrategy{item} },
+}
+
+func testItems(count int) map[string]*klines.Item {
+	var items = make(map[string]*klines.Item)
+	for i := 0; i < count; i++ {
+		items[fmt.Sprintf("%06d", i)] = utils.GetRandomKlineItem(50, int64(i+1))
+	}
+	return items
+}
+
+// RUN
+// go test -v ./utils/universe -run TestRunner
+func TestRunner(t *testing.T) {
+	t.Parallel()
+	var items = testItems(20)
+	items["short"] = utils.GetRandomKlineItem(5, 1)
+	items["empty"] = nil
+
+	var progress []Progress
+	var config = DefaultConfig()
+	config.Workers = 4
+	config.Progress = func(p Progress) { progress = append(progress, p) }
+
+	report, err := NewRunner(items, testFactories, config).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != len(items) || report.Failed != 2 {
+		t.Fatalf("got %d results, %d failed", len(report.Results), report.Failed)
+	}
+	for i := 1; i < len(report.Results); i++ {
+		if report.Results[i-1].Symbol >= report.Results[i].Symbol {
+			t.Fatalf("results not sorted: %s, %s", report.Results[i-1].Symbol, report.Results[i].Symbol)
+		}
+	}
+	if len(progress) != len(items) || progress[len(progress)-1].Done != len(items) || progress[0].Total != len(items) {
+		t.Fatalf("progress: %+v", progress)
+	}
+
+	// 短序列的 panic 只影响该策略
+	short, ok := report.Result("short")
+	if !ok || !errors.Is(short.Err, ErrPanic) || len(short.Sides[0].Data) != 5 || short.Sides[1].Data != nil {
+		t.Fatalf("short: %+v", short)
+	}
+	if empty, _ := report.Result("empty"); !errors.Is(empty.Err, ErrNoCandles) {
+		t.Fatalf("empty: %v", empty.Err)
+	}
+	var errs = report.Errors()
+	if len(errs) != 2 || errs["short"] == nil || errs["empty"] == nil {
+		t.Fatalf("errors: %v", errs)
+	}
+
+	var buy, sell = report.Latest(0, utils.Buy), report.Latest(0, utils.Sell)
+	if len(buy)+len(sell) != 20 {
+		t.Fatalf("latest: %d buy, %d sell", len(buy), len(sell))
+	}
+	for _, symbol := range buy {
+		var item = items[symbol]
+		if item.Candles[49].Close <= item.Candles[0].Close {
+			t.Fatalf("%s: unexpected buy", symbol)
+		}
+	}
+
+	if _, err := NewDefaultRunner(nil, testFactories...).Run(context.Background()); !errors.Is(err, ErrNoSymbols) {
+		t.Fatalf("got %v, want ErrNoSymbols", err)
+	}
+	if _, err := NewDefaultRunner(items).Run(context.Background()); !errors.Is(err, ErrNoStrategies) {
+		t.Fatalf("got %v, want ErrNoStrategies", err)
+	}
+}
+
+// RUN
+// go test -v ./utils/universe -run TestRunnerWorkers
+func TestRunnerWorkers(t *testing.T) {
+	t.Parallel()
+	var running, peak int32
+	var factory = func(item *klines.Item) utils.IStrategy {
+		var n = atomic.AddInt32(&running, 1)
+		for {
+			var p = atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return lastStrategy{item}
+	}
+
+	var config = DefaultConfig()
+	config.Workers = 3
+	report, err := NewRunner(testItems(30), []Factory{factory}, config).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 30 || peak > 3 {
+		t.Fatalf("got %d results, %d concurrent", len(report.Results), peak)
+	}
+}
+
+// RUN
+// go test -v ./utils/universe -run TestRunnerCancel
+func TestRunnerCancel(t *testing.T) {
+	t.Parallel()
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var config = DefaultConfig()
+	config.Workers = 2
+	config.Progress = func(p Progress) {
+		if p.Done == 3 {
+			cancel()
+		}
+	}
+	report, err := NewRunner(testItems(100), testFactories, config).Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if len(report.Results) < 3 || len(report.Results) >= 100 {
+		t.Fatalf("got %d results", len(report.Results))
+	}
+}
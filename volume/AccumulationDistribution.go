@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/shopspring/decimal"
 )
 
 // Accumulation/Distribution Indicator (A/D). 累积/分配指标 (A/D)。 累计指标
@@ -88,6 +89,29 @@ func (e *AccumulationDistribution) GetValues() []float64 {
 	return val
 }
 
+// GetDecimalValues 使用 decimal 计算，返回与 GetValues 一一对应的值，最高价等于最低价时当根不累计
+func (e *AccumulationDistribution) GetDecimalValues() []decimal.Decimal {
+	var ohlc = e.kline.GetDecimalOHLC()
+	var high = ohlc.High
+	var low = ohlc.Low
+	var closing = ohlc.Close
+	var volume = ohlc.Volume
+
+	ad := make([]decimal.Decimal, len(closing))
+	for i := 0; i < len(ad); i++ {
+		if i > 0 {
+			ad[i] = ad[i-1]
+		}
+		var length = high[i].Sub(low[i])
+		if length.IsZero() {
+			continue
+		}
+		var mfm = closing[i].Sub(low[i]).Sub(high[i].Sub(closing[i])).Div(length)
+		ad[i] = ad[i].Add(volume[i].Mul(mfm))
+	}
+	return ad
+}
+
 // GetData Func
 func (e *AccumulationDistribution) GetData() []AccumulationDistributionData {
 	if len(e.data) == 0 {
@@ -2,6 +2,7 @@ package volume
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
@@ -31,3 +32,17 @@ func TestAccumulationDistribution(t *testing.T) {
 		)
 	}
 }
+
+// RUN
+// go test -v ./volume -run TestAccumulationDistributionDecimal
+func TestAccumulationDistributionDecimal(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 9)
+	stock := NewAccumulationDistribution(list)
+	batch := stock.GetValues()
+	for i, v := range stock.GetDecimalValues() {
+		if f, _ := v.Float64(); math.Abs(f-batch[i]) > 1e-6 {
+			t.Fatalf("[%d] decimal %s float %f", i, v, batch[i])
+		}
+	}
+}
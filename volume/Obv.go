@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/shopspring/decimal"
 )
 
 // Obv计算方法：
@@ -18,6 +19,13 @@ type Obv struct {
 	Name  string
 	data  []ObvData
 	kline *klines.Item
+	// 增量计算状态，last 为最后一根K线之前的 Obv 值，用于 UpdateLast
+	stream *obvStream
+}
+
+type obvStream struct {
+	last  float64
+	state float64
 }
 
 type ObvData struct {
@@ -71,3 +79,82 @@ func (e *Obv) GetData() []ObvData {
 	}
 	return e.data
 }
+
+// GetDecimalValues 使用 decimal 计算，返回与 GetData 一一对应的值，成交量带小数时累计不会产生误差
+func (e *Obv) GetDecimalValues() []decimal.Decimal {
+	var ohlc = e.kline.GetDecimalOHLC()
+	var closes = ohlc.Close
+	var volumes = ohlc.Volume
+
+	result := make([]decimal.Decimal, len(closes))
+	for i := 1; i < len(closes); i++ {
+		switch closes[i].Cmp(closes[i-1]) {
+		case 1:
+			result[i] = result[i-1].Add(volumes[i])
+		case -1:
+			result[i] = result[i-1].Sub(volumes[i])
+		default:
+			result[i] = result[i-1]
+		}
+	}
+	return result
+}
+
+// Update 追加一根已完成的K线，返回最新的 Obv 值
+func (e *Obv) Update(candle *klines.Candle) ObvData {
+	e.initStream()
+	var index = len(e.data)
+	e.kline.SetCandle(index, candle)
+
+	e.stream.last = e.stream.state
+	e.stream.state = e.step(e.stream.last, index)
+
+	var p = ObvData{Time: time.Unix(candle.TimeUnix, 0), Value: e.stream.state}
+	e.data = append(e.data, p)
+	return p
+}
+
+// UpdateLast 更新最后一根未完成的K线，返回最新的 Obv 值
+func (e *Obv) UpdateLast(candle *klines.Candle) ObvData {
+	e.initStream()
+	var index = len(e.data) - 1
+	if index < 0 {
+		return e.Update(candle)
+	}
+	e.kline.SetCandle(index, candle)
+
+	e.stream.state = e.step(e.stream.last, index)
+
+	e.data[index] = ObvData{Time: time.Unix(candle.TimeUnix, 0), Value: e.stream.state}
+	return e.data[index]
+}
+
+// initStream 第一次增量计算时，根据已有的K线恢复计算状态
+func (e *Obv) initStream() {
+	if e.stream != nil {
+		return
+	}
+	if len(e.data) == 0 && len(e.kline.Candles) > 0 {
+		e.Calculation()
+	}
+	e.stream = &obvStream{}
+	for i := range e.data {
+		e.stream.last = e.stream.state
+		e.stream.state = e.step(e.stream.last, i)
+	}
+}
+
+// step 与 Calculation 的计算方式一致
+func (e *Obv) step(prev float64, index int) float64 {
+	if index == 0 {
+		return 0
+	}
+	var closing = e.kline.Candles[index].Close
+	var prevClosing = e.kline.Candles[index-1].Close
+	if closing > prevClosing {
+		return prev + e.kline.Candles[index].Volume
+	} else if closing < prevClosing {
+		return prev - e.kline.Candles[index].Volume
+	}
+	return prev
+}
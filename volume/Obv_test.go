@@ -2,9 +2,11 @@ package volume
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
 )
 
 // RUN
@@ -30,3 +32,36 @@ func TestObv(t *testing.T) {
 		)
 	}
 }
+
+// RUN
+// go test -v ./volume -run TestObvUpdate
+func TestObvUpdate(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 9)
+	batch := NewObv(list).GetData()
+
+	stream := NewObv(&klines.Item{Interval: list.Interval})
+	for i, candle := range list.Candles {
+		partial := *candle
+		partial.Close = candle.Open
+		stream.Update(&partial)
+		v := stream.UpdateLast(candle)
+		if math.Abs(v.Value-batch[i].Value) > 1e-9 {
+			t.Fatalf("[%d] stream %f batch %f", i, v.Value, batch[i].Value)
+		}
+	}
+}
+
+// RUN
+// go test -v ./volume -run TestObvDecimal
+func TestObvDecimal(t *testing.T) {
+	t.Parallel()
+	list := utils.GetRandomKlineItem(300, 9)
+	stock := NewObv(list)
+	batch := stock.GetData()
+	for i, v := range stock.GetDecimalValues() {
+		if f, _ := v.Float64(); math.Abs(f-batch[i].Value) > 1e-6 {
+			t.Fatalf("[%d] decimal %s float %f", i, v, batch[i].Value)
+		}
+	}
+}
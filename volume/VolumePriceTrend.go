@@ -6,6 +6,7 @@ import (
 
 	"github.com/idoall/stockindicator/utils/klines"
 	"github.com/idoall/stockindicator/utils/ta"
+	"github.com/shopspring/decimal"
 )
 
 // The Volume Price Trend (VPT) provides a correlation between the
@@ -62,6 +63,20 @@ func (e *VolumePriceTrend) Calculation() *VolumePriceTrend {
 	return e
 }
 
+// GetDecimalValues 使用 decimal 计算，返回与 GetData 一一对应的值
+func (e *VolumePriceTrend) GetDecimalValues() []decimal.Decimal {
+	var ohlc = e.kline.GetDecimalOHLC()
+	var closing = ohlc.Close
+	var volume = ohlc.Volume
+	if len(closing) == 0 {
+		return nil
+	}
+
+	previousClosing := ta.DecimalShiftRightAndFillBy(e.Period, closing[0], closing)
+	vpt := ta.DecimalMultiply(volume, ta.DecimalDivide(ta.DecimalSubtract(closing, previousClosing), previousClosing))
+	return ta.DecimalSum(len(vpt), vpt)
+}
+
 // AnalysisSide Func
 // func (e *VolumePriceTrend) AnalysisSide() utils.SideData {
 // 	sides := make([]utils.Side, len(e.kline.Candles))
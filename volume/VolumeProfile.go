@@ -0,0 +1,280 @@
+package volume
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+// VolumeProfile 成交量分布（筹码分布）
+// 把每根K线的成交量按价格平均分布到最低价与最高价之间，统计每个价格区间的成交量。
+//
+// POC (Point of Control)：成交量最大的价格区间
+// Value Area：从 POC 开始向两侧扩展，成交量达到总成交量 ValueArea（默认 70%）的价格范围
+// HVN/LVN：成交量高于平均值的局部高点与低于平均值的局部低点，常作为支撑阻力与价格快速穿越的区域
+type VolumeProfile struct {
+	Name string
+	// 价格区间数量
+	Bins int
+	// 价值区域占总成交量的比例
+	ValueArea float64
+	// 判断 HVN/LVN 时两侧比较的价格区间数量
+	NodeWidth int
+	// 按时段重置，默认每天 UTC 零点重置
+	Session Session
+	data    []VolumeProfileData
+	kline   *klines.Item
+}
+
+// VolumeProfileData 从时段开始到当前K线的成交量分布（Developing POC），没有成交量或不在时段内时为 NaN
+type VolumeProfileData struct {
+	Time          time.Time
+	POC           float64
+	ValueAreaHigh float64
+	ValueAreaLow  float64
+}
+
+// VolumeProfileBin 一个价格区间 [Low, High) 的成交量
+type VolumeProfileBin struct {
+	Low    float64
+	High   float64
+	Volume float64
+}
+
+// Profile 一段K线的成交量分布
+type Profile struct {
+	// 第一根与最后一根K线的时间
+	Start time.Time
+	End   time.Time
+	// 价格从低到高排列
+	Bins   []VolumeProfileBin
+	Volume float64
+	// POC 所在价格区间的序号与中间价
+	POCIndex int
+	POC      float64
+	// 价值区域的最高价与最低价
+	ValueAreaHigh float64
+	ValueAreaLow  float64
+	// 高成交量节点与低成交量节点，价格从低到高排列，低成交量节点不包含两端的价格区间
+	HighVolumeNodes []VolumeProfileBin
+	LowVolumeNodes  []VolumeProfileBin
+}
+
+// NewVolumeProfile new Func
+func NewVolumeProfile(klineItem *klines.Item, bins int, valueArea float64) *VolumeProfile {
+	return &VolumeProfile{
+		Name:      fmt.Sprintf("VolumeProfile%d", bins),
+		Bins:      bins,
+		ValueArea: valueArea,
+		NodeWidth: 2,
+		Session:   Session{Period: SessionDaily},
+		kline:     klineItem,
+	}
+}
+
+// NewDefaultVolumeProfile new Func
+func NewDefaultVolumeProfile(klineItem *klines.Item) *VolumeProfile {
+	return NewVolumeProfile(klineItem, 24, 0.7)
+}
+
+// Calculation Func
+// 当前K线创出时段内新高或新低时，需要按新的价格范围重新分布时段内全部K线的成交量，
+// 单边行情中几乎每根K线都会重新分布，耗时约为 O(时段K线数量² × Bins)。
+// Session 为 SessionNone 时全部K线为一个时段，K线较多时计算很慢，只需要整段的分布时使用 Profile。
+func (e *VolumeProfile) Calculation() *VolumeProfile {
+	var candles = e.kline.Candles
+	var times = e.kline.GetOHLC().TimeUnix
+	e.data = make([]VolumeProfileData, len(candles))
+	for i := range e.data {
+		e.data[i] = VolumeProfileData{Time: time.Unix(times[i], 0), POC: math.NaN(), ValueAreaHigh: math.NaN(), ValueAreaLow: math.NaN()}
+	}
+
+	for _, r := range e.Session.sessionRanges(times) {
+		var hist *histogram
+		for i := r[0]; i < r[1]; i++ {
+			// 价格范围扩大时重新分布，否则只需要累加当前K线
+			if hist == nil || candles[i].Low < hist.low || candles[i].High > hist.high {
+				hist = newHistogram(candles[r[0]:i+1], e.Bins)
+			} else {
+				hist.add(candles[i])
+			}
+			if poc := hist.poc(); poc >= 0 {
+				var low, high = hist.valueArea(poc, e.ValueArea)
+				e.data[i].POC = hist.binLow(poc) + hist.size/2
+				e.data[i].ValueAreaLow = hist.binLow(low)
+				e.data[i].ValueAreaHigh = hist.binLow(high + 1)
+			}
+		}
+	}
+	return e
+}
+
+// GetData return Point
+func (e *VolumeProfile) GetData() []VolumeProfileData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// Profile 计算 [from, to) 范围内K线的成交量分布，范围为空时返回 nil
+func (e *VolumeProfile) Profile(from, to int) *Profile {
+	from = max(from, 0)
+	to = min(to, len(e.kline.Candles))
+	if from >= to {
+		return nil
+	}
+	var candles = e.kline.Candles[from:to]
+	var hist = newHistogram(candles, e.Bins)
+
+	var profile = &Profile{
+		Start:         time.Unix(candles[0].TimeUnix, 0),
+		End:           time.Unix(candles[len(candles)-1].TimeUnix, 0),
+		Bins:          make([]VolumeProfileBin, len(hist.volumes)),
+		POCIndex:      hist.poc(),
+		POC:           math.NaN(),
+		ValueAreaHigh: math.NaN(),
+		ValueAreaLow:  math.NaN(),
+	}
+	for i, v := range hist.volumes {
+		profile.Bins[i] = VolumeProfileBin{Low: hist.binLow(i), High: hist.binLow(i + 1), Volume: v}
+		profile.Volume += v
+	}
+	if profile.POCIndex < 0 {
+		return profile
+	}
+	var low, high = hist.valueArea(profile.POCIndex, e.ValueArea)
+	profile.POC = hist.binLow(profile.POCIndex) + hist.size/2
+	profile.ValueAreaLow = hist.binLow(low)
+	profile.ValueAreaHigh = hist.binLow(high + 1)
+
+	var mean = profile.Volume / float64(len(hist.volumes))
+	for i, v := range hist.volumes {
+		var left, right = max(i-e.NodeWidth, 0), min(i+e.NodeWidth, len(hist.volumes)-1)
+		// 相同成交量的相邻区间只取最低的一个
+		var isHigh, isLow = v > mean, v < mean && i-e.NodeWidth >= 0 && i+e.NodeWidth < len(hist.volumes)
+		for j := left; j <= right; j++ {
+			if j < i {
+				isHigh = isHigh && v > hist.volumes[j]
+				isLow = isLow && v < hist.volumes[j]
+			} else if j > i {
+				isHigh = isHigh && v >= hist.volumes[j]
+				isLow = isLow && v <= hist.volumes[j]
+			}
+		}
+		if isHigh {
+			profile.HighVolumeNodes = append(profile.HighVolumeNodes, profile.Bins[i])
+		} else if isLow {
+			profile.LowVolumeNodes = append(profile.LowVolumeNodes, profile.Bins[i])
+		}
+	}
+	return profile
+}
+
+// Profiles 按 Session 计算每个时段的成交量分布
+func (e *VolumeProfile) Profiles() []*Profile {
+	var ranges = e.Session.sessionRanges(e.kline.GetOHLC().TimeUnix)
+	var profiles = make([]*Profile, len(ranges))
+	for i, r := range ranges {
+		profiles[i] = e.Profile(r[0], r[1])
+	}
+	return profiles
+}
+
+// histogram 等宽价格区间的成交量
+type histogram struct {
+	low, high, size float64
+	volumes         []float64
+}
+
+// newHistogram 按K线的最低价与最高价划分 bins 个价格区间，并分布成交量
+func newHistogram(candles []*klines.Candle, bins int) *histogram {
+	var h = &histogram{low: math.Inf(1), high: math.Inf(-1)}
+	for _, candle := range candles {
+		h.low = math.Min(h.low, candle.Low)
+		h.high = math.Max(h.high, candle.High)
+	}
+	bins = max(bins, 1)
+	if h.high <= h.low {
+		bins = 1
+	}
+	h.size = (h.high - h.low) / float64(bins)
+	h.volumes = make([]float64, bins)
+	for _, candle := range candles {
+		h.add(candle)
+	}
+	return h
+}
+
+func (h *histogram) binLow(index int) float64 {
+	if index >= len(h.volumes) {
+		return h.high
+	}
+	return h.low + float64(index)*h.size
+}
+
+func (h *histogram) index(price float64) int {
+	if h.size == 0 {
+		return 0
+	}
+	return min(max(int((price-h.low)/h.size), 0), len(h.volumes)-1)
+}
+
+// add 把K线的成交量按价格重叠的长度分布到各个区间，最高价等于最低价时全部计入收盘价所在区间
+func (h *histogram) add(candle *klines.Candle) {
+	if candle.Volume == 0 {
+		return
+	}
+	var height = candle.High - candle.Low
+	if height <= 0 {
+		h.volumes[h.index(candle.Close)] += candle.Volume
+		return
+	}
+	for i := h.index(candle.Low); i <= h.index(candle.High); i++ {
+		var overlap = math.Min(h.binLow(i+1), candle.High) - math.Max(h.binLow(i), candle.Low)
+		if overlap > 0 {
+			h.volumes[i] += candle.Volume * overlap / height
+		}
+	}
+}
+
+// poc 成交量最大的区间序号，相同时取价格最低的区间，没有成交量时返回 -1
+func (h *histogram) poc() int {
+	var index = -1
+	var volume float64
+	for i, v := range h.volumes {
+		if v > volume {
+			index, volume = i, v
+		}
+	}
+	return index
+}
+
+// valueArea 从 poc 开始，每次向成交量较大的一侧扩展一个区间，直到成交量达到 percent，返回区间序号范围 [low, high]
+func (h *histogram) valueArea(poc int, percent float64) (low, high int) {
+	var total float64
+	for _, v := range h.volumes {
+		total += v
+	}
+	low, high = poc, poc
+	var volume = h.volumes[poc]
+	for volume < total*percent && (low > 0 || high < len(h.volumes)-1) {
+		var up, down = -1.0, -1.0
+		if high < len(h.volumes)-1 {
+			up = h.volumes[high+1]
+		}
+		if low > 0 {
+			down = h.volumes[low-1]
+		}
+		if up >= down {
+			high++
+			volume += up
+		} else {
+			low--
+			volume += down
+		}
+	}
+	return low, high
+}
@@ -0,0 +1,97 @@
+package volume
+
+import (
+	"math"
+	"testing"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+)
+
+func newVolumeProfileTestItem(ranges ...[3]float64) *klines.Item {
+	var item = &klines.Item{Interval: klines.OneHour}
+	for i, v := range ranges {
+		item.Candles = append(item.Candles, &klines.Candle{
+			TimeUnix: 1699977600 + int64(i)*3600,
+			Low:      v[0],
+			High:     v[1],
+			Open:     v[0],
+			Close:    v[1],
+			Volume:   v[2],
+		})
+	}
+	return item
+}
+
+// RUN
+// go test -v ./volume -run TestVolumeProfile
+func TestVolumeProfile(t *testing.T) {
+	t.Parallel()
+	var list = newVolumeProfileTestItem([3]float64{10, 12, 100}, [3]float64{11, 12, 100}, [3]float64{10, 11, 10})
+	var stock = NewVolumeProfile(list, 4, 0.7)
+
+	// 区间成交量为 [30, 30, 75, 75]
+	var profile = stock.Profile(0, len(list.Candles))
+	if profile.Volume != 210 || profile.POCIndex != 2 || profile.POC != 11.25 {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	if profile.ValueAreaLow != 11 || profile.ValueAreaHigh != 12 {
+		t.Fatalf("value area %f-%f expected 11-12", profile.ValueAreaLow, profile.ValueAreaHigh)
+	}
+
+	var dataList = stock.GetData()
+	if len(dataList) != 3 || dataList[0].POC != 10.25 || dataList[0].ValueAreaLow != 10 || dataList[0].ValueAreaHigh != 11.5 {
+		t.Fatalf("unexpected developing data %+v", dataList)
+	}
+	if dataList[2].POC != profile.POC || dataList[2].ValueAreaHigh != profile.ValueAreaHigh {
+		t.Fatalf("last developing POC %+v expected %+v", dataList[2], profile)
+	}
+}
+
+// RUN
+// go test -v ./volume -run TestVolumeProfileNodes
+func TestVolumeProfileNodes(t *testing.T) {
+	t.Parallel()
+	var list = newVolumeProfileTestItem([3]float64{0, 1, 10}, [3]float64{1, 2, 50}, [3]float64{2, 3, 5}, [3]float64{3, 4, 40}, [3]float64{4, 5, 10})
+	var stock = NewVolumeProfile(list, 5, 0.7)
+	stock.NodeWidth = 1
+
+	var profile = stock.Profile(0, len(list.Candles))
+	if len(profile.HighVolumeNodes) != 2 || profile.HighVolumeNodes[0].Low != 1 || profile.HighVolumeNodes[1].Low != 3 {
+		t.Fatalf("unexpected high volume nodes %+v", profile.HighVolumeNodes)
+	}
+	if len(profile.LowVolumeNodes) != 1 || profile.LowVolumeNodes[0].Low != 2 {
+		t.Fatalf("unexpected low volume nodes %+v", profile.LowVolumeNodes)
+	}
+	if stock.Profile(3, 3) != nil {
+		t.Fatal("expected nil profile for empty range")
+	}
+}
+
+// RUN
+// go test -v ./volume -run TestVolumeProfileSession
+func TestVolumeProfileSession(t *testing.T) {
+	t.Parallel()
+	// 从 2023-11-14 22:00 UTC 开始的 30 分钟K线
+	var list = utils.GetRandomKlineItem(100, 1)
+	// 默认每天重置
+	var stock = NewDefaultVolumeProfile(list)
+
+	var profiles = stock.Profiles()
+	if len(profiles) != 3 || profiles[0].End.Unix() != list.Candles[3].TimeUnix || profiles[1].Start.Unix() != list.Candles[4].TimeUnix {
+		t.Fatalf("unexpected sessions %d", len(profiles))
+	}
+	var dataList = stock.GetData()
+	for i, v := range dataList {
+		if math.IsNaN(v.POC) || v.ValueAreaLow > v.POC || v.ValueAreaHigh < v.POC {
+			t.Fatalf("[%d] unexpected developing data %+v", i, v)
+		}
+	}
+	// 每个时段的第一根K线只包含自身的成交量
+	if dataList[4].ValueAreaLow < list.Candles[4].Low || dataList[4].ValueAreaHigh > list.Candles[4].High {
+		t.Fatalf("developing data did not reset %+v", dataList[4])
+	}
+	if last := profiles[2]; dataList[99].POC != last.POC || dataList[99].ValueAreaLow != last.ValueAreaLow {
+		t.Fatalf("last developing POC %+v expected %+v", dataList[99], last)
+	}
+}
@@ -0,0 +1,213 @@
+package volume
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/ta"
+)
+
+// Vwap 成交量加权平均价 (Volume Weighted Average Price)
+// 从锚点开始累计，典型价格 = (最高价 + 最低价 + 收盘价) / 3
+//
+// Vwap = Sum(典型价格 * 成交量) / Sum(成交量)
+// 标准差 = 平方根(Sum(典型价格² * 成交量) / Sum(成交量) - Vwap²)
+// 上下轨 = Vwap ± Bands * 标准差
+//
+// 锚点有三种：按 Session 在每个时段开始时重置；从 Anchor 时间开始；
+// 从最近一个确认的枢轴高点或低点开始，枢轴点在其右侧 PivotRight 根K线之后才确认，确认之前仍使用上一个锚点。
+type Vwap struct {
+	Name string
+	// 按时段重置，Period 为 SessionNone 时从第一根K线开始累计
+	Session Session
+	// 锚定的开始时间，不为零值时忽略 Session
+	Anchor time.Time
+	// 枢轴点左右两侧的K线数量，大于 0 时锚定到最近确认的枢轴点，忽略 Session 与 Anchor
+	PivotLeft  int
+	PivotRight int
+	// 3 条标准差带的倍数
+	Bands [3]float64
+	// AnalysisSide 使用第几条标准差带，1-3
+	ReversionBand int
+	data          []VwapData
+	kline         *klines.Item
+}
+
+// VwapData 不在时段内或锚点之前为 NaN
+type VwapData struct {
+	Time   time.Time
+	Value  float64
+	StdDev float64
+	Upper1 float64
+	Lower1 float64
+	Upper2 float64
+	Lower2 float64
+	Upper3 float64
+	Lower3 float64
+}
+
+// NewVwap new Func
+func NewVwap(klineItem *klines.Item, session Session) *Vwap {
+	return &Vwap{
+		Name:          "Vwap",
+		Session:       session,
+		Bands:         [3]float64{1, 2, 3},
+		ReversionBand: 2,
+		kline:         klineItem,
+	}
+}
+
+// NewDefaultVwap new Func，按 UTC 每天重置
+func NewDefaultVwap(klineItem *klines.Item) *Vwap {
+	return NewVwap(klineItem, Session{Period: SessionDaily})
+}
+
+// NewAnchoredVwap new Func，从 anchor 时间开始计算
+func NewAnchoredVwap(klineItem *klines.Item, anchor time.Time) *Vwap {
+	m := NewVwap(klineItem, Session{})
+	m.Name = "AnchoredVwap"
+	m.Anchor = anchor
+	return m
+}
+
+// NewPivotAnchoredVwap new Func，从最近确认的枢轴高点或低点开始计算
+func NewPivotAnchoredVwap(klineItem *klines.Item, left, right int) *Vwap {
+	m := NewVwap(klineItem, Session{})
+	m.Name = fmt.Sprintf("AnchoredVwap%d-%d", left, right)
+	m.PivotLeft = left
+	m.PivotRight = right
+	return m
+}
+
+// anchors 返回每根K线的锚点序号，没有锚点时为 -1
+func (e *Vwap) anchors() []int {
+	var candles = e.kline.Candles
+	var result = make([]int, len(candles))
+	for i := range result {
+		result[i] = -1
+	}
+
+	switch {
+	case e.PivotLeft > 0 || e.PivotRight > 0:
+		var ohlc = e.kline.GetOHLC()
+		var highs = ta.PivotHigh(ohlc.High, e.PivotLeft, e.PivotRight)
+		var lows = ta.PivotLow(ohlc.Low, e.PivotLeft, e.PivotRight)
+		var anchor = -1
+		for i := range candles {
+			// 第 i 根K线确认 i-PivotRight 为枢轴点
+			if highs[i] != 0 || lows[i] != 0 {
+				anchor = i - e.PivotRight
+			}
+			result[i] = anchor
+		}
+	case !e.Anchor.IsZero():
+		var anchor = -1
+		for i, candle := range candles {
+			if anchor < 0 && candle.TimeUnix >= e.Anchor.Unix() {
+				anchor = i
+			}
+			result[i] = anchor
+		}
+	default:
+		for _, r := range e.Session.sessionRanges(e.kline.GetOHLC().TimeUnix) {
+			for i := r[0]; i < r[1]; i++ {
+				result[i] = r[0]
+			}
+		}
+	}
+	return result
+}
+
+// Calculation Func
+func (e *Vwap) Calculation() *Vwap {
+	var candles = e.kline.Candles
+	var anchors = e.anchors()
+	e.data = make([]VwapData, len(candles))
+
+	var typical = func(c *klines.Candle) float64 {
+		return (c.High + c.Low + c.Close) / 3
+	}
+	var sumPV, sumP2V, sumV float64
+	for i, candle := range candles {
+		var p = VwapData{Time: time.Unix(candle.TimeUnix, 0), Value: math.NaN(), StdDev: math.NaN()}
+		var anchor = anchors[i]
+		if anchor >= 0 {
+			// 锚点变化时从新的锚点重新累计，否则只需要累加当前K线
+			if i == 0 || anchors[i-1] != anchor {
+				sumPV, sumP2V, sumV = 0, 0, 0
+				for j := anchor; j < i; j++ {
+					var price = typical(candles[j])
+					sumPV += price * candles[j].Volume
+					sumP2V += price * price * candles[j].Volume
+					sumV += candles[j].Volume
+				}
+			}
+			var price = typical(candle)
+			sumPV += price * candle.Volume
+			sumP2V += price * price * candle.Volume
+			sumV += candle.Volume
+			if sumV > 0 {
+				p.Value = sumPV / sumV
+				p.StdDev = math.Sqrt(math.Max(sumP2V/sumV-p.Value*p.Value, 0))
+			}
+		}
+		p.Upper1, p.Lower1 = p.Value+e.Bands[0]*p.StdDev, p.Value-e.Bands[0]*p.StdDev
+		p.Upper2, p.Lower2 = p.Value+e.Bands[1]*p.StdDev, p.Value-e.Bands[1]*p.StdDev
+		p.Upper3, p.Lower3 = p.Value+e.Bands[2]*p.StdDev, p.Value-e.Bands[2]*p.StdDev
+		e.data[i] = p
+	}
+	return e
+}
+
+// GetData return Point
+func (e *Vwap) GetData() []VwapData {
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+	return e.data
+}
+
+// band 返回第 ReversionBand 条标准差带
+func (e *Vwap) band(p VwapData) (upper, lower float64) {
+	switch e.ReversionBand {
+	case 1:
+		return p.Upper1, p.Lower1
+	case 3:
+		return p.Upper3, p.Lower3
+	default:
+		return p.Upper2, p.Lower2
+	}
+}
+
+// AnalysisSide Func
+// 均值回归：收盘价从下轨之下回到下轨之上时提供买入操作，
+// 收盘价从上轨之上回到上轨之下时提供卖出操作，上下轨为第 ReversionBand 条标准差带。
+func (e *Vwap) AnalysisSide() utils.SideData {
+	sides := make([]utils.Side, len(e.kline.Candles))
+
+	if len(e.data) == 0 {
+		e = e.Calculation()
+	}
+
+	var closes = e.kline.GetOHLC().Close
+	for i, v := range e.data {
+		sides[i] = utils.Hold
+		if i < 1 {
+			continue
+		}
+		var upper, lower = e.band(v)
+		var prevUpper, prevLower = e.band(e.data[i-1])
+		if closes[i-1] < prevLower && closes[i] >= lower {
+			sides[i] = utils.Buy
+		} else if closes[i-1] > prevUpper && closes[i] <= upper {
+			sides[i] = utils.Sell
+		}
+	}
+	return utils.SideData{
+		Name: e.Name,
+		Data: sides,
+	}
+}
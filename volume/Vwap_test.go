@@ -0,0 +1,101 @@
+package volume
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/idoall/stockindicator/utils"
+)
+
+// RUN
+// go test -v ./volume -run TestVwap
+func TestVwap(t *testing.T) {
+	t.Parallel()
+	// 从 2023-11-14 22:00 UTC 开始的 30 分钟K线
+	var list = utils.GetRandomKlineItem(100, 1)
+	var stock = NewDefaultVwap(list)
+	var dataList = stock.GetData()
+
+	// 第二个时段从第 4 根K线开始
+	var sumPV, sumV float64
+	for i := 4; i < 52; i++ {
+		var c = list.Candles[i]
+		var price = (c.High + c.Low + c.Close) / 3
+		sumPV += price * c.Volume
+		sumV += c.Volume
+		if math.Abs(dataList[i].Value-sumPV/sumV) > 1e-9 {
+			t.Fatalf("[%d] vwap %f expected %f", i, dataList[i].Value, sumPV/sumV)
+		}
+	}
+	if dataList[4].StdDev != 0 || dataList[52].StdDev != 0 {
+		t.Fatalf("vwap did not reset at session start %+v %+v", dataList[4], dataList[52])
+	}
+	var v = dataList[30]
+	if math.Abs(v.Upper2-v.Value-2*v.StdDev) > 1e-9 || math.Abs(v.Value-v.Lower3-3*v.StdDev) > 1e-9 || v.Upper1 <= v.Value {
+		t.Fatalf("unexpected bands %+v", v)
+	}
+
+	var sides = stock.AnalysisSide()
+	if len(sides.Data) != len(list.Candles) {
+		t.Fatalf("expected %d sides, got %d", len(list.Candles), len(sides.Data))
+	}
+
+	fmt.Printf("-- %s --\n", stock.Name)
+	for i := len(dataList) - 1; i > len(dataList)-5; i-- {
+		var v = dataList[i]
+		fmt.Printf("\t[%d]Time:%s\tPrice:%f\tValue:%f\tUpper2:%f\tLower2:%f\tSide:%s\n",
+			i,
+			v.Time.Format("2006-01-02 15:04:05"),
+			list.Candles[i].Close,
+			v.Value,
+			v.Upper2,
+			v.Lower2,
+			sides.Data[i],
+		)
+	}
+}
+
+// RUN
+// go test -v ./volume -run TestVwapSessionHours
+func TestVwapSessionHours(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(100, 1)
+	// 每天 01:00 - 03:00 UTC
+	var stock = NewVwap(list, Session{Period: SessionDaily, Offset: time.Hour, Length: 2 * time.Hour})
+
+	for i, v := range stock.GetData() {
+		var hour = v.Time.UTC().Hour()
+		if inside := hour >= 1 && hour < 3; inside == math.IsNaN(v.Value) {
+			t.Fatalf("[%d] %s unexpected value %f", i, v.Time.UTC(), v.Value)
+		}
+	}
+}
+
+// RUN
+// go test -v ./volume -run TestAnchoredVwap
+func TestAnchoredVwap(t *testing.T) {
+	t.Parallel()
+	var list = utils.GetRandomKlineItem(100, 2)
+	var anchored = NewAnchoredVwap(list, time.Unix(list.Candles[10].TimeUnix, 0)).GetData()
+	if !math.IsNaN(anchored[9].Value) || anchored[10].StdDev != 0 || math.IsNaN(anchored[99].Value) {
+		t.Fatalf("unexpected anchored vwap %+v %+v", anchored[9], anchored[10])
+	}
+
+	var stock = NewPivotAnchoredVwap(list, 5, 5)
+	var anchors = stock.anchors()
+	var dataList = stock.GetData()
+	for i := 1; i < len(anchors); i++ {
+		// 锚点只会在确认后向后移动，不使用未来的K线
+		if anchors[i] > i-5 && anchors[i] != -1 || anchors[i] < anchors[i-1] {
+			t.Fatalf("[%d] unexpected anchor %d", i, anchors[i])
+		}
+		if anchors[i] >= 0 && anchors[i] != anchors[i-1] {
+			var full = NewAnchoredVwap(list, time.Unix(list.Candles[anchors[i]].TimeUnix, 0)).GetData()
+			if math.Abs(full[i].Value-dataList[i].Value) > 1e-9 {
+				t.Fatalf("[%d] vwap %f expected %f", i, dataList[i].Value, full[i].Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package volume
+
+import (
+	"github.com/idoall/stockindicator/utils/klines"
+	"github.com/idoall/stockindicator/utils/registry"
+)
+
+// 注册交易量类指标，参数顺序与 NewXxx 一致
+func init() {
+	registry.Register(registry.Definition{
+		Name: "AccumulationDistribution", Aliases: []string{"ad"}, Category: registry.Volume, Description: "累积/派发线",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewAccumulationDistribution(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "ChaikinMoneyFlow", Aliases: []string{"cmf"}, Category: registry.Volume, Description: "蔡金资金流量",
+		Params: []registry.Param{registry.IntParam("period", 20, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewChaikinMoneyFlow(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "EaseOfMovement", Aliases: []string{"emv"}, Category: registry.Volume, Description: "简易波动指标",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewEaseOfMovement(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "ForceIndex", Category: registry.Volume, Description: "强力指数",
+		Params: []registry.Param{registry.IntParam("period", 13, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewForceIndex(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "MoneyFlowIndex", Aliases: []string{"mfi"}, Category: registry.Volume, Description: "资金流量指标",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewMoneyFlowIndex(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "NegativeVolumeIndex", Aliases: []string{"nvi"}, Category: registry.Volume, Description: "负成交量指标",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewNegativeVolumeIndex(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Obv", Category: registry.Volume, Description: "能量潮",
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewObv(item)
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "VolumePriceTrend", Aliases: []string{"vpt"}, Category: registry.Volume, Description: "量价趋势",
+		Params: []registry.Param{registry.IntParam("period", 14, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewVolumePriceTrend(item, p.Int("period"))
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "VolumeProfile", Aliases: []string{"vp"}, Category: registry.Volume, Description: "成交量分布",
+		// session: 0 不重置 1 每天 2 每周 3 每月
+		Params: []registry.Param{registry.IntParam("bins", 24, 1), registry.FloatParam("valueArea", 0.7, 0, 1), registry.IntRangeParam("session", 1, 0, 3)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			m := NewVolumeProfile(item, p.Int("bins"), p.Float("valueArea"))
+			m.Session = Session{Period: SessionPeriod(p.Int("session"))}
+			return m
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Vwap", Category: registry.Volume, Description: "成交量加权平均价",
+		// session: 0 不重置 1 每天 2 每周 3 每月
+		Params: []registry.Param{registry.IntRangeParam("session", 1, 0, 3), registry.IntRangeParam("band", 2, 1, 3)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			m := NewVwap(item, Session{Period: SessionPeriod(p.Int("session"))})
+			m.ReversionBand = p.Int("band")
+			return m
+		},
+	})
+	registry.Register(registry.Definition{
+		Name: "Vwma", Category: registry.Volume, Description: "成交量加权移动平均线",
+		Params: []registry.Param{registry.IntParam("period", 5, 1)},
+		New: func(item *klines.Item, p registry.Values) interface{} {
+			return NewVwma(item, p.Int("period"))
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package volume
+
+import "time"
+
+// SessionPeriod 交易时段的重置周期
+type SessionPeriod int
+
+const (
+	// SessionNone 不重置，全部K线为一个时段
+	SessionNone SessionPeriod = iota
+	// SessionDaily 每天重置
+	SessionDaily
+	// SessionWeekly 每周一重置
+	SessionWeekly
+	// SessionMonthly 每月 1 日重置
+	SessionMonthly
+)
+
+// Session 交易时段，按 Location 时区划分，Location 为空时使用 UTC。
+// Offset 为时段相对零点的开始时间，例如夜盘从 21:00 开始的期货可以设置为 21 * time.Hour；
+// Length 为每个时段的交易时长，时段开始 Length 之后的K线不属于任何时段，为 0 时持续到下一个时段开始，
+// 例如美股常规交易时段为 Location 纽约、Offset 9:30、Length 6.5 小时。
+type Session struct {
+	Period   SessionPeriod
+	Location *time.Location
+	Offset   time.Duration
+	Length   time.Duration
+}
+
+// start 返回 t 所在时段的开始时间，SessionNone 返回零值
+func (s Session) start(t time.Time) time.Time {
+	if s.Period == SessionNone {
+		return time.Time{}
+	}
+	var loc = s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	var local = t.In(loc).Add(-s.Offset)
+	var day = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	switch s.Period {
+	case SessionWeekly:
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case SessionMonthly:
+		day = day.AddDate(0, 0, 1-day.Day())
+	}
+	return day.Add(s.Offset)
+}
+
+// sessionRanges 按时段划分K线，返回每个时段的 [from, to) 序号范围，不包含时段之外的K线，K线需要按时间升序排列
+func (s Session) sessionRanges(times []int64) [][2]int {
+	var ranges [][2]int
+	var current time.Time
+	var open bool
+	for i, v := range times {
+		var t = time.Unix(v, 0)
+		var start = s.start(t)
+		var inside = s.Period == SessionNone || s.Length <= 0 || t.Before(start.Add(s.Length))
+		if open && (!inside || !start.Equal(current)) {
+			ranges[len(ranges)-1][1] = i
+			open = false
+		}
+		if inside && !open {
+			ranges = append(ranges, [2]int{i, len(times)})
+			current, open = start, true
+		}
+	}
+	return ranges
+}